@@ -0,0 +1,322 @@
+package archive
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// Fs is a read-only afero.Fs exposing the members of an archive.
+// Members are mounted under the archive's own path so that a Bundle rooted at the
+// archive path resolves member paths exactly as it would for a directory.
+type Fs struct {
+	root    string
+	entries map[string]*entry // cleaned absolute path -> entry
+	closers []io.Closer       // underlying archive file and spool, if kept open
+}
+
+// entry describes a single file or directory inside the archive
+type entry struct {
+	name     string
+	dir      bool
+	mode     os.FileMode
+	size     int64
+	modTime  time.Time
+	children map[string]bool
+	open     func() (io.ReaderAt, error) // returns member content for regular files
+}
+
+// newFs creates an empty archive filesystem rooted at root
+func newFs(root string, modTime time.Time) *Fs {
+	fs := &Fs{
+		root:    filepath.Clean(root),
+		entries: make(map[string]*entry),
+	}
+	fs.entries[fs.root] = &entry{
+		name:     filepath.Base(fs.root),
+		dir:      true,
+		mode:     os.ModeDir | 0555,
+		modTime:  modTime,
+		children: make(map[string]bool),
+	}
+	return fs
+}
+
+// memberPath converts an archive member name into its mounted absolute path
+func (fs *Fs) memberPath(memberName string) (string, bool) {
+	cleaned := filepath.Clean("/" + filepath.FromSlash(memberName))
+	if cleaned == string(filepath.Separator) {
+		return "", false
+	}
+	return filepath.Join(fs.root, cleaned), true
+}
+
+// addDir registers a directory and all of its missing parents
+func (fs *Fs) addDir(fullPath string, mode os.FileMode, modTime time.Time) {
+	if existing, ok := fs.entries[fullPath]; ok {
+		if existing.dir && !modTime.IsZero() {
+			existing.modTime = modTime
+		}
+		return
+	}
+
+	fs.entries[fullPath] = &entry{
+		name:     filepath.Base(fullPath),
+		dir:      true,
+		mode:     os.ModeDir | mode.Perm(),
+		modTime:  modTime,
+		children: make(map[string]bool),
+	}
+	fs.linkToParent(fullPath, modTime)
+}
+
+// addFile registers a regular file whose content is produced lazily by open
+func (fs *Fs) addFile(fullPath string, mode os.FileMode, size int64, modTime time.Time, open func() (io.ReaderAt, error)) {
+	fs.entries[fullPath] = &entry{
+		name:    filepath.Base(fullPath),
+		mode:    mode.Perm(),
+		size:    size,
+		modTime: modTime,
+		open:    open,
+	}
+	fs.linkToParent(fullPath, modTime)
+}
+
+// linkToParent records fullPath as a child of its parent directory, creating it if needed
+func (fs *Fs) linkToParent(fullPath string, modTime time.Time) {
+	parent := filepath.Dir(fullPath)
+	if _, ok := fs.entries[parent]; !ok {
+		fs.addDir(parent, 0555, modTime)
+	}
+	fs.entries[parent].children[filepath.Base(fullPath)] = true
+}
+
+// lookup resolves a path to its archive entry
+func (fs *Fs) lookup(name string) (*entry, string, error) {
+	cleaned := filepath.Clean(name)
+	found, ok := fs.entries[cleaned]
+	if !ok {
+		return nil, cleaned, os.ErrNotExist
+	}
+	return found, cleaned, nil
+}
+
+// Root returns the path the archive members are mounted under
+func (fs *Fs) Root() string {
+	return fs.root
+}
+
+// Close releases the underlying archive file and removes the spooled members
+func (fs *Fs) Close() error {
+	var firstErr error
+	for _, closer := range fs.closers {
+		if err := closer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	fs.closers = nil
+	return firstErr
+}
+
+// Name implements afero.Fs
+func (fs *Fs) Name() string {
+	return "ArchiveFs"
+}
+
+// Open implements afero.Fs
+func (fs *Fs) Open(name string) (afero.File, error) {
+	found, cleaned, err := fs.lookup(name)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	memberFile := &file{fs: fs, entry: found, path: cleaned}
+	if found.dir {
+		return memberFile, nil
+	}
+
+	content, err := found.open()
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+	memberFile.reader = io.NewSectionReader(content, 0, found.size)
+
+	return memberFile, nil
+}
+
+// OpenFile implements afero.Fs; only read-only access is permitted
+func (fs *Fs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_APPEND|os.O_CREATE|os.O_TRUNC) != 0 {
+		return nil, &os.PathError{Op: "open", Path: name, Err: syscall.EPERM}
+	}
+	return fs.Open(name)
+}
+
+// Stat implements afero.Fs
+func (fs *Fs) Stat(name string) (os.FileInfo, error) {
+	found, _, err := fs.lookup(name)
+	if err != nil {
+		return nil, &os.PathError{Op: "stat", Path: name, Err: err}
+	}
+	return &entryInfo{found}, nil
+}
+
+// Create implements afero.Fs
+func (fs *Fs) Create(name string) (afero.File, error) {
+	return nil, &os.PathError{Op: "create", Path: name, Err: syscall.EPERM}
+}
+
+// Mkdir implements afero.Fs
+func (fs *Fs) Mkdir(name string, perm os.FileMode) error {
+	return &os.PathError{Op: "mkdir", Path: name, Err: syscall.EPERM}
+}
+
+// MkdirAll implements afero.Fs
+func (fs *Fs) MkdirAll(path string, perm os.FileMode) error {
+	return &os.PathError{Op: "mkdir", Path: path, Err: syscall.EPERM}
+}
+
+// Remove implements afero.Fs
+func (fs *Fs) Remove(name string) error {
+	return &os.PathError{Op: "remove", Path: name, Err: syscall.EPERM}
+}
+
+// RemoveAll implements afero.Fs
+func (fs *Fs) RemoveAll(path string) error {
+	return &os.PathError{Op: "remove", Path: path, Err: syscall.EPERM}
+}
+
+// Rename implements afero.Fs
+func (fs *Fs) Rename(oldname, newname string) error {
+	return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: syscall.EPERM}
+}
+
+// Chmod implements afero.Fs
+func (fs *Fs) Chmod(name string, mode os.FileMode) error {
+	return &os.PathError{Op: "chmod", Path: name, Err: syscall.EPERM}
+}
+
+// Chown implements afero.Fs
+func (fs *Fs) Chown(name string, uid, gid int) error {
+	return &os.PathError{Op: "chown", Path: name, Err: syscall.EPERM}
+}
+
+// Chtimes implements afero.Fs
+func (fs *Fs) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	return &os.PathError{Op: "chtimes", Path: name, Err: syscall.EPERM}
+}
+
+// entryInfo adapts an archive entry to os.FileInfo
+type entryInfo struct {
+	entry *entry
+}
+
+func (ei *entryInfo) Name() string       { return ei.entry.name }
+func (ei *entryInfo) Size() int64        { return ei.entry.size }
+func (ei *entryInfo) Mode() os.FileMode  { return ei.entry.mode }
+func (ei *entryInfo) ModTime() time.Time { return ei.entry.modTime }
+func (ei *entryInfo) IsDir() bool        { return ei.entry.dir }
+func (ei *entryInfo) Sys() interface{}   { return nil }
+
+// file is an open handle on an archive member
+type file struct {
+	fs        *Fs
+	entry     *entry
+	path      string
+	reader    *io.SectionReader
+	dirOffset int
+}
+
+func (f *file) Name() string { return f.path }
+
+func (f *file) Close() error { return nil }
+
+func (f *file) Read(p []byte) (int, error) {
+	if f.entry.dir {
+		return 0, &os.PathError{Op: "read", Path: f.path, Err: syscall.EISDIR}
+	}
+	return f.reader.Read(p)
+}
+
+func (f *file) ReadAt(p []byte, off int64) (int, error) {
+	if f.entry.dir {
+		return 0, &os.PathError{Op: "read", Path: f.path, Err: syscall.EISDIR}
+	}
+	return f.reader.ReadAt(p, off)
+}
+
+func (f *file) Seek(offset int64, whence int) (int64, error) {
+	if f.entry.dir {
+		return 0, &os.PathError{Op: "seek", Path: f.path, Err: syscall.EISDIR}
+	}
+	return f.reader.Seek(offset, whence)
+}
+
+func (f *file) Stat() (os.FileInfo, error) {
+	return &entryInfo{f.entry}, nil
+}
+
+// Readdir returns directory entries in name order, count <= 0 returns all remaining
+func (f *file) Readdir(count int) ([]os.FileInfo, error) {
+	if !f.entry.dir {
+		return nil, &os.PathError{Op: "readdir", Path: f.path, Err: syscall.ENOTDIR}
+	}
+
+	names := make([]string, 0, len(f.entry.children))
+	for name := range f.entry.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if f.dirOffset >= len(names) {
+		if count > 0 {
+			return nil, io.EOF
+		}
+		return []os.FileInfo{}, nil
+	}
+
+	remaining := names[f.dirOffset:]
+	if count > 0 && count < len(remaining) {
+		remaining = remaining[:count]
+	}
+	f.dirOffset += len(remaining)
+
+	infos := make([]os.FileInfo, 0, len(remaining))
+	for _, name := range remaining {
+		infos = append(infos, &entryInfo{f.fs.entries[filepath.Join(f.path, name)]})
+	}
+
+	return infos, nil
+}
+
+func (f *file) Readdirnames(count int) ([]string, error) {
+	infos, err := f.Readdir(count)
+	names := make([]string, len(infos))
+	for i, info := range infos {
+		names[i] = info.Name()
+	}
+	return names, err
+}
+
+func (f *file) Write(p []byte) (int, error) {
+	return 0, &os.PathError{Op: "write", Path: f.path, Err: syscall.EPERM}
+}
+
+func (f *file) WriteAt(p []byte, off int64) (int, error) {
+	return 0, &os.PathError{Op: "write", Path: f.path, Err: syscall.EPERM}
+}
+
+func (f *file) WriteString(s string) (int, error) {
+	return 0, &os.PathError{Op: "write", Path: f.path, Err: syscall.EPERM}
+}
+
+func (f *file) Sync() error { return nil }
+
+func (f *file) Truncate(size int64) error {
+	return &os.PathError{Op: "truncate", Path: f.path, Err: syscall.EPERM}
+}
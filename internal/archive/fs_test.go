@@ -0,0 +1,303 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/ulikunitz/xz"
+)
+
+// testMember is a file, directory or link written into a test archive
+type testMember struct {
+	name    string
+	content string // Link target for links
+	dir     bool
+	symlink bool
+}
+
+var testMembers = []testMember{
+	{name: "sos/", dir: true},
+	{name: "sos/var/log/", dir: true},
+	{name: "sos/var/log/messages", content: "Jan  2 10:00:00 host kernel: booted\n"},
+	{name: "sos/var/log/app/app.log", content: "2024-01-02 10:00:00 INFO started\n2024-01-02 10:00:01 INFO ready\n"},
+	{name: "sos/etc/messages", content: "../var/log/messages", symlink: true}, // Relative link
+	{name: "sos/root/messages", content: "/var/log/messages", symlink: true},  // Absolute link into the capture root
+	{name: "sos/chained", content: "etc/messages", symlink: true},             // Link to a link
+	{name: "sos/logs", content: "var/log", symlink: true},                     // Directory link, skipped
+	{name: "sos/dangling", content: "var/log/missing", symlink: true},         // Dangling link, skipped
+	{name: "../escape.log", content: "outside the archive\n"},                 // Confined to the root
+	{name: "sos/var/log/empty.log", content: ""},
+}
+
+// wantMembers maps readable member paths to their expected content
+var wantMembers = map[string]string{
+	"sos/var/log/messages":    "Jan  2 10:00:00 host kernel: booted\n",
+	"sos/var/log/app/app.log": "2024-01-02 10:00:00 INFO started\n2024-01-02 10:00:01 INFO ready\n",
+	"sos/etc/messages":        "Jan  2 10:00:00 host kernel: booted\n",
+	"sos/root/messages":       "Jan  2 10:00:00 host kernel: booted\n",
+	"sos/chained":             "Jan  2 10:00:00 host kernel: booted\n",
+	"sos/var/log/empty.log":   "",
+	"escape.log":              "outside the archive\n",
+}
+
+// writeTar writes testMembers as a tar stream, through compress if it is set
+func writeTar(t *testing.T, path string, compress func(io.Writer) (io.WriteCloser, error)) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var output io.Writer = file
+	if compress != nil {
+		compressor, err := compress(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer compressor.Close()
+		output = compressor
+	}
+
+	tarWriter := tar.NewWriter(output)
+	defer tarWriter.Close()
+	for _, member := range testMembers {
+		header := &tar.Header{Name: member.name, Mode: 0644, Typeflag: tar.TypeReg, Size: int64(len(member.content))}
+		switch {
+		case member.dir:
+			header = &tar.Header{Name: member.name, Mode: 0755, Typeflag: tar.TypeDir}
+		case member.symlink:
+			header = &tar.Header{Name: member.name, Mode: 0777, Typeflag: tar.TypeSymlink, Linkname: member.content}
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			if _, err := io.WriteString(tarWriter, member.content); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// A hard link shares the content of an earlier member
+	if err := tarWriter.WriteHeader(&tar.Header{Name: "sos/hardlink.log", Mode: 0644, Typeflag: tar.TypeLink, Linkname: "sos/var/log/messages"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeZip writes testMembers as a zip archive, storing app.log uncompressed
+func writeZip(t *testing.T, path string) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	zipWriter := zip.NewWriter(file)
+	defer zipWriter.Close()
+	for _, member := range testMembers {
+		header := &zip.FileHeader{Name: member.name, Method: zip.Deflate}
+		switch {
+		case member.dir:
+			header.SetMode(os.ModeDir | 0755)
+		case member.symlink:
+			header.SetMode(os.ModeSymlink | 0777)
+		default:
+			header.SetMode(0644)
+		}
+		if filepath.Base(member.name) == "app.log" {
+			header.Method = zip.Store
+		}
+
+		writer, err := zipWriter.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !member.dir {
+			if _, err := io.WriteString(writer, member.content); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestArchiveFs(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string
+		write      func(t *testing.T, path string)
+		spooled    bool
+		hardLinked bool
+	}{
+		{
+			name:       "tar",
+			file:       "sos.tar",
+			write:      func(t *testing.T, path string) { writeTar(t, path, nil) },
+			hardLinked: true,
+		},
+		{
+			name: "tar.gz",
+			file: "sos.tar.gz",
+			write: func(t *testing.T, path string) {
+				writeTar(t, path, func(w io.Writer) (io.WriteCloser, error) { return gzip.NewWriter(w), nil })
+			},
+			spooled:    true,
+			hardLinked: true,
+		},
+		{
+			name: "tar.xz",
+			file: "sos.tar.xz",
+			write: func(t *testing.T, path string) {
+				writeTar(t, path, func(w io.Writer) (io.WriteCloser, error) { return xz.NewWriter(w) })
+			},
+			spooled:    true,
+			hardLinked: true,
+		},
+		{
+			name:    "zip",
+			file:    "sos.zip",
+			write:   writeZip,
+			spooled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spoolDir := t.TempDir()
+			t.Setenv("TMPDIR", spoolDir)
+
+			archivePath := filepath.Join(t.TempDir(), tt.file)
+			tt.write(t, archivePath)
+
+			fs, err := Open(afero.NewOsFs(), archivePath)
+			if err != nil {
+				t.Fatalf("Open(%s) error = %v", archivePath, err)
+			}
+			defer fs.Close()
+
+			want := make(map[string]string, len(wantMembers)+1)
+			for name, content := range wantMembers {
+				want[name] = content
+			}
+			if tt.hardLinked {
+				want["sos/hardlink.log"] = wantMembers["sos/var/log/messages"]
+			}
+
+			// Every member is read twice to check spooled members are inflated only once
+			for i := 0; i < 2; i++ {
+				for name, content := range want {
+					got, err := afero.ReadFile(fs, filepath.Join(archivePath, name))
+					if err != nil {
+						t.Errorf("ReadFile(%s) error = %v", name, err)
+						continue
+					}
+					if string(got) != content {
+						t.Errorf("ReadFile(%s) = %q, want %q", name, got, content)
+					}
+				}
+			}
+
+			for _, name := range []string{"sos/logs", "sos/dangling", "../escape.log", "sos/var/log/missing"} {
+				if _, err := fs.Stat(filepath.Join(archivePath, name)); !os.IsNotExist(err) {
+					t.Errorf("Stat(%s) error = %v, want not exist", name, err)
+				}
+			}
+
+			// Directories are listed, including ones only implied by member paths
+			var files []string
+			err = afero.Walk(fs, archivePath, func(path string, info os.FileInfo, err error) error {
+				if err == nil && !info.IsDir() {
+					files = append(files, path)
+				}
+				return err
+			})
+			if err != nil {
+				t.Fatalf("Walk() error = %v", err)
+			}
+			if len(files) != len(want) {
+				t.Errorf("Walk() found %d files, want %d: %v", len(files), len(want), files)
+			}
+
+			if _, err := fs.OpenFile(filepath.Join(archivePath, "sos/var/log/messages"), os.O_RDWR, 0); err == nil {
+				t.Error("OpenFile() opened an archive member for writing")
+			}
+
+			// The spool file is unlinked as soon as it is created
+			spooled := false
+			for _, closer := range fs.closers {
+				if members, ok := closer.(*spool); ok && members.file != nil {
+					spooled = true
+				}
+			}
+			if spooled != tt.spooled {
+				t.Errorf("members spooled = %v, want %v", spooled, tt.spooled)
+			}
+			if remaining, _ := os.ReadDir(spoolDir); len(remaining) != 0 {
+				t.Errorf("spool left %d files visible in the temp dir", len(remaining))
+			}
+			if err := fs.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestOpenContext(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "sos.tar.gz")
+	writeTar(t, archivePath, func(w io.Writer) (io.WriteCloser, error) { return gzip.NewWriter(w), nil })
+
+	var reported int64
+	fs, err := OpenContext(context.Background(), afero.NewOsFs(), archivePath, func(decompressed int64) {
+		reported = decompressed
+	})
+	if err != nil {
+		t.Fatalf("OpenContext() error = %v", err)
+	}
+	fs.Close()
+	if reported == 0 {
+		t.Error("OpenContext() reported no decompression progress")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := OpenContext(ctx, afero.NewOsFs(), archivePath, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("OpenContext() with a cancelled context error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestTrimExtension(t *testing.T) {
+	tests := []struct {
+		name      string
+		want      string
+		isArchive bool
+	}{
+		{"sosreport.tar.xz", "sosreport", true},
+		{"bundle.TGZ", "bundle", true},
+		{"logs.zip", "logs", true},
+		{"backup.tar", "backup", true},
+		{"app.log.gz", "app.log.gz", false},
+		{"notes.txt", "notes.txt", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TrimExtension(tt.name); got != tt.want {
+				t.Errorf("TrimExtension(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+			if got := IsArchive(tt.name); got != tt.isArchive {
+				t.Errorf("IsArchive(%q) = %v, want %v", tt.name, got, tt.isArchive)
+			}
+		})
+	}
+}
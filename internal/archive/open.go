@@ -0,0 +1,384 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cheerioskun/logninja/internal/parser"
+	"github.com/cheerioskun/logninja/internal/utils"
+	"github.com/spf13/afero"
+)
+
+// archiveExtensions lists recognised archive suffixes, longest first so TrimExtension strips them whole
+var archiveExtensions = []string{
+	".tar.gz", ".tar.bz2", ".tar.xz", ".tar.zst",
+	".tgz", ".tbz2", ".txz", ".tzst",
+	".tar", ".zip",
+}
+
+// IsArchive reports whether the path names a supported bundle archive
+func IsArchive(path string) bool {
+	return archiveExtension(path) != ""
+}
+
+// TrimExtension removes a recognised archive suffix from a file name
+func TrimExtension(name string) string {
+	return strings.TrimSuffix(name, archiveExtension(name))
+}
+
+// archiveExtension returns the matching archive suffix of path, or "" if none matches
+func archiveExtension(path string) string {
+	lower := strings.ToLower(path)
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(lower, ext) {
+			return path[len(path)-len(ext):]
+		}
+	}
+	return ""
+}
+
+// Open mounts the archive at archivePath (read through base) as a read-only filesystem.
+// Member paths are exposed under archivePath itself, e.g. "/tmp/bundle.zip/var/log/syslog".
+func Open(base afero.Fs, archivePath string) (*Fs, error) {
+	return OpenContext(context.Background(), base, archivePath, nil)
+}
+
+// OpenContext is Open that stops when ctx is cancelled. Compressed tars are decompressed
+// while they are opened; progress, if non-nil, is called with the bytes decompressed so far.
+func OpenContext(ctx context.Context, base afero.Fs, archivePath string, progress func(decompressed int64)) (*Fs, error) {
+	info, err := base.Stat(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access archive %s: %w", archivePath, err)
+	}
+
+	if strings.EqualFold(archiveExtension(archivePath), ".zip") {
+		return openZip(base, archivePath, info.Size(), info.ModTime())
+	}
+
+	format, err := parser.DetectCompression(base, archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == parser.CompressionNone {
+		return openPlainTar(base, archivePath, info.ModTime())
+	}
+	return openCompressedTar(ctx, base, archivePath, info.ModTime(), progress)
+}
+
+// openZip indexes a zip archive; stored members are read in place, compressed ones are
+// inflated into a spool file the first time they are opened
+func openZip(base afero.Fs, archivePath string, size int64, modTime time.Time) (*Fs, error) {
+	archiveFile, err := base.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive %s: %w", archivePath, err)
+	}
+
+	zipReader, err := zip.NewReader(archiveFile, size)
+	if err != nil {
+		archiveFile.Close()
+		return nil, fmt.Errorf("failed to read zip archive %s: %w", archivePath, err)
+	}
+
+	fs := newFs(archivePath, modTime)
+	members := &spool{}
+	fs.closers = []io.Closer{archiveFile, members}
+	var symlinks []symlink
+
+	for _, member := range zipReader.File {
+		fullPath, ok := fs.memberPath(member.Name)
+		if !ok {
+			continue
+		}
+
+		if member.FileInfo().IsDir() {
+			fs.addDir(fullPath, member.Mode(), member.Modified)
+			continue
+		}
+		if member.Mode()&os.ModeSymlink != 0 {
+			// The content of a symlink member is its target
+			if target, err := readZipLink(member); err == nil {
+				symlinks = append(symlinks, symlink{path: fullPath, target: target, modTime: member.Modified})
+			} else {
+				utils.Warning("skipping archive member %s: %v", fullPath, err)
+			}
+			continue
+		}
+		if !member.Mode().IsRegular() {
+			continue
+		}
+
+		fs.addFile(fullPath, member.Mode(), int64(member.UncompressedSize64), member.Modified, zipMemberOpener(archiveFile, member, members))
+	}
+
+	fs.resolveSymlinks(symlinks)
+	return fs, nil
+}
+
+// readZipLink reads the target of a symlink member
+func readZipLink(member *zip.File) (string, error) {
+	reader, err := member.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read link target: %w", err)
+	}
+	defer reader.Close()
+
+	target, err := io.ReadAll(io.LimitReader(reader, 4096))
+	if err != nil {
+		return "", fmt.Errorf("failed to read link target: %w", err)
+	}
+	return string(target), nil
+}
+
+// zipMemberOpener returns a loader for a single zip member. Compressed members are inflated
+// once, on first use, and later opens read the spooled copy.
+func zipMemberOpener(archiveFile io.ReaderAt, member *zip.File, members *spool) func() (io.ReaderAt, error) {
+	if member.Method == zip.Store {
+		return func() (io.ReaderAt, error) {
+			offset, err := member.DataOffset()
+			if err != nil {
+				return nil, err
+			}
+			return io.NewSectionReader(archiveFile, offset, int64(member.UncompressedSize64)), nil
+		}
+	}
+
+	var (
+		once    sync.Once
+		content io.ReaderAt
+		err     error
+	)
+	return func() (io.ReaderAt, error) {
+		once.Do(func() {
+			var reader io.ReadCloser
+			reader, err = member.Open()
+			if err != nil {
+				return
+			}
+			defer reader.Close()
+			content, err = members.store(member.Name, reader)
+			if err != nil {
+				err = fmt.Errorf("failed to inflate %s: %w", member.Name, err)
+			}
+		})
+		return content, err
+	}
+}
+
+// openPlainTar indexes an uncompressed tar; member content is read in place by offset
+func openPlainTar(base afero.Fs, archivePath string, modTime time.Time) (*Fs, error) {
+	archiveFile, err := base.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive %s: %w", archivePath, err)
+	}
+
+	counter := &offsetTracker{reader: archiveFile}
+	fs, err := indexTar(archivePath, modTime, counter, func(header *tar.Header, _ *tar.Reader) (func() (io.ReaderAt, error), error) {
+		section := io.NewSectionReader(archiveFile, counter.offset, header.Size)
+		return func() (io.ReaderAt, error) { return section, nil }, nil
+	})
+	if err != nil {
+		archiveFile.Close()
+		return nil, err
+	}
+
+	fs.closers = []io.Closer{archiveFile}
+	return fs, nil
+}
+
+// openCompressedTar indexes a compressed tar. Compressed streams cannot be seeked, so members
+// are decompressed once up front into a spool file as the headers are walked.
+func openCompressedTar(ctx context.Context, base afero.Fs, archivePath string, modTime time.Time, progress func(int64)) (*Fs, error) {
+	stream, _, err := parser.OpenDecompressed(base, archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	members := &spool{}
+	decompressed := &progressReader{ctx: ctx, reader: stream, progress: progress}
+	fs, err := indexTar(archivePath, modTime, decompressed, func(header *tar.Header, tarReader *tar.Reader) (func() (io.ReaderAt, error), error) {
+		content, err := members.store(header.Name, tarReader)
+		if err != nil {
+			return nil, err
+		}
+		return func() (io.ReaderAt, error) { return content, nil }, nil
+	})
+	if err != nil {
+		members.Close()
+		return nil, err
+	}
+
+	if progress != nil {
+		progress(decompressed.read)
+	}
+	fs.closers = []io.Closer{members}
+	return fs, nil
+}
+
+// indexTar walks the tar headers and registers every directory and regular file, then the
+// symlinks to files. loadMember is called while the tar reader is positioned at the member's content.
+func indexTar(archivePath string, modTime time.Time, stream io.Reader, loadMember func(*tar.Header, *tar.Reader) (func() (io.ReaderAt, error), error)) (*Fs, error) {
+	fs := newFs(archivePath, modTime)
+	tarReader := tar.NewReader(stream)
+	var symlinks []symlink
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar archive %s: %w", archivePath, err)
+		}
+
+		fullPath, ok := fs.memberPath(header.Name)
+		if !ok {
+			continue
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			fs.addDir(fullPath, header.FileInfo().Mode(), header.ModTime)
+
+		case tar.TypeReg:
+			opener, err := loadMember(header, tarReader)
+			if err != nil {
+				return nil, err
+			}
+			fs.addFile(fullPath, header.FileInfo().Mode(), header.Size, header.ModTime, opener)
+
+		case tar.TypeLink:
+			// Hard links share the content of a member seen earlier in the archive
+			if targetPath, ok := fs.memberPath(header.Linkname); ok {
+				if target, exists := fs.entries[targetPath]; exists && !target.dir {
+					fs.addFile(fullPath, target.mode, target.size, header.ModTime, target.open)
+				}
+			}
+
+		case tar.TypeSymlink:
+			// Targets may come later in the archive, so links are resolved once all are known
+			symlinks = append(symlinks, symlink{path: fullPath, target: header.Linkname, modTime: header.ModTime})
+		}
+	}
+
+	fs.resolveSymlinks(symlinks)
+	return fs, nil
+}
+
+// symlink is a symbolic link member waiting to be resolved
+type symlink struct {
+	path    string // Mounted path of the link
+	target  string // Link target as stored in the archive
+	modTime time.Time
+}
+
+// resolveSymlinks mounts links to files as copies of their targets, following chains of links.
+// Links to directories, and dangling or cyclic links, are skipped and reported: walking a
+// directory doesn't follow directory links either.
+func (fs *Fs) resolveSymlinks(symlinks []symlink) {
+	pending := symlinks
+	for len(pending) > 0 {
+		var unresolved []symlink
+		for _, link := range pending {
+			target, exists := fs.linkTarget(link)
+			switch {
+			case !exists:
+				unresolved = append(unresolved, link) // Possibly another link, resolved this round
+			case target.dir:
+				utils.Warning("skipping archive member %s: links to directory %s", link.path, link.target)
+			default:
+				fs.addFile(link.path, target.mode, target.size, link.modTime, target.open)
+			}
+		}
+
+		if len(unresolved) == len(pending) {
+			for _, link := range unresolved {
+				utils.Warning("skipping archive member %s: link target %s not found in archive", link.path, link.target)
+			}
+			return
+		}
+		pending = unresolved
+	}
+}
+
+// linkTarget returns the entry a symlink points to, if it is in the archive. Absolute targets
+// refer to the captured system, whose root is either the archive root or, as in sosreports,
+// the single top-level directory of the archive.
+func (fs *Fs) linkTarget(link symlink) (*entry, bool) {
+	linkDir, err := filepath.Rel(fs.root, filepath.Dir(link.path))
+	if err != nil {
+		return nil, false
+	}
+
+	var candidates []string
+	if strings.HasPrefix(link.target, "/") {
+		topDir := strings.SplitN(filepath.ToSlash(linkDir), "/", 2)[0]
+		candidates = []string{link.target, topDir + link.target}
+	} else {
+		candidates = []string{filepath.Join(linkDir, filepath.FromSlash(link.target))}
+	}
+
+	for _, candidate := range candidates {
+		if targetPath, ok := fs.memberPath(candidate); ok {
+			if target, exists := fs.entries[targetPath]; exists {
+				return target, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// progressReportInterval is how many decompressed bytes pass between progress reports
+const progressReportInterval = 4 << 20
+
+// progressReader reports how much of a decompressed stream has been read, and fails reads
+// once ctx is cancelled
+type progressReader struct {
+	ctx      context.Context
+	reader   io.Reader
+	progress func(int64)
+	read     int64
+	reported int64
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	if err := pr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := pr.reader.Read(p)
+	pr.read += int64(n)
+	if pr.progress != nil && pr.read-pr.reported >= progressReportInterval {
+		pr.reported = pr.read
+		pr.progress(pr.read)
+	}
+	return n, err
+}
+
+// offsetTracker records how many bytes the tar reader has consumed so member offsets are known
+type offsetTracker struct {
+	reader io.ReadSeeker
+	offset int64
+}
+
+func (ot *offsetTracker) Read(p []byte) (int, error) {
+	n, err := ot.reader.Read(p)
+	ot.offset += int64(n)
+	return n, err
+}
+
+func (ot *offsetTracker) Seek(offset int64, whence int) (int64, error) {
+	position, err := ot.reader.Seek(offset, whence)
+	if err == nil {
+		ot.offset = position
+	}
+	return position, err
+}
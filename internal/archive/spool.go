@@ -0,0 +1,67 @@
+package archive
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// spool is a temporary file holding decompressed archive members back to back, so members
+// that can't be read in place are decompressed once and kept on disk rather than in memory.
+// The file is unlinked as soon as it is created, so it is gone once closed even if the
+// process is killed.
+type spool struct {
+	mu       sync.Mutex
+	file     *os.File // Created on first use
+	size     int64
+	unlinked bool // Whether the file was removed while open; not possible on every platform
+}
+
+// store appends the content of reader to the spool and returns a reader over it
+func (s *spool) store(name string, reader io.Reader) (io.ReaderAt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		file, err := os.CreateTemp("", "logninja-archive-*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create spool file: %w", err)
+		}
+		s.file = file
+		s.unlinked = os.Remove(file.Name()) == nil
+	}
+
+	offset := s.size
+	if _, err := s.file.Seek(offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to spool %s: %w", name, err)
+	}
+	written, err := io.Copy(s.file, reader)
+	s.size += written
+	if err != nil {
+		return nil, fmt.Errorf("failed to spool %s: %w", name, err)
+	}
+
+	// os.File.ReadAt is safe to use concurrently with later appends
+	return io.NewSectionReader(s.file, offset, written), nil
+}
+
+// Close releases the spool file, removing it if it couldn't be unlinked while open
+func (s *spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	closeErr := s.file.Close()
+	var removeErr error
+	if !s.unlinked {
+		removeErr = os.Remove(s.file.Name())
+	}
+	s.file = nil
+	if closeErr != nil {
+		return closeErr
+	}
+	return removeErr
+}
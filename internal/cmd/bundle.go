@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/cheerioskun/logninja/internal/archive"
+	"github.com/cheerioskun/logninja/internal/models"
+	"github.com/cheerioskun/logninja/internal/scanner"
+	"github.com/cheerioskun/logninja/internal/utils"
+	"github.com/spf13/afero"
+)
+
+var (
+	scanWorkers int
+	noScanCache bool
+)
+
+// openBundleFs returns the filesystem a bundle should be scanned through.
+// Directories are read from the OS; archives are mounted read-only at their own path
+// so the bundle path stays the same. The returned cleanup releases any archive handle.
+// Compressed tars are decompressed while they are mounted, which reports progress on
+// stderr and stops on Ctrl+C.
+func openBundleFs(absPath string) (afero.Fs, func(), error) {
+	osFs := afero.NewOsFs()
+
+	info, err := osFs.Stat(absPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to access path %s: %w", absPath, err)
+	}
+
+	if info.IsDir() || !archive.IsArchive(absPath) {
+		return osFs, func() {}, nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	reported := false
+	archiveFs, err := archive.OpenContext(ctx, osFs, absPath, func(decompressed int64) {
+		reported = true
+		fmt.Fprintf(os.Stderr, "\r\033[KDecompressing archive: %s", formatBytes(decompressed))
+	})
+	if reported {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+	}
+	if ctx.Err() != nil {
+		return nil, nil, fmt.Errorf("opening archive cancelled")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+
+	return archiveFs, func() { archiveFs.Close() }, nil
+}
+
+// configureIndexCache enables the persistent scan index unless --no-cache was given
+func configureIndexCache(bundleScanner *scanner.BundleScanner) {
+	if noScanCache {
+		return
+	}
+
+	indexDir, err := scanner.DefaultIndexDir()
+	if err != nil {
+		utils.Warning("scan index cache disabled: %v", err)
+		return
+	}
+
+	bundleScanner.SetIndexCache(scanner.NewIndexCache(afero.NewOsFs(), indexDir))
+}
+
+// scanWithProgress runs a full bundle scan that Ctrl+C aborts cleanly,
+// rendering a single updating progress line to out while it runs
+func scanWithProgress(bundleScanner *scanner.BundleScanner, absPath string, out io.Writer) (*models.Bundle, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	progress := make(chan scanner.ScanProgress)
+	rendered := make(chan struct{})
+	go func() {
+		defer close(rendered)
+		for update := range progress {
+			fmt.Fprintf(out, "\r\033[K%s", formatScanProgress(update))
+		}
+		fmt.Fprint(out, "\r\033[K")
+	}()
+
+	bundle, err := bundleScanner.ScanBundleContext(ctx, absPath, progress)
+	close(progress)
+	<-rendered
+
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("scan cancelled")
+	}
+	return bundle, err
+}
+
+// formatScanProgress renders a progress snapshot as a single status line
+func formatScanProgress(progress scanner.ScanProgress) string {
+	switch progress.Phase {
+	case scanner.PhaseWalking:
+		return fmt.Sprintf("Walking: %d files found", progress.FilesWalked)
+	case scanner.PhaseClassifying:
+		return fmt.Sprintf("Classifying: %d/%d files (%d cached) | %s peeked",
+			progress.FilesClassified, progress.TotalFiles, progress.FilesCached, formatBytes(progress.BytesPeeked))
+	default:
+		return fmt.Sprintf("%s: %d files | %s peeked",
+			progress.Phase, progress.TotalFiles, formatBytes(progress.BytesPeeked))
+	}
+}
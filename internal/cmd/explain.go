@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/cheerioskun/logninja/internal/models"
+	"github.com/cheerioskun/logninja/internal/parser"
+	"github.com/cheerioskun/logninja/internal/scanner"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+// explainCmd shows why a scan does or doesn't treat a file as a log file
+var explainCmd = &cobra.Command{
+	Use:   "explain <file>",
+	Short: "Explain why a file is or isn't detected as a log file",
+	Long: `Classify a single file the way a scan does and show the details: the lines
+sampled from its start, how many of them each timestamp pattern matched, the
+chosen pattern and its confidence, and which detection threshold failed, if any.
+The content is sniffed first: binary files are never sampled, and UTF-16 files
+are transcoded to UTF-8 before their lines are matched.
+
+The thresholds can be changed in the config file:
+
+  detection:
+    min_matches: 2       # sampled lines with a timestamp, out of the first 10
+    min_confidence: 0.3  # share of the sampled lines with a timestamp
+
+Examples:
+  logninja explain /var/log/app.log
+  logninja explain ./bundle/node1/kubelet.log.gz`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExplain,
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	filePath, err := filepath.Abs(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to resolve absolute path: %w", err)
+	}
+
+	detection, err := scanner.NewBundleScanner(afero.NewOsFs()).ExplainFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Content: %s\n", describeContent(detection.Content))
+	if !detection.Content.Binary {
+		fmt.Printf("Sampled %d lines of %s:\n", detection.LineCount, filePath)
+		printSample(detection.Sample)
+	}
+	fmt.Println()
+
+	if detection.LineCount > 0 {
+		printPatternScores(detection.Scores, detection.Best, detection.LineCount)
+		fmt.Println()
+	}
+
+	if detection.Best != nil {
+		fmt.Printf("Chosen pattern: %s (%d/%d lines, %.0f%% confidence)\n", detection.Best.Pattern.Name,
+			detection.Best.Matches, detection.LineCount, detection.Confidence*100)
+	} else {
+		fmt.Println("Chosen pattern: none")
+	}
+	fmt.Printf("Thresholds: min_matches %d, min_confidence %.0f%%\n",
+		detection.Thresholds.MinMatches, detection.Thresholds.MinConfidence*100)
+	fmt.Println()
+
+	if detection.IsLogFile() {
+		fmt.Println("Result: log file")
+		return nil
+	}
+	fmt.Println("Result: not a log file")
+	for _, failure := range detection.Failures {
+		fmt.Printf("  - %s\n", failure)
+	}
+	return nil
+}
+
+// describeContent summarises what a file was sniffed as, e.g. "compressed (gzip) text, utf-16le"
+func describeContent(content scanner.ContentSniff) string {
+	description := string(content.Kind)
+	if content.Kind == models.KindCompressed {
+		inner := "text"
+		if content.Binary {
+			inner = "binary"
+		}
+		description = fmt.Sprintf("compressed (%s) %s", content.Compression, inner)
+	}
+	if content.Encoding != parser.EncodingUnknown {
+		description += ", " + string(content.Encoding)
+	}
+	return description
+}
@@ -5,10 +5,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 
 	"github.com/cheerioskun/logninja/internal/models"
 	"github.com/cheerioskun/logninja/internal/scanner"
-	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -17,7 +17,7 @@ import (
 func getIncludePatternsFromFilters(filters []models.RegexFilter) []string {
 	var patterns []string
 	for _, filter := range filters {
-		if filter.Valid && filter.Take {
+		if filter.Valid && filter.Take && filter.IsPathFilter() {
 			patterns = append(patterns, filter.Pattern)
 		}
 	}
@@ -27,7 +27,7 @@ func getIncludePatternsFromFilters(filters []models.RegexFilter) []string {
 func getExcludePatternsFromFilters(filters []models.RegexFilter) []string {
 	var patterns []string
 	for _, filter := range filters {
-		if filter.Valid && !filter.Take {
+		if filter.Valid && !filter.Take && filter.IsPathFilter() {
 			patterns = append(patterns, filter.Pattern)
 		}
 	}
@@ -70,6 +70,8 @@ func init() {
 	initCmd.Flags().BoolVar(&includeNonLogs, "include-non-logs", false, "include non-log files in analysis")
 	initCmd.Flags().Int64Var(&minFileSize, "min-size", 0, "minimum file size in bytes to include")
 	initCmd.Flags().IntVar(&maxDepth, "max-depth", 10, "maximum directory depth to scan")
+	initCmd.Flags().IntVar(&scanWorkers, "workers", runtime.NumCPU(), "number of files to peek at concurrently while scanning")
+	initCmd.Flags().BoolVar(&noScanCache, "no-cache", false, "ignore and do not update the scan index cache")
 
 	// Bind flags to viper
 	viper.BindPFlag("smart-selection", initCmd.Flags().Lookup("smart-selection"))
@@ -94,16 +96,22 @@ func runInit(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Configuration will be saved to: %s\n", outputConfig)
 	fmt.Println()
 
-	// Create filesystem interface
-	fs := afero.NewOsFs()
+	// Create filesystem interface (archives are mounted as a virtual filesystem)
+	fs, cleanup, err := openBundleFs(absPath)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
 
 	// Create bundle scanner (always uses timestamp detection)
 	bundleScanner := scanner.NewBundleScanner(fs)
 	bundleScanner.SetMaxDepth(maxDepth)
+	bundleScanner.SetWorkers(scanWorkers)
+	configureIndexCache(bundleScanner)
 
 	// Scan the bundle
 	fmt.Println("Scanning directory...")
-	bundle, err := bundleScanner.ScanBundle(absPath)
+	bundle, err := scanWithProgress(bundleScanner, absPath, os.Stderr)
 	if err != nil {
 		return fmt.Errorf("failed to scan bundle: %w", err)
 	}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/cheerioskun/logninja/internal/parser"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+var sampleLines int
+
+// patternsCmd groups the timestamp pattern commands
+var patternsCmd = &cobra.Command{
+	Use:   "patterns",
+	Short: "Inspect timestamp patterns",
+	Long: `Inspect the timestamp patterns used to recognise log files.
+
+Besides the built-in formats, patterns can be added in the config file:
+
+  timestamp_patterns:
+    - name: InHouse
+      regex: '\[\d{2}/\d{2}/\d{4} \d{2}:\d{2}:\d{2}\.\d{3} [A-Z]{3,4}\]'
+      layout: '[02/01/2006 15:04:05.000 MST]'
+      priority: 0
+    - name: Events
+      field: event.created
+    - name: Stamp
+      field: stamp
+      format: logfmt
+  json_timestamp_field: logged_at
+
+InHouse matches lines like "[16/10/2026 14:05:01.123 UTC] worker started". The
+layout uses Go's reference time (or "unix" for epoch numbers). If the regex has a
+capture group, only the first group is parsed. Patterns with a field read the
+timestamp from that key of JSON (or logfmt) lines, as json_timestamp_field does
+for a single JSON key.
+
+Lower priorities are tried first, and custom patterns win ties. The built-in
+patterns use -10 to -1 for JSON and logfmt fields and 0 to 21 for text formats,
+so the default priority 0 comes after the field patterns but before every
+built-in text format.`,
+}
+
+// patternsTestCmd shows how every pattern fares on the start of a file
+var patternsTestCmd = &cobra.Command{
+	Use:   "test <file>",
+	Short: "Show which timestamp pattern wins on a file",
+	Long: `Sample the first lines of a file and show how each timestamp pattern fares:
+how many lines it matched, how many of those it could parse, and the first timestamp.
+With the default 10 lines, the winner is the pattern a scan would pick for the file.
+
+Examples:
+  logninja patterns test /var/log/app.log
+  logninja patterns test ./server.log.gz --lines 50`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPatternsTest,
+}
+
+func init() {
+	rootCmd.AddCommand(patternsCmd)
+	patternsCmd.AddCommand(patternsTestCmd)
+
+	patternsTestCmd.Flags().IntVar(&sampleLines, "lines", 10, "number of non-empty lines to sample")
+}
+
+func runPatternsTest(cmd *cobra.Command, args []string) error {
+	filePath, err := filepath.Abs(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to resolve absolute path: %w", err)
+	}
+	if sampleLines <= 0 {
+		return fmt.Errorf("--lines must be positive")
+	}
+
+	fs := afero.NewOsFs()
+	file, _, err := parser.OpenDecompressed(fs, filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	// Keep a copy of what was sampled so it can be shown alongside the scores
+	var sample bytes.Buffer
+	extractor := parser.NewTimestampExtractor(fs).ForFile(filePath)
+	scores, lineCount, err := extractor.ScorePatterns(io.TeeReader(file, &sample), sampleLines)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	fmt.Printf("Sampled %d lines of %s:\n", lineCount, filePath)
+	printSample(parser.SampleLines(sample.Bytes(), lineCount))
+
+	best := parser.BestScore(scores)
+	fmt.Println()
+	printPatternScores(scores, best, lineCount)
+
+	fmt.Println()
+	if best == nil {
+		fmt.Println("No pattern matched: the file would not be recognised as a log")
+		return nil
+	}
+	fmt.Printf("Winner: %s (%d/%d lines, %.0f%% confidence)\n",
+		best.Pattern.Name, best.Matches, lineCount, float64(best.Matches)/float64(lineCount)*100)
+	if best.Parsed < best.Matches {
+		fmt.Printf("Warning: the winning layout %q failed to parse %d of its matches\n",
+			best.Pattern.Layout, best.Matches-best.Parsed)
+	}
+
+	return nil
+}
+
+// printPatternScores tabulates the patterns that matched any sampled line, marking the best one
+func printPatternScores(scores []parser.PatternScore, best *parser.PatternScore, lineCount int) {
+	fmt.Printf("%-32s %8s %8s %8s  %s\n", "PATTERN", "PRIORITY", "MATCHED", "PARSED", "FIRST TIMESTAMP")
+	unmatched := 0
+	for _, score := range scores {
+		if score.Matches == 0 {
+			unmatched++
+			continue
+		}
+
+		marker := "  "
+		if best != nil && best.Pattern == score.Pattern {
+			marker = "* "
+		}
+		name := score.Pattern.Name
+		if score.Pattern.Custom {
+			name += " (custom)"
+		}
+		first := "-"
+		if score.Parsed > 0 {
+			first = score.FirstTime.Format("2006-01-02 15:04:05.000 MST")
+		}
+
+		fmt.Printf("%s%-30s %8d %8s %8d  %s\n", marker, name, score.Pattern.Priority,
+			fmt.Sprintf("%d/%d", score.Matches, lineCount), score.Parsed, first)
+	}
+	if unmatched > 0 {
+		fmt.Printf("  (%d other patterns matched nothing)\n", unmatched)
+	}
+}
+
+// printSample lists the sampled lines, truncated to fit a terminal
+func printSample(lines []string) {
+	const maxWidth = 100
+
+	for i, line := range lines {
+		if len(line) > maxWidth {
+			line = line[:maxWidth-3] + "..."
+		}
+		fmt.Printf("  %3d │ %s\n", i+1, line)
+	}
+}
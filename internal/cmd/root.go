@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/cheerioskun/logninja/internal/models"
+	"github.com/cheerioskun/logninja/internal/parser"
+	"github.com/cheerioskun/logninja/internal/scanner"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -41,9 +44,13 @@ func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.logninja.yaml)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().String("timezone", "", "zone of timestamps without an offset, e.g. Europe/Berlin (default UTC)")
+	rootCmd.PersistentFlags().String("display-timezone", "", "zone times are shown in, e.g. Local (default UTC)")
 
 	// Bind flags to viper
 	viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
+	viper.BindPFlag("timezone.default", rootCmd.PersistentFlags().Lookup("timezone"))
+	viper.BindPFlag("timezone.display", rootCmd.PersistentFlags().Lookup("display-timezone"))
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -69,4 +76,98 @@ func initConfig() {
 	if err := viper.ReadInConfig(); err == nil && verbose {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
+
+	loadTimestampPatterns()
+	loadTimezones()
+	loadDetectionThresholds()
+}
+
+// loadTimestampPatterns registers the timestamp_patterns list from the config file, e.g.
+//
+//	timestamp_patterns:
+//	  - name: InHouse
+//	    regex: '\[\d{2}/\d{2}/\d{4} \d{2}:\d{2}:\d{2}\.\d{3} [A-Z]{3,4}\]'
+//	    layout: '[02/01/2006 15:04:05.000 MST]'
+//	    priority: 0
+//	  - name: Events
+//	    field: event.created  # JSON lines
+//	  - name: Stamp
+//	    field: stamp
+//	    format: logfmt
+//	json_timestamp_field: logged_at
+//
+// The InHouse entry matches lines like "[16/10/2026 14:05:01.123 UTC] worker started".
+// Built-in patterns use priorities -10 to -1 for JSON and logfmt fields and 0 to 21 for
+// text; custom patterns win ties, so the default priority 0 is tried after the field
+// patterns but before every built-in text one. json_timestamp_field names the timestamp
+// key of JSON lines when it isn't one of the common ones. Invalid entries are reported
+// and skipped.
+func loadTimestampPatterns() {
+	var defs []parser.PatternDefinition
+	if err := viper.UnmarshalKey("timestamp_patterns", &defs); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring timestamp_patterns in %s: %v\n", viper.ConfigFileUsed(), err)
+	}
+
+	// A configured JSON timestamp key outranks the common ones
+	if field := viper.GetString("json_timestamp_field"); field != "" {
+		defs = append(defs, parser.PatternDefinition{Name: "JSON_Configured_" + field, Field: field, Priority: -100})
+	}
+	if len(defs) == 0 {
+		return
+	}
+
+	if err := parser.SetCustomPatterns(defs); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: invalid timestamp patterns in %s:\n%v\n", viper.ConfigFileUsed(), err)
+	}
+}
+
+// loadTimezones applies the timezone settings from the flags or the config file, e.g.
+//
+//	timezone:
+//	  default: UTC            # timestamps without an offset
+//	  display: Local          # zone times are shown in
+//	  overrides:
+//	    - glob: "*/var/log/*" # host-local syslog
+//	      zone: Europe/Berlin
+//
+// Explicit offsets and zone names in the logs always take precedence.
+func loadTimezones() {
+	config := parser.TimezoneConfig{Default: viper.GetString("timezone.default")}
+	if err := viper.UnmarshalKey("timezone.overrides", &config.Overrides); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring timezone overrides in %s: %v\n", viper.ConfigFileUsed(), err)
+	}
+	if err := parser.SetTimezones(config); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: invalid timezone settings:\n%v\n", err)
+	}
+
+	if display := viper.GetString("timezone.display"); display != "" {
+		location, err := parser.LoadZone(display)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: invalid display timezone: %v\n", err)
+			return
+		}
+		models.SetDisplayLocation(location)
+	}
+}
+
+// loadDetectionThresholds applies the thresholds deciding which files are log files, e.g.
+//
+//	detection:
+//	  min_matches: 2       # sampled lines with a timestamp, out of the first 10
+//	  min_confidence: 0.3  # share of the sampled lines with a timestamp
+//
+// Settings left out keep their defaults. 'logninja explain <file>' shows how a file fares.
+func loadDetectionThresholds() {
+	if !viper.IsSet("detection") {
+		return
+	}
+
+	config := scanner.DefaultDetectionThresholds()
+	if err := viper.UnmarshalKey("detection", &config); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring detection settings in %s: %v\n", viper.ConfigFileUsed(), err)
+		return
+	}
+	if err := scanner.SetDetectionThresholds(config); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: invalid detection settings in %s:\n%v\n", viper.ConfigFileUsed(), err)
+	}
 }
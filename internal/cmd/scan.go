@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
 
+	"github.com/cheerioskun/logninja/internal/models"
 	"github.com/cheerioskun/logninja/internal/scanner"
-	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -34,7 +37,8 @@ Provides an overview of what would be available in the TUI:
 Examples:
   logninja scan /var/log
   logninja scan ./my-logs --quick
-  logninja scan /path/to/logs --max-depth 3`,
+  logninja scan /path/to/logs --max-depth 3
+  logninja scan ./must-gather.zip`,
 	Args: cobra.ExactArgs(1),
 	RunE: runScan,
 }
@@ -44,6 +48,8 @@ func init() {
 
 	// Scan-specific flags
 	scanCmd.Flags().IntVar(&maxDepth, "max-depth", 10, "maximum directory depth to scan")
+	scanCmd.Flags().IntVar(&scanWorkers, "workers", runtime.NumCPU(), "number of files to peek at concurrently while scanning")
+	scanCmd.Flags().BoolVar(&noScanCache, "no-cache", false, "ignore and do not update the scan index cache")
 	scanCmd.Flags().BoolVar(&quickScan, "quick", false, "perform quick scan (top-level only)")
 
 	// Bind flags to viper
@@ -64,12 +70,18 @@ func runScan(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("path does not exist: %s", absPath)
 	}
 
-	// Create filesystem interface
-	fs := afero.NewOsFs()
+	// Create filesystem interface (archives are mounted as a virtual filesystem)
+	fs, cleanup, err := openBundleFs(absPath)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
 
 	// Create bundle scanner
 	bundleScanner := scanner.NewBundleScanner(fs)
 	bundleScanner.SetMaxDepth(maxDepth)
+	bundleScanner.SetWorkers(scanWorkers)
+	configureIndexCache(bundleScanner)
 
 	fmt.Printf("Scanning: %s\n", absPath)
 	fmt.Printf("Max depth: %d\n", maxDepth)
@@ -89,7 +101,7 @@ func runScan(cmd *cobra.Command, args []string) error {
 
 	} else {
 		// Full scan
-		bundle, err := bundleScanner.ScanBundle(absPath)
+		bundle, err := scanWithProgress(bundleScanner, absPath, os.Stderr)
 		if err != nil {
 			return fmt.Errorf("scan failed: %w", err)
 		}
@@ -103,9 +115,21 @@ func runScan(cmd *cobra.Command, args []string) error {
 
 		if bundle.TimeRange != nil {
 			fmt.Printf("  Time range: %s\n", bundle.TimeRange.String())
+			fmt.Printf("  Time span: %s\n", bundle.TimeRange.Duration().Round(time.Second))
 		} else {
-			fmt.Printf("  Time range: (not available - timestamps not parsed)\n")
+			fmt.Printf("  Time range: (not available - no timestamps found)\n")
+		}
+
+		bundleLevels := make(models.LevelCounts)
+		for _, file := range bundle.Files {
+			bundleLevels.Add(file.LevelCounts)
 		}
+		if bundleLevels.Total() > 0 {
+			fmt.Printf("  Entries by level: %s\n", formatLevelCounts(bundleLevels))
+		}
+
+		fmt.Println()
+		printFormatBreakdown(bundle.Metadata)
 
 		fmt.Println()
 
@@ -125,14 +149,30 @@ func runScan(cmd *cobra.Command, args []string) error {
 				if file.IsLogFile {
 					logFiles++
 					if logFiles <= 10 { // Show first 10 log files
-						fmt.Printf("  [LOG] %s (%s, detected by content analysis)\n",
-							file.Path, formatBytes(file.Size))
+						if file.IsCompressed() {
+							fmt.Printf("  [LOG] %s (%s, %s → %s uncompressed, detected by content analysis)\n",
+								file.Path, file.Compression, formatBytes(file.Size), formatBytes(file.UncompressedSize))
+						} else if file.Encoding != "" {
+							fmt.Printf("  [LOG] %s (%s, %s, detected by content analysis)\n",
+								file.Path, file.Encoding, formatBytes(file.Size))
+						} else {
+							fmt.Printf("  [LOG] %s (%s, detected by content analysis)\n",
+								file.Path, formatBytes(file.Size))
+						}
+						if file.TimeRange != nil {
+							fmt.Printf("        %s (%s, %d lines, %d entries)\n", file.TimeRange.String(), file.TimestampPattern, file.EstimatedLines, file.EntryCount)
+						} else {
+							fmt.Printf("        (no time range - timestamps could not be bounded)\n")
+						}
+						if file.LevelCounts.Total() > 0 {
+							fmt.Printf("        %s\n", formatLevelCounts(file.LevelCounts))
+						}
 					}
 				} else {
 					otherFiles++
 					if otherFiles <= 5 { // Show first 5 other files
-						fmt.Printf("  [FILE] %s (%s)\n",
-							file.Path, formatBytes(file.Size))
+						fmt.Printf("  [FILE] %s (%s, %s)\n",
+							file.Path, file.KindLabel(), formatBytes(file.Size))
 					}
 				}
 			}
@@ -149,6 +189,30 @@ func runScan(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// printFormatBreakdown lists the detected log formats with their file counts, sizes and mean
+// detection confidence, followed by the files no format was detected in
+func printFormatBreakdown(metadata models.BundleMetadata) {
+	fmt.Println("Formats:")
+	for _, stats := range metadata.CommonFormats {
+		fmt.Printf("  %-28s %6d files %10s  %3.0f%% confidence\n",
+			stats.Format, stats.FileCount, formatBytes(stats.Size), stats.Confidence*100)
+	}
+	if metadata.Undetected > 0 {
+		fmt.Printf("  %-28s %6d files\n", models.UndetectedFormat, metadata.Undetected)
+	}
+}
+
+// formatLevelCounts lists the non-zero level counts from most to least severe
+func formatLevelCounts(counts models.LevelCounts) string {
+	var parts []string
+	for _, level := range models.LogLevels {
+		if count := counts[level]; count > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", level, count))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
 func formatBytes(bytes int64) string {
 	const unit = 1024
 	if bytes < unit {
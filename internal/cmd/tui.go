@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/cheerioskun/logninja/internal/models"
@@ -31,9 +33,15 @@ The TUI provides:
 - Time range selection
 - Real-time size estimation
 
+The bundle may be a directory or a tar/zip archive (.tar, .tar.gz, .tar.xz,
+.tar.bz2, .tar.zst, .zip). Plain tars and stored zip members are read in place;
+compressed members are decompressed once into a temporary file that is deleted
+as soon as it is created, so nothing is left behind on disk.
+
 Examples:
   logninja tui /var/log
-  logninja tui ./my-logs --max-depth 5`,
+  logninja tui ./my-logs --max-depth 5
+  logninja tui ./sosreport-host.tar.xz`,
 	Args: cobra.ExactArgs(1),
 	RunE: runTUI,
 }
@@ -43,6 +51,8 @@ func init() {
 
 	// TUI-specific flags
 	tuiCmd.Flags().IntVar(&maxDepth, "max-depth", 10, "maximum directory depth to scan")
+	tuiCmd.Flags().IntVar(&scanWorkers, "workers", runtime.NumCPU(), "number of files to peek at concurrently while scanning")
+	tuiCmd.Flags().BoolVar(&noScanCache, "no-cache", false, "ignore and do not update the scan index cache")
 
 	// Bind flags to viper
 	viper.BindPFlag("max-depth", tuiCmd.Flags().Lookup("max-depth"))
@@ -62,34 +72,31 @@ func runTUI(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("path does not exist: %s", absPath)
 	}
 
-	// Create filesystem interface
-	fs := afero.NewOsFs()
+	// Create filesystem interface (archives are mounted as a virtual filesystem)
+	fs, cleanup, err := openBundleFs(absPath)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
 
 	// Create bundle scanner
-	scanner := scanner.NewBundleScanner(fs)
-	scanner.SetMaxDepth(maxDepth)
+	bundleScanner := scanner.NewBundleScanner(fs)
+	bundleScanner.SetMaxDepth(maxDepth)
+	bundleScanner.SetWorkers(scanWorkers)
+	configureIndexCache(bundleScanner)
 
 	if viper.GetBool("verbose") {
 		fmt.Fprintf(os.Stderr, "Scanning bundle at: %s\n", absPath)
 		fmt.Fprintf(os.Stderr, "Max depth: %d\n", maxDepth)
 	}
 
-	// Scan the bundle
-	bundle, err := scanner.ScanBundle(absPath)
-	if err != nil {
-		return fmt.Errorf("failed to scan bundle: %w", err)
-	}
-
-	if viper.GetBool("verbose") {
-		fmt.Fprintf(os.Stderr, "Found %d files (%d log files)\n",
-			bundle.Metadata.TotalFileCount, bundle.Metadata.LogFileCount)
+	// The bundle is scanned inside the TUI so progress is visible and Ctrl+C can abort it
+	scan := func(ctx context.Context, progress chan<- scanner.ScanProgress) (*models.Bundle, error) {
+		return bundleScanner.ScanBundleContext(ctx, absPath, progress)
 	}
 
-	// Create working set
-	workingSet := models.NewWorkingSet(bundle)
-
-	// Initialize TUI
-	model := ui.NewAppModel(workingSet, fs)
+	// Initialize TUI; exports are always written to the OS filesystem
+	model := ui.NewLoaderModel(absPath, scan, afero.NewOsFs())
 
 	// Start the TUI program
 	program := tea.NewProgram(model, tea.WithAltScreen())
@@ -98,10 +105,21 @@ func runTUI(cmd *cobra.Command, args []string) error {
 		fmt.Fprintf(os.Stderr, "Starting TUI...\n")
 	}
 
-	_, err = program.Run()
+	finalModel, err := program.Run()
 	if err != nil {
 		return fmt.Errorf("TUI error: %w", err)
 	}
 
+	// The loader is only still the active model if the scan never completed
+	if loader, ok := finalModel.(*ui.LoaderModel); ok {
+		if loader.Cancelled() {
+			fmt.Fprintln(os.Stderr, "Scan cancelled")
+			return nil
+		}
+		if loader.Err() != nil {
+			return fmt.Errorf("failed to scan bundle: %w", loader.Err())
+		}
+	}
+
 	return nil
 }
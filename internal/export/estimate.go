@@ -0,0 +1,128 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/cheerioskun/logninja/internal/models"
+	"github.com/cheerioskun/logninja/internal/parser"
+)
+
+// estimateSampleSize is how much of each file is read to estimate the share of its bytes the
+// line filters keep; smaller files (and windows) are measured exactly
+const estimateSampleSize = 256 * 1024
+
+// errSampleFull stops reading once a sample is large enough
+var errSampleFull = errors.New("sample full")
+
+// estimateKey identifies a kept-fraction measurement of one file span under one set of filters
+type estimateKey struct {
+	path       string
+	start, end int64
+}
+
+// EstimateFilteredSize estimates how many bytes an export of the working set writes once its
+// content filters are applied. Each file's kept fraction is measured on a sample from the
+// start of its window and scaled to the whole window.
+func (s *Service) EstimateFilteredSize(ctx context.Context, ws *models.WorkingSet) (int64, error) {
+	if ws == nil || ws.Bundle == nil {
+		return 0, fmt.Errorf("invalid working set")
+	}
+
+	var total int64
+	for _, file := range ws.Bundle.Files {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		if !ws.IsFileSelected(file.Path) || !ws.PassesTimeFilter(&file) {
+			continue
+		}
+
+		plan := s.planFile(ws, &file)
+		if plan.trimmed && plan.size == 0 {
+			continue // No lines inside the window
+		}
+
+		size, err := s.filteredSize(ctx, plan)
+		if err != nil {
+			return 0, err
+		}
+		total += size
+	}
+
+	return total, nil
+}
+
+// filteredSize estimates the bytes written for a planned file after line filtering
+func (s *Service) filteredSize(ctx context.Context, plan filePlan) (int64, error) {
+	if plan.lineFilter == nil {
+		return plan.size, nil
+	}
+
+	fraction, err := s.keptFraction(ctx, plan)
+	if err != nil {
+		return 0, err
+	}
+	return int64(fraction * float64(plan.size)), nil
+}
+
+// keptFraction measures the share of bytes the plan's line filter keeps in a sample of the file.
+// Results are cached for the current filters so repeated estimates stay cheap.
+func (s *Service) keptFraction(ctx context.Context, plan filePlan) (float64, error) {
+	key := estimateKey{path: plan.sourcePath}
+	if plan.trimmed {
+		key.start, key.end = plan.window.Start, plan.window.End
+	}
+	filterKey := plan.lineFilter.Key()
+
+	s.estimatesMu.Lock()
+	if s.estimatesFilter != filterKey {
+		// Only the latest filters are worth keeping
+		s.estimates = make(map[estimateKey]float64)
+		s.estimatesFilter = filterKey
+	}
+	fraction, ok := s.estimates[key]
+	s.estimatesMu.Unlock()
+	if ok {
+		return fraction, nil
+	}
+
+	source, closer, err := s.openPlanned(plan)
+	if err != nil {
+		return 0, err
+	}
+	defer closer.Close()
+
+	var read, kept int64
+	err = parser.ForEachEntry(source, plan.entries, func(lines [][]byte) error {
+		var size int64
+		for _, line := range lines {
+			size += int64(len(line))
+		}
+		read += size
+		if plan.keepEntry(lines) {
+			kept += size
+		}
+		if read >= estimateSampleSize {
+			return errSampleFull
+		}
+		return ctx.Err()
+	})
+	if err != nil && !errors.Is(err, errSampleFull) {
+		return 0, fmt.Errorf("failed to sample %s: %w", plan.sourcePath, err)
+	}
+
+	fraction = 1
+	if read > 0 {
+		fraction = float64(kept) / float64(read)
+	}
+
+	s.estimatesMu.Lock()
+	if s.estimatesFilter == filterKey {
+		s.estimates[key] = fraction
+	}
+	s.estimatesMu.Unlock()
+
+	return fraction, nil
+}
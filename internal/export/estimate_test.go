@@ -0,0 +1,159 @@
+package export
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/cheerioskun/logninja/internal/models"
+	"github.com/spf13/afero"
+)
+
+// contentFilter builds a compiled content filter
+func contentFilter(pattern string, take bool) models.RegexFilter {
+	return models.RegexFilter{
+		Pattern:  pattern,
+		Take:     take,
+		Target:   models.FilterTargetContent,
+		Compiled: regexp.MustCompile(pattern),
+		Valid:    true,
+	}
+}
+
+// keptBytes applies filters to the entries of content and returns the bytes kept; indented
+// lines continue the entry above them
+func keptBytes(content string, filters []models.RegexFilter) int64 {
+	lineFilter := NewLineFilter(filters)
+	var kept int64
+	var entry [][]byte
+	var entrySize int64
+	flush := func() {
+		if len(entry) > 0 && lineFilter.KeepEntry(entry) {
+			kept += entrySize
+		}
+		entry, entrySize = nil, 0
+	}
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "\t") {
+			flush()
+		}
+		entry = append(entry, []byte(strings.TrimSuffix(line, "\n")))
+		entrySize += int64(len(line))
+	}
+	flush()
+	return kept
+}
+
+func TestEstimateFilteredSize(t *testing.T) {
+	content, window := minuteLog(15, 30)
+
+	var gzipped bytes.Buffer
+	writer := gzip.NewWriter(&gzipped)
+	writer.Write([]byte(content))
+	writer.Close()
+
+	root := t.TempDir()
+	sources := map[string][]byte{
+		"app.log":        []byte(content),
+		"old/app.log.gz": gzipped.Bytes(),
+	}
+	for name, data := range sources {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bundle := models.NewBundle(root, afero.NewOsFs())
+	bundle.Files = []models.FileInfo{
+		{Path: "app.log", Size: int64(len(content)), IsLogFile: true, TimeRange: minuteRange(0, 59), TimestampPattern: "DateTime_Dash"},
+		{Path: "old/app.log.gz", Size: int64(gzipped.Len()), UncompressedSize: int64(len(content)), Compression: "gzip",
+			IsLogFile: true, TimeRange: minuteRange(0, 59), TimestampPattern: "DateTime_Dash"},
+	}
+
+	tests := []struct {
+		name    string
+		filters []models.RegexFilter
+		window  *models.TimeRange
+		source  string // Content each file is filtered from
+	}{
+		{"no filters", nil, nil, content},
+		{"drop filter removes whole entries", []models.RegexFilter{contentFilter("continued", false)}, nil, content},
+		{"take filter", []models.RegexFilter{contentFilter(`tick [0-9]*5$`, true)}, nil, content},
+		{"last match decides", []models.RegexFilter{contentFilter("tick", true), contentFilter("tick 2", false)}, nil, content},
+		{"take filter in a window", []models.RegexFilter{contentFilter(`tick [0-9]*5$`, true)}, minuteRange(15, 30), window},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := models.NewWorkingSet(bundle)
+			ws.SelectAllFiles()
+			ws.SetRegexFilters(tt.filters)
+			ws.SetTimeFilter(tt.window)
+
+			service := NewServiceWithFilesystems(afero.NewOsFs(), afero.NewMemMapFs())
+			got, err := service.EstimateFilteredSize(context.Background(), ws)
+			if err != nil {
+				t.Fatalf("EstimateFilteredSize() error = %v", err)
+			}
+
+			// Files smaller than a sample are measured exactly
+			want := int64(len(content) + gzipped.Len())
+			if len(tt.filters) > 0 {
+				want = 2 * keptBytes(tt.source, tt.filters)
+			}
+			if got != want {
+				t.Errorf("EstimateFilteredSize() = %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func TestEstimateFilteredSizeScalesSample(t *testing.T) {
+	// Every fourth line is kept, so the sample is representative of the whole file
+	var content strings.Builder
+	for i := 0; content.Len() < 4*estimateSampleSize; i++ {
+		level := []string{"ERROR", "INFO", "INFO", "DEBUG"}[i%4]
+		fmt.Fprintf(&content, "2024-01-02 10:00:00 %s worker handled request %08d\n", level, i)
+	}
+
+	fs := afero.NewMemMapFs()
+	if err := afero.WriteFile(fs, "/bundle/app.log", []byte(content.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	bundle := models.NewBundle("/bundle", fs)
+	bundle.Files = []models.FileInfo{{Path: "app.log", Size: int64(content.Len()), IsLogFile: true}}
+
+	ws := models.NewWorkingSet(bundle)
+	ws.SelectAllFiles()
+	ws.SetRegexFilters([]models.RegexFilter{contentFilter("ERROR", true)})
+
+	service := NewServiceWithFilesystems(fs, afero.NewMemMapFs())
+	got, err := service.EstimateFilteredSize(context.Background(), ws)
+	if err != nil {
+		t.Fatalf("EstimateFilteredSize() error = %v", err)
+	}
+
+	want := keptBytes(content.String(), ws.RegexFilters)
+	if diff := got - want; diff < -want/100 || diff > want/100 {
+		t.Errorf("EstimateFilteredSize() = %d, want %d within 1%%", got, want)
+	}
+
+	// Repeated estimates come from the cache
+	again, err := service.EstimateFilteredSize(context.Background(), ws)
+	if err != nil || again != got {
+		t.Errorf("repeated EstimateFilteredSize() = %d, %v, want %d", again, err, got)
+	}
+}
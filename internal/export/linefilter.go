@@ -0,0 +1,77 @@
+package export
+
+import (
+	"github.com/cheerioskun/logninja/internal/models"
+	"github.com/cheerioskun/logninja/internal/structured"
+)
+
+// LineFilter applies the content filters of a working set to log entries: a timestamped
+// line together with its continuation lines, so a stack trace is kept or dropped whole.
+// A filter matches an entry if it matches any of its lines. Like path filters, the last
+// matching filter decides: an entry is kept if it is a take filter. Entries no filter
+// matches are kept only when there are no take filters, so a list of drop filters alone
+// removes entries and leaves everything else. Filters with a key match the value of that
+// field in JSON or logfmt lines rather than the whole line.
+type LineFilter struct {
+	filters []models.RegexFilter
+	hasTake bool
+}
+
+// NewLineFilter builds a filter from the valid content filters in the list,
+// returning nil if there are none
+func NewLineFilter(filters []models.RegexFilter) *LineFilter {
+	lf := &LineFilter{}
+	for _, filter := range filters {
+		if !filter.IsContentFilter() || !filter.Valid || filter.Compiled == nil {
+			continue
+		}
+		lf.filters = append(lf.filters, filter)
+		if filter.Take {
+			lf.hasTake = true
+		}
+	}
+
+	if len(lf.filters) == 0 {
+		return nil
+	}
+	return lf
+}
+
+// KeepEntry returns true if the entry made of lines (without their newlines) survives the filters
+func (lf *LineFilter) KeepEntry(lines [][]byte) bool {
+	for i := len(lf.filters) - 1; i >= 0; i-- {
+		for _, line := range lines {
+			if matchesLine(&lf.filters[i], line) {
+				return lf.filters[i].Take
+			}
+		}
+	}
+	return !lf.hasTake
+}
+
+// Key identifies the filter's patterns, for caching results computed with it
+func (lf *LineFilter) Key() string {
+	key := ""
+	for _, filter := range lf.filters {
+		if filter.Take {
+			key += "+"
+		} else {
+			key += "-"
+		}
+		if filter.Key != "" {
+			key += filter.Key + "="
+		}
+		key += filter.Pattern + "\x00"
+	}
+	return key
+}
+
+// matchesLine returns true if a content filter matches a log line: the value of its key if
+// it has one (lines without the key never match), otherwise the whole line
+func matchesLine(filter *models.RegexFilter, line []byte) bool {
+	if filter.Key == "" {
+		return filter.Compiled.Match(line)
+	}
+	value, ok := structured.Field(line, filter.Key)
+	return ok && filter.Compiled.Match(value)
+}
@@ -0,0 +1,53 @@
+package export
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/cheerioskun/logninja/internal/models"
+)
+
+func TestLineFilterKeepEntry(t *testing.T) {
+	fieldFilter := func(key, pattern string, take bool) models.RegexFilter {
+		filter := contentFilter(pattern, take)
+		filter.Key = key
+		return filter
+	}
+
+	tests := []struct {
+		name    string
+		filters []models.RegexFilter
+		entry   []string
+		want    bool
+	}{
+		{"drop matches a continuation line", []models.RegexFilter{contentFilter("Exception", false)},
+			[]string{"2024-01-02 10:00:00 ERROR failed", "java.lang.RuntimeException: boom"}, false},
+		{"take keeps only matches", []models.RegexFilter{contentFilter("ERROR", true)},
+			[]string{"2024-01-02 10:00:00 INFO started"}, false},
+		{"JSON field", []models.RegexFilter{fieldFilter("level", "^error$", true)},
+			[]string{`{"level":"error","msg":"level info"}`}, true},
+		{"JSON field ignores the rest of the line", []models.RegexFilter{fieldFilter("level", "^info$", true)},
+			[]string{`{"level":"error","msg":"info"}`}, false},
+		{"logfmt field", []models.RegexFilter{fieldFilter("user", "^alice$", false)},
+			[]string{`ts=2024-01-02T10:00:00Z user=alice msg="signed in"`}, false},
+		{"lines without the field never match", []models.RegexFilter{fieldFilter("user", ".", false)},
+			[]string{"2024-01-02 10:00:00 INFO user alice"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := make([][]byte, len(tt.entry))
+			for i, line := range tt.entry {
+				lines[i] = []byte(line)
+			}
+			if got := NewLineFilter(tt.filters).KeepEntry(lines); got != tt.want {
+				t.Errorf("KeepEntry(%q) = %v, want %v", tt.entry, got, tt.want)
+			}
+		})
+	}
+
+	invalid := models.RegexFilter{Pattern: "(", Target: models.FilterTargetContent, Error: "missing closing )"}
+	if NewLineFilter([]models.RegexFilter{invalid, {Pattern: "x", Compiled: regexp.MustCompile("x"), Valid: true}}) != nil {
+		t.Error("NewLineFilter() without valid content filters returned a filter")
+	}
+}
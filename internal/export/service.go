@@ -1,25 +1,52 @@
 package export
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
+	"github.com/cheerioskun/logninja/internal/archive"
 	"github.com/cheerioskun/logninja/internal/models"
+	"github.com/cheerioskun/logninja/internal/parser"
+	"github.com/cheerioskun/logninja/internal/utils"
 	"github.com/spf13/afero"
 )
 
 // Service handles export operations with directory structure preservation
 type Service struct {
-	fs afero.Fs
+	sourceFs           afero.Fs // Filesystem the bundle is read from
+	destinationFs      afero.Fs // Filesystem exported files are written to
+	timestampExtractor *parser.TimestampExtractor
+
+	// Byte windows located for the current time filter
+	windowsMu     sync.Mutex
+	windows       map[windowKey]parser.ByteRange
+	windowsFilter [2]int64
+
+	// Kept fractions measured for the current line filters
+	estimatesMu     sync.Mutex
+	estimates       map[estimateKey]float64
+	estimatesFilter string
 }
 
-// NewService creates a new export service
+// NewService creates a new export service that reads and writes through the same filesystem
 func NewService(fs afero.Fs) *Service {
+	return NewServiceWithFilesystems(fs, fs)
+}
+
+// NewServiceWithFilesystems creates an export service that reads bundle files from sourceFs
+// and writes them to destinationFs, e.g. copying members out of a read-only archive
+func NewServiceWithFilesystems(sourceFs, destinationFs afero.Fs) *Service {
 	return &Service{
-		fs: fs,
+		sourceFs:           sourceFs,
+		destinationFs:      destinationFs,
+		timestampExtractor: parser.NewTimestampExtractor(sourceFs),
+		windows:            make(map[windowKey]parser.ByteRange),
+		estimates:          make(map[estimateKey]float64),
 	}
 }
 
@@ -32,14 +59,24 @@ type ExportOptions struct {
 
 // ExportSummary contains information about the export operation
 type ExportSummary struct {
-	FileCount       int
-	TotalSize       int64
-	SourcePath      string
-	DestinationPath string
+	FileCount        int
+	TotalSize        int64 // Bytes that will be written, after trimming and before line filters
+	SourcePath       string
+	DestinationPath  string
+	TimeRange        *models.TimeRange // Active time filter, nil if none
+	TrimmedFileCount int               // Files cut down to the time window
+	LineFilterCount  int               // Content filters applied to log lines
+	FilteredSize     int64             // Estimated bytes left after content filters; TotalSize without them
 }
 
 // GetExportSummary calculates what would be exported without actually exporting
 func (s *Service) GetExportSummary(ws *models.WorkingSet, destPath string) (*ExportSummary, error) {
+	return s.GetExportSummaryContext(context.Background(), ws, destPath)
+}
+
+// GetExportSummaryContext calculates the export summary like GetExportSummary, giving up
+// with ctx.Err() once ctx is cancelled (e.g. because the modal was closed)
+func (s *Service) GetExportSummaryContext(ctx context.Context, ws *models.WorkingSet, destPath string) (*ExportSummary, error) {
 	if ws == nil || ws.Bundle == nil {
 		return nil, fmt.Errorf("invalid working set")
 	}
@@ -48,12 +85,43 @@ func (s *Service) GetExportSummary(ws *models.WorkingSet, destPath string) (*Exp
 		SourcePath:      ws.Bundle.Path,
 		DestinationPath: destPath,
 	}
+	if ws.HasTimeFilter() {
+		summary.TimeRange = ws.TimeFilter
+	}
+	for _, filter := range ws.RegexFilters {
+		if filter.IsContentFilter() && filter.Valid {
+			summary.LineFilterCount++
+		}
+	}
 
 	// Count selected files and calculate total size
 	for _, file := range ws.Bundle.Files {
-		if ws.IsFileSelected(file.Path) {
-			summary.FileCount++
-			summary.TotalSize += file.Size
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !ws.IsFileSelected(file.Path) || !ws.PassesTimeFilter(&file) {
+			continue
+		}
+
+		plan := s.planFile(ws, &file)
+		if plan.trimmed && plan.size == 0 {
+			continue // No lines inside the window
+		}
+
+		filteredSize, err := s.filteredSize(ctx, plan)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			utils.Warning("estimating filtered size of %s: %v", file.Path, err)
+			filteredSize = plan.size
+		}
+
+		summary.FileCount++
+		summary.TotalSize += plan.size
+		summary.FilteredSize += filteredSize
+		if plan.trimmed {
+			summary.TrimmedFileCount++
 		}
 	}
 
@@ -67,47 +135,58 @@ func (s *Service) ExportWorkingSet(ws *models.WorkingSet, opts ExportOptions) er
 	}
 
 	// Create destination directory if it doesn't exist
-	if err := s.fs.MkdirAll(opts.DestinationPath, 0755); err != nil {
+	if err := s.destinationFs.MkdirAll(opts.DestinationPath, 0755); err != nil {
 		return fmt.Errorf("failed to create destination directory: %w", err)
 	}
 
-	// Export each selected file
+	// Export each selected file that overlaps the time filter, trimmed to the window
 	for _, file := range ws.Bundle.Files {
-		if ws.IsFileSelected(file.Path) {
-			if err := s.exportFile(ws.Bundle.Path, file.Path, opts); err != nil {
-				return fmt.Errorf("failed to export file %s: %w", file.Path, err)
-			}
+		if !ws.IsFileSelected(file.Path) || !ws.PassesTimeFilter(&file) {
+			continue
+		}
+
+		plan := s.planFile(ws, &file)
+		if plan.trimmed && plan.size == 0 {
+			continue // No lines inside the window
+		}
+
+		if err := s.exportFile(plan, opts); err != nil {
+			return fmt.Errorf("failed to export file %s: %w", file.Path, err)
 		}
 	}
 
 	return nil
 }
 
-// exportFile copies a single file preserving directory structure
-func (s *Service) exportFile(bundlePath, relativePath string, opts ExportOptions) error {
-	// Source file path
-	sourcePath := filepath.Join(bundlePath, relativePath)
-
+// exportFile copies a single file, or its time window, preserving directory structure
+func (s *Service) exportFile(plan filePlan, opts ExportOptions) error {
 	// Destination file path (preserving directory structure)
-	destPath := filepath.Join(opts.DestinationPath, relativePath)
+	destPath := filepath.Join(opts.DestinationPath, plan.destRel)
 
 	// Create destination directory
 	destDir := filepath.Dir(destPath)
-	if err := s.fs.MkdirAll(destDir, 0755); err != nil {
+	if err := s.destinationFs.MkdirAll(destDir, 0755); err != nil {
 		return fmt.Errorf("failed to create directory %s: %w", destDir, err)
 	}
 
 	// Check if destination exists and handle overwrite
 	if !opts.Overwrite {
-		if exists, err := afero.Exists(s.fs, destPath); err != nil {
+		if exists, err := afero.Exists(s.destinationFs, destPath); err != nil {
 			return fmt.Errorf("failed to check if destination exists: %w", err)
 		} else if exists {
 			return fmt.Errorf("destination file exists and overwrite is disabled: %s", destPath)
 		}
 	}
 
-	// Copy the file
-	if err := s.copyFile(sourcePath, destPath); err != nil {
+	// Copy the window or filtered lines, or the whole file
+	if plan.trimmed || plan.lineFilter != nil {
+		if err := s.copyFileRange(plan, destPath); err != nil {
+			return fmt.Errorf("failed to copy filtered file: %w", err)
+		}
+		return nil
+	}
+
+	if err := s.copyFile(plan.sourcePath, destPath); err != nil {
 		return fmt.Errorf("failed to copy file: %w", err)
 	}
 
@@ -117,7 +196,7 @@ func (s *Service) exportFile(bundlePath, relativePath string, opts ExportOptions
 // copyFile copies a file from source to destination, preserving attributes
 func (s *Service) copyFile(sourcePath, destPath string) error {
 	// Open source file
-	srcFile, err := s.fs.Open(sourcePath)
+	srcFile, err := s.sourceFs.Open(sourcePath)
 	if err != nil {
 		return fmt.Errorf("failed to open source file: %w", err)
 	}
@@ -130,7 +209,7 @@ func (s *Service) copyFile(sourcePath, destPath string) error {
 	}
 
 	// Create destination file
-	destFile, err := s.fs.Create(destPath)
+	destFile, err := s.destinationFs.Create(destPath)
 	if err != nil {
 		return fmt.Errorf("failed to create destination file: %w", err)
 	}
@@ -143,13 +222,13 @@ func (s *Service) copyFile(sourcePath, destPath string) error {
 	}
 
 	// Preserve permissions
-	if err := s.fs.Chmod(destPath, srcInfo.Mode()); err != nil {
+	if err := s.destinationFs.Chmod(destPath, srcInfo.Mode()); err != nil {
 		// Log warning but don't fail the operation
 		// TODO: Add proper logging
 	}
 
 	// Preserve timestamps if possible
-	if _, ok := s.fs.(*afero.OsFs); ok {
+	if _, ok := s.destinationFs.(*afero.OsFs); ok {
 		if err := os.Chtimes(destPath, srcInfo.ModTime(), srcInfo.ModTime()); err != nil {
 			// Log warning but don't fail the operation
 			// TODO: Add proper logging
@@ -166,8 +245,8 @@ func GetDefaultExportPath(bundlePath string) (string, error) {
 		return "", fmt.Errorf("failed to get current working directory: %w", err)
 	}
 
-	// Extract the last component of the path for the suffix
-	baseName := filepath.Base(bundlePath)
+	// Extract the last component of the path for the suffix, dropping any archive extension
+	baseName := archive.TrimExtension(filepath.Base(bundlePath))
 
 	// Handle case where cwd is root
 	if baseName == "/" || baseName == "." {
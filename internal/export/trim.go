@@ -0,0 +1,212 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/cheerioskun/logninja/internal/models"
+	"github.com/cheerioskun/logninja/internal/parser"
+	"github.com/cheerioskun/logninja/internal/utils"
+	"github.com/spf13/afero"
+)
+
+// filePlan describes how a single selected file is exported
+type filePlan struct {
+	sourcePath string                // Absolute path of the source file
+	destRel    string                // Destination path relative to the export root
+	trimmed    bool                  // Only the time window bytes are copied
+	window     parser.ByteRange      // Decompressed byte span copied when trimmed
+	lineFilter *LineFilter           // Content filters applied entry by entry, nil to copy bytes as-is
+	entries    *parser.EntryDetector // Groups lines into entries for the line filter
+	size       int64                 // Bytes read from the source, before line filtering
+}
+
+// windowKey identifies a located time window of one file
+type windowKey struct {
+	path       string
+	start, end int64
+}
+
+// planFile decides whether a file is copied whole or trimmed to the working set's time window,
+// and whether its lines go through the content filters. Trimmed or filtered compressed files are
+// written decompressed, so their compression suffix is dropped; UTF-16 files are written as UTF-8.
+func (s *Service) planFile(ws *models.WorkingSet, file *models.FileInfo) filePlan {
+	plan := filePlan{
+		sourcePath: filepath.Join(ws.Bundle.Path, file.Path),
+		destRel:    file.Path,
+		size:       file.Size,
+	}
+
+	if file.IsLogFile {
+		plan.lineFilter = NewLineFilter(ws.RegexFilters)
+		plan.entries = parser.NewEntryDetector(s.timestampExtractor.ForFile(plan.sourcePath), s.timestampExtractor.PatternByName(file.TimestampPattern))
+	}
+	if plan.lineFilter != nil && file.IsCompressed() {
+		plan.destRel = parser.TrimCompressionExtension(file.Path, parser.CompressionFormat(file.Compression))
+		if file.UncompressedSize > 0 {
+			plan.size = file.UncompressedSize
+		}
+	}
+
+	if !ws.HasTimeFilter() || !file.IsLogFile || file.TimeRange == nil {
+		return plan
+	}
+
+	// Files entirely inside the window are copied unchanged
+	filter := ws.TimeFilter
+	if !file.TimeRange.Start.Before(filter.Start) && !file.TimeRange.End.After(filter.End) {
+		return plan
+	}
+
+	window, err := s.findWindow(plan.sourcePath, file, filter)
+	if err != nil {
+		utils.Warning("exporting %s untrimmed: %v", file.Path, err)
+		return plan
+	}
+
+	plan.trimmed = true
+	plan.window = window
+	plan.size = window.Len()
+	if file.IsCompressed() {
+		plan.destRel = parser.TrimCompressionExtension(file.Path, parser.CompressionFormat(file.Compression))
+	}
+
+	return plan
+}
+
+// findWindow locates the byte span of a file within the time filter.
+// Results are cached for the current filter so repeated summaries stay cheap.
+func (s *Service) findWindow(sourcePath string, file *models.FileInfo, filter *models.TimeRange) (parser.ByteRange, error) {
+	key := windowKey{path: sourcePath, start: filter.Start.UnixNano(), end: filter.End.UnixNano()}
+
+	s.windowsMu.Lock()
+	window, ok := s.windows[key]
+	s.windowsMu.Unlock()
+	if ok {
+		return window, nil
+	}
+
+	pattern := s.timestampExtractor.PatternByName(file.TimestampPattern)
+	if pattern == nil {
+		result, err := s.timestampExtractor.DetectBestPattern(sourcePath)
+		if err != nil {
+			return parser.ByteRange{}, err
+		}
+		if result.BestPattern == nil {
+			return parser.ByteRange{}, fmt.Errorf("no timestamp pattern detected")
+		}
+		pattern = result.BestPattern
+	}
+
+	window, err := parser.FindTimeWindow(s.sourceFs, sourcePath, s.timestampExtractor, pattern, filter.Start, filter.End)
+	if err != nil {
+		return parser.ByteRange{}, err
+	}
+
+	s.windowsMu.Lock()
+	if s.windowsFilter != [2]int64{key.start, key.end} {
+		// Only the latest window is worth keeping
+		s.windows = make(map[windowKey]parser.ByteRange)
+		s.windowsFilter = [2]int64{key.start, key.end}
+	}
+	s.windows[key] = window
+	s.windowsMu.Unlock()
+
+	return window, nil
+}
+
+// copyFileRange writes the (decompressed) source file to destPath, cut down to the plan's
+// time window and passed through its line filter one entry at a time
+func (s *Service) copyFileRange(plan filePlan, destPath string) error {
+	source, closer, err := s.openPlanned(plan)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+
+	srcInfo, err := s.sourceFs.Stat(plan.sourcePath)
+	if err != nil {
+		return fmt.Errorf("failed to get source file info: %w", err)
+	}
+
+	destFile, err := s.destinationFs.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("failed to create destination file: %w", err)
+	}
+	defer destFile.Close()
+
+	if plan.lineFilter == nil {
+		if _, err := io.Copy(destFile, source); err != nil {
+			return fmt.Errorf("failed to copy file contents: %w", err)
+		}
+	} else {
+		writer := bufio.NewWriter(destFile)
+		err := parser.ForEachEntry(source, plan.entries, func(lines [][]byte) error {
+			if !plan.keepEntry(lines) {
+				return nil
+			}
+			for _, line := range lines {
+				if _, err := writer.Write(line); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to filter file contents: %w", err)
+		}
+		if err := writer.Flush(); err != nil {
+			return fmt.Errorf("failed to write file contents: %w", err)
+		}
+	}
+
+	// Preserve permissions and timestamps like a whole-file copy
+	if err := s.destinationFs.Chmod(destPath, srcInfo.Mode()); err != nil {
+		utils.Warning("failed to preserve permissions of %s: %v", destPath, err)
+	}
+	if _, ok := s.destinationFs.(*afero.OsFs); ok {
+		if err := os.Chtimes(destPath, srcInfo.ModTime(), srcInfo.ModTime()); err != nil {
+			utils.Warning("failed to preserve timestamps of %s: %v", destPath, err)
+		}
+	}
+
+	return nil
+}
+
+// openPlanned opens the decompressed source of a plan positioned at its window, returning a
+// reader limited to the window and the underlying file to close
+func (s *Service) openPlanned(plan filePlan) (io.Reader, io.Closer, error) {
+	reader, _, err := parser.OpenDecompressed(s.sourceFs, plan.sourcePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open source file: %w", err)
+	}
+	if !plan.trimmed {
+		return reader, reader, nil
+	}
+
+	// Plain files seek straight to the window; decompressed streams are skipped through
+	if seeker, ok := reader.(io.Seeker); ok {
+		if _, err := seeker.Seek(plan.window.Start, io.SeekStart); err != nil {
+			reader.Close()
+			return nil, nil, fmt.Errorf("failed to seek to window start: %w", err)
+		}
+	} else if _, err := io.CopyN(io.Discard, reader, plan.window.Start); err != nil {
+		reader.Close()
+		return nil, nil, fmt.Errorf("failed to skip to window start: %w", err)
+	}
+
+	return io.LimitReader(reader, plan.window.Len()), reader, nil
+}
+
+// keepEntry applies the plan's line filter to an entry read by parser.ForEachEntry
+func (plan filePlan) keepEntry(lines [][]byte) bool {
+	trimmed := make([][]byte, len(lines))
+	for i, line := range lines {
+		trimmed[i] = bytes.TrimRight(line, "\r\n")
+	}
+	return plan.lineFilter.KeepEntry(trimmed)
+}
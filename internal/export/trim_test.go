@@ -0,0 +1,173 @@
+package export
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cheerioskun/logninja/internal/models"
+	"github.com/spf13/afero"
+)
+
+// trimTestStart is the timestamp of the first line of generated test logs
+var trimTestStart = time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+
+// minuteLog returns an hour of log lines one minute apart, each tenth one followed by a
+// continuation line, and the lines of minutes [from, to]
+func minuteLog(from, to int) (content, window string) {
+	var all, selected strings.Builder
+	for minute := 0; minute < 60; minute++ {
+		entry := fmt.Sprintf("%s INFO tick %d\n", trimTestStart.Add(time.Duration(minute)*time.Minute).Format("2006-01-02 15:04:05"), minute)
+		if minute%10 == 0 {
+			entry += "\tcontinued\n"
+		}
+		all.WriteString(entry)
+		if minute >= from && minute <= to {
+			selected.WriteString(entry)
+		}
+	}
+	return all.String(), selected.String()
+}
+
+// minuteRange returns the time range covering minutes [from, to] of a generated log
+func minuteRange(from, to int) *models.TimeRange {
+	return &models.TimeRange{Start: trimTestStart.Add(time.Duration(from) * time.Minute), End: trimTestStart.Add(time.Duration(to) * time.Minute)}
+}
+
+func TestExportTrimsToTimeWindow(t *testing.T) {
+	content, window := minuteLog(15, 30)
+
+	var gzipped bytes.Buffer
+	writer := gzip.NewWriter(&gzipped)
+	writer.Write([]byte(content))
+	writer.Close()
+
+	root := t.TempDir()
+	sources := map[string][]byte{
+		"app.log":        []byte(content),
+		"old/app.log.gz": gzipped.Bytes(),
+		"notes.txt":      []byte("not a log\n"),
+	}
+	for name, data := range sources {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bundle := models.NewBundle(root, afero.NewOsFs())
+	logFile := func(path, compression string, timeRange *models.TimeRange) models.FileInfo {
+		return models.FileInfo{
+			Path:             path,
+			Size:             int64(len(sources[path])),
+			Compression:      compression,
+			IsLogFile:        true,
+			TimeRange:        timeRange,
+			TimestampPattern: "DateTime_Dash",
+		}
+	}
+	bundle.Files = []models.FileInfo{
+		logFile("app.log", "", minuteRange(0, 59)),
+		logFile("old/app.log.gz", "gzip", minuteRange(0, 59)),
+		{Path: "notes.txt", Size: int64(len(sources["notes.txt"]))},
+	}
+
+	tests := []struct {
+		name        string
+		filter      *models.TimeRange
+		want        map[string]string
+		wantTrimmed int
+	}{
+		{
+			name:   "no time filter",
+			filter: nil,
+			want: map[string]string{
+				"app.log":        content,
+				"old/app.log.gz": string(gzipped.Bytes()),
+				"notes.txt":      "not a log\n",
+			},
+		},
+		{
+			name:   "window inside the files",
+			filter: minuteRange(15, 30),
+			want: map[string]string{
+				"app.log":     window,
+				"old/app.log": window,
+				"notes.txt":   "not a log\n",
+			},
+			wantTrimmed: 2,
+		},
+		{
+			name:   "window covering the files",
+			filter: &models.TimeRange{Start: trimTestStart.Add(-time.Hour), End: trimTestStart.Add(2 * time.Hour)},
+			want: map[string]string{
+				"app.log":        content,
+				"old/app.log.gz": string(gzipped.Bytes()),
+				"notes.txt":      "not a log\n",
+			},
+		},
+		{
+			name:   "window between two lines",
+			filter: &models.TimeRange{Start: trimTestStart.Add(90 * time.Second), End: trimTestStart.Add(100 * time.Second)},
+			want: map[string]string{
+				"notes.txt": "not a log\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := models.NewWorkingSet(bundle)
+			ws.SelectAllFiles()
+			ws.SetTimeFilter(tt.filter)
+
+			destFs := afero.NewMemMapFs()
+			service := NewServiceWithFilesystems(afero.NewOsFs(), destFs)
+
+			summary, err := service.GetExportSummary(ws, "/export")
+			if err != nil {
+				t.Fatalf("GetExportSummary() error = %v", err)
+			}
+			var wantSize int64
+			for _, data := range tt.want {
+				wantSize += int64(len(data))
+			}
+			if summary.FileCount != len(tt.want) || summary.TotalSize != wantSize || summary.TrimmedFileCount != tt.wantTrimmed {
+				t.Errorf("summary = %d files (%d trimmed) of %d bytes, want %d files (%d trimmed) of %d bytes",
+					summary.FileCount, summary.TrimmedFileCount, summary.TotalSize, len(tt.want), tt.wantTrimmed, wantSize)
+			}
+
+			if err := service.ExportWorkingSet(ws, ExportOptions{DestinationPath: "/export", PreserveStructure: true}); err != nil {
+				t.Fatalf("ExportWorkingSet() error = %v", err)
+			}
+
+			exported := make(map[string]string)
+			afero.Walk(destFs, "/export", func(path string, info os.FileInfo, err error) error {
+				if err == nil && !info.IsDir() {
+					data, _ := afero.ReadFile(destFs, path)
+					relative, _ := filepath.Rel("/export", path)
+					exported[relative] = string(data)
+				}
+				return err
+			})
+			if len(exported) != len(tt.want) {
+				t.Errorf("exported %d files, want %d", len(exported), len(tt.want))
+			}
+			for name, want := range tt.want {
+				if got, ok := exported[name]; !ok {
+					t.Errorf("%s was not exported", name)
+				} else if got != want {
+					t.Errorf("%s exported as:\n%s\nwant:\n%s", name, got, want)
+				}
+			}
+		})
+	}
+}
@@ -8,6 +8,12 @@ type RegexFiltersChangedMsg struct {
 	SourceComponent string               // Which component sent this
 }
 
+// TimeFilterChangedMsg is sent when the time window filter is set or cleared
+type TimeFilterChangedMsg struct {
+	TimeRange       *models.TimeRange // New time window, nil clears the filter
+	SourceComponent string            // Which component sent this
+}
+
 // WorkingSetUpdatedMsg is sent when the working set has been recalculated
 type WorkingSetUpdatedMsg struct {
 	SelectedCount int      // Number of currently selected files
@@ -2,6 +2,8 @@ package models
 
 import (
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/spf13/afero"
@@ -20,22 +22,52 @@ type Bundle struct {
 
 // FileInfo contains information about a single file in the bundle
 type FileInfo struct {
-	Path         string     `json:"path"`          // Relative path from bundle root
-	Size         int64      `json:"size"`          // File size in bytes
-	IsLogFile    bool       `json:"is_log_file"`   // Detected as log file
-	TimeRange    *TimeRange `json:"time_range"`    // Time span (nil if not parsed)
-	Selected     bool       `json:"selected"`      // User selection state
-	LastModified time.Time  `json:"last_modified"` // File modification time
+	Path             string      `json:"path"`                   // Relative path from bundle root
+	Size             int64       `json:"size"`                   // File size on disk in bytes
+	UncompressedSize int64       `json:"uncompressed_size"`      // Decompressed size (equals Size for plain files, 0 if not measured)
+	Compression      string      `json:"compression"`            // Compression format ("" if uncompressed)
+	Kind             FileKind    `json:"kind"`                   // Content sniffed from the file's head
+	Encoding         string      `json:"encoding,omitempty"`     // Text encoding from a byte order mark ("utf-8", "utf-16le", "utf-16be"), "" if none
+	IsLogFile        bool        `json:"is_log_file"`            // Detected as log file
+	TimeRange        *TimeRange  `json:"time_range"`             // Time span (nil if not parsed)
+	TimestampPattern string      `json:"timestamp_pattern"`      // Name of the detected timestamp pattern
+	FormatConfidence float64     `json:"format_confidence"`      // Share of sampled lines the pattern matched, 0 to 1
+	EstimatedLines   int64       `json:"estimated_lines"`        // Line count (0 if not counted)
+	EntryCount       int64       `json:"entry_count"`            // Log entries: timestamped lines with their continuation lines
+	LevelCounts      LevelCounts `json:"level_counts,omitempty"` // Entries per log level, log files only
+	Selected         bool        `json:"selected"`               // User selection state
+	LastModified     time.Time   `json:"last_modified"`          // File modification time
 }
 
 // BundleMetadata contains aggregate information about the bundle
 type BundleMetadata struct {
-	LogFileCount   int       `json:"log_file_count"`   // Number of log files
-	TotalFileCount int       `json:"total_file_count"` // Total number of files
-	OldestLog      time.Time `json:"oldest_log"`       // Earliest log timestamp
-	NewestLog      time.Time `json:"newest_log"`       // Latest log timestamp
-	CommonFormats  []string  `json:"common_formats"`   // Detected log formats
-	ScanDepth      int       `json:"scan_depth"`       // Directory depth scanned
+	LogFileCount   int           `json:"log_file_count"`   // Number of log files
+	TotalFileCount int           `json:"total_file_count"` // Total number of files
+	OldestLog      time.Time     `json:"oldest_log"`       // Earliest log timestamp
+	NewestLog      time.Time     `json:"newest_log"`       // Latest log timestamp
+	CommonFormats  []FormatStats `json:"common_formats"`   // Detected log formats, most files first
+	Undetected     int           `json:"undetected"`       // Files without a detected format
+	ScanDepth      int           `json:"scan_depth"`       // Directory depth scanned
+}
+
+// UndetectedFormat is the format of files no timestamp pattern was detected in
+const UndetectedFormat = "undetected"
+
+// FileKind is what a file holds, as sniffed from its first bytes
+type FileKind string
+
+const (
+	KindText       FileKind = "text"       // Readable text; only text is searched for timestamps
+	KindBinary     FileKind = "binary"     // Cores, databases and other binary data
+	KindCompressed FileKind = "compressed" // Compressed; its decompressed content may be text or binary
+)
+
+// FormatStats aggregates the files detected with one log format
+type FormatStats struct {
+	Format     string  `json:"format"`     // Timestamp pattern, see FileInfo.Format
+	FileCount  int     `json:"file_count"` // Files detected with the format
+	Size       int64   `json:"size"`       // Their total size on disk
+	Confidence float64 `json:"confidence"` // Their mean detection confidence
 }
 
 // NewBundle creates a new Bundle with the given filesystem
@@ -48,7 +80,7 @@ func NewBundle(path string, fs afero.Fs) *Bundle {
 		Metadata: BundleMetadata{
 			LogFileCount:   0,
 			TotalFileCount: 0,
-			CommonFormats:  make([]string, 0),
+			CommonFormats:  make([]FormatStats, 0),
 			ScanDepth:      0,
 		},
 		ScanTime: time.Now(),
@@ -92,6 +124,84 @@ func (b *Bundle) AddFile(file FileInfo) {
 	}
 }
 
+// UpdateFormatStats tallies the detected formats of the bundle's files into its metadata
+func (b *Bundle) UpdateFormatStats() {
+	byFormat := make(map[string]*FormatStats)
+	b.Metadata.CommonFormats = make([]FormatStats, 0)
+	b.Metadata.Undetected = 0
+
+	for _, file := range b.Files {
+		format := file.Format()
+		if format == UndetectedFormat {
+			b.Metadata.Undetected++
+			continue
+		}
+
+		stats, ok := byFormat[format]
+		if !ok {
+			stats = &FormatStats{Format: format}
+			byFormat[format] = stats
+		}
+		stats.FileCount++
+		stats.Size += file.Size
+		stats.Confidence += file.FormatConfidence // Summed here, averaged below
+	}
+
+	for _, stats := range byFormat {
+		stats.Confidence /= float64(stats.FileCount)
+		b.Metadata.CommonFormats = append(b.Metadata.CommonFormats, *stats)
+	}
+	sort.Slice(b.Metadata.CommonFormats, func(i, j int) bool {
+		a, c := b.Metadata.CommonFormats[i], b.Metadata.CommonFormats[j]
+		if a.FileCount != c.FileCount {
+			return a.FileCount > c.FileCount
+		}
+		return a.Format < c.Format
+	})
+}
+
+// Format returns the log format the file was detected with: the name of its timestamp
+// pattern, without the "_Anchored" suffix telling whether lines start with the timestamp.
+// It is UndetectedFormat for files that aren't log files.
+func (f *FileInfo) Format() string {
+	if !f.IsLogFile || f.TimestampPattern == "" {
+		return UndetectedFormat
+	}
+	return strings.TrimSuffix(f.TimestampPattern, "_Anchored")
+}
+
+// IsCompressed returns true if the file is stored compressed on disk
+func (f *FileInfo) IsCompressed() bool {
+	return f.Compression != ""
+}
+
+// KindLabel is a short description of the file's kind for listings: its compression format
+// for compressed files, and the encoding of UTF-16 text
+func (f *FileInfo) KindLabel() string {
+	switch {
+	case f.Kind == KindCompressed && f.Compression != "":
+		return f.Compression
+	case f.Kind == KindText && strings.HasPrefix(f.Encoding, "utf-16"):
+		return f.Encoding
+	case f.Kind == "":
+		return "-"
+	}
+	return string(f.Kind)
+}
+
+// KindDescription is the file's kind followed by its compression format and text encoding,
+// if any, e.g. "compressed gzip utf-16le"; kind filters are matched against it
+func (f *FileInfo) KindDescription() string {
+	parts := []string{string(f.Kind)}
+	if f.Compression != "" {
+		parts = append(parts, f.Compression)
+	}
+	if f.Encoding != "" {
+		parts = append(parts, f.Encoding)
+	}
+	return strings.Join(parts, " ")
+}
+
 // GetSelectedFiles returns a slice of selected file paths
 func (b *Bundle) GetSelectedFiles() []string {
 	var selected []string
@@ -0,0 +1,37 @@
+package models
+
+// LogLevel is a normalised log severity
+type LogLevel string
+
+const (
+	LevelFatal   LogLevel = "FATAL" // Also critical, panic, emergency and alert
+	LevelError   LogLevel = "ERROR"
+	LevelWarn    LogLevel = "WARN"
+	LevelInfo    LogLevel = "INFO" // Also notice
+	LevelDebug   LogLevel = "DEBUG"
+	LevelTrace   LogLevel = "TRACE"
+	LevelUnknown LogLevel = "UNKNOWN" // No level could be detected
+)
+
+// LogLevels lists every level from most to least severe
+var LogLevels = []LogLevel{LevelFatal, LevelError, LevelWarn, LevelInfo, LevelDebug, LevelTrace, LevelUnknown}
+
+// LevelCounts holds the number of log entries per log level. An entry (a timestamped line
+// with its continuation lines, e.g. a stack trace) counts towards the level of its first line.
+type LevelCounts map[LogLevel]int64
+
+// Add accumulates other into the counts
+func (lc LevelCounts) Add(other LevelCounts) {
+	for level, count := range other {
+		lc[level] += count
+	}
+}
+
+// Total returns the number of entries across all levels
+func (lc LevelCounts) Total() int64 {
+	var total int64
+	for _, count := range lc {
+		total += count
+	}
+	return total
+}
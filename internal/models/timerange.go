@@ -57,6 +57,14 @@ func (tr *TimeRange) Intersection(other *TimeRange) *TimeRange {
 	return result
 }
 
+// In returns the same range with its times expressed in loc
+func (tr *TimeRange) In(loc *time.Location) *TimeRange {
+	if tr == nil {
+		return nil
+	}
+	return &TimeRange{Start: tr.Start.In(loc), End: tr.End.In(loc)}
+}
+
 // String returns a human-readable representation of the time range
 func (tr *TimeRange) String() string {
 	return fmt.Sprintf("%s - %s", tr.Start.Format(time.RFC3339), tr.End.Format(time.RFC3339))
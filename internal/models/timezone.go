@@ -0,0 +1,34 @@
+package models
+
+import (
+	"sync"
+	"time"
+)
+
+// displayLocation is the zone timestamps are normalised to for display; UTC unless configured
+var (
+	displayMu       sync.RWMutex
+	displayLocation = time.UTC
+)
+
+// SetDisplayLocation sets the zone times are shown in
+func SetDisplayLocation(loc *time.Location) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	displayMu.Lock()
+	displayLocation = loc
+	displayMu.Unlock()
+}
+
+// DisplayLocation returns the zone times are shown in
+func DisplayLocation() *time.Location {
+	displayMu.RLock()
+	defer displayMu.RUnlock()
+	return displayLocation
+}
+
+// InDisplayZone returns t in the display zone
+func InDisplayZone(t time.Time) time.Time {
+	return t.In(DisplayLocation())
+}
@@ -5,13 +5,77 @@ import (
 	"time"
 )
 
+// FilterTarget is what a regex filter is matched against
+type FilterTarget string
+
+const (
+	FilterTargetPath      FilterTarget = "path"       // File paths; selects files
+	FilterTargetContent   FilterTarget = "content"    // Log lines; trims exported files
+	FilterTargetFileMatch FilterTarget = "file_match" // File contents; selects whole files containing a match
+	FilterTargetFormat    FilterTarget = "format"     // Detected log formats; selects files of matching formats
+	FilterTargetKind      FilterTarget = "kind"       // Sniffed content kinds; selects files of matching kinds
+)
+
 // RegexFilter represents a single regex filter with take/exclude logic
 type RegexFilter struct {
-	Pattern  string         `json:"pattern"` // The regex pattern text
-	Take     bool           `json:"take"`    // true = include, false = exclude
-	Compiled *regexp.Regexp `json:"-"`       // Compiled regex (not serialized)
-	Valid    bool           `json:"valid"`   // Whether the pattern is valid
-	Error    string         `json:"error"`   // Error message if invalid
+	Pattern  string         `json:"pattern"`          // The regex pattern text
+	Take     bool           `json:"take"`             // true = include, false = exclude
+	Target   FilterTarget   `json:"target,omitempty"` // What the pattern matches; empty means path
+	Key      string         `json:"key,omitempty"`    // For content filters, the JSON or logfmt field matched instead of the whole line
+	Compiled *regexp.Regexp `json:"-"`                // Compiled regex (not serialized)
+	Valid    bool           `json:"valid"`            // Whether the pattern is valid
+	Error    string         `json:"error"`            // Error message if invalid
+
+	// Matching lines per file (by path) of a file match filter; files without a match are
+	// absent, and the map is nil until the search has finished
+	Matches map[string]int64 `json:"-"`
+}
+
+// IsPathFilter returns true if the filter matches file paths
+func (rf *RegexFilter) IsPathFilter() bool {
+	return rf.Target == "" || rf.Target == FilterTargetPath
+}
+
+// IsContentFilter returns true if the filter matches log lines rather than file paths
+func (rf *RegexFilter) IsContentFilter() bool {
+	return rf.Target == FilterTargetContent
+}
+
+// IsFileMatchFilter returns true if the filter selects files by whether their contents match
+func (rf *RegexFilter) IsFileMatchFilter() bool {
+	return rf.Target == FilterTargetFileMatch
+}
+
+// IsFormatFilter returns true if the filter selects files by their detected log format
+func (rf *RegexFilter) IsFormatFilter() bool {
+	return rf.Target == FilterTargetFormat
+}
+
+// IsKindFilter returns true if the filter selects files by their sniffed content kind
+func (rf *RegexFilter) IsKindFilter() bool {
+	return rf.Target == FilterTargetKind
+}
+
+// MatchesFile returns true if the filter matches the file: its path for path filters, its
+// contents for file match filters (false while they are still being searched), its detected
+// format for format filters and its kind description for kind filters. Content filters never
+// match files.
+func (rf *RegexFilter) MatchesFile(file *FileInfo) bool {
+	if !rf.Valid || rf.Compiled == nil {
+		return false
+	}
+	switch {
+	case rf.IsPathFilter():
+		return rf.Compiled.MatchString(file.Path)
+	case rf.IsFileMatchFilter():
+		return rf.Matches[file.Path] > 0
+	case rf.IsFormatFilter():
+		return rf.Compiled.MatchString(file.Format())
+	case rf.IsKindFilter():
+		return rf.Compiled.MatchString(file.KindDescription())
+	default:
+		return false
+	}
 }
 
 // WorkingSet represents the current working state with all user selections and filters
@@ -27,11 +91,12 @@ type WorkingSet struct {
 
 // VolumePoint represents a time-binned data point for the histogram
 type VolumePoint struct {
-	BinStart  time.Time `json:"bin_start"`  // Bin start time
-	BinEnd    time.Time `json:"bin_end"`    // Bin end time
-	Count     int64     `json:"count"`      // Log entries in bin
-	Size      int64     `json:"size"`       // Bytes in bin
-	FileCount int       `json:"file_count"` // Files contributing to bin
+	BinStart  time.Time   `json:"bin_start"`        // Bin start time
+	BinEnd    time.Time   `json:"bin_end"`          // Bin end time
+	Count     int64       `json:"count"`            // Log entries starting in bin
+	Size      int64       `json:"size"`             // Bytes in bin
+	FileCount int         `json:"file_count"`       // Files contributing to bin
+	Levels    LevelCounts `json:"levels,omitempty"` // Entries per log level; sums to Count
 }
 
 // NewWorkingSet creates a new WorkingSet from a bundle
@@ -54,6 +119,22 @@ func NewWorkingSet(bundle *Bundle) *WorkingSet {
 	}
 }
 
+// Snapshot returns a copy of the working set with its own selection map, safe to read from
+// a background goroutine while the original keeps changing. The bundle is shared.
+func (ws *WorkingSet) Snapshot() *WorkingSet {
+	snapshot := *ws
+
+	snapshot.SelectedFiles = make(map[string]bool, len(ws.SelectedFiles))
+	for path, selected := range ws.SelectedFiles {
+		snapshot.SelectedFiles[path] = selected
+	}
+
+	snapshot.RegexFilters = make([]RegexFilter, len(ws.RegexFilters))
+	copy(snapshot.RegexFilters, ws.RegexFilters)
+
+	return &snapshot
+}
+
 // AddRegexFilter adds a new regex filter at the end of the list
 func (ws *WorkingSet) AddRegexFilter(pattern string, take bool) error {
 	compiled, err := regexp.Compile(pattern)
@@ -110,6 +191,34 @@ func (ws *WorkingSet) SetRegexFilters(filters []RegexFilter) {
 	ws.LastUpdated = time.Now()
 }
 
+// SetFileMatches records the search results of every file match filter with the given pattern
+func (ws *WorkingSet) SetFileMatches(pattern string, matches map[string]int64) {
+	for i := range ws.RegexFilters {
+		if ws.RegexFilters[i].IsFileMatchFilter() && ws.RegexFilters[i].Pattern == pattern {
+			ws.RegexFilters[i].Matches = matches
+		}
+	}
+	ws.LastUpdated = time.Now()
+}
+
+// FileMatchCounts sums, per file, the matching lines found by the include file match
+// filters. It returns nil if there are no such filters with results.
+func (ws *WorkingSet) FileMatchCounts() map[string]int64 {
+	var counts map[string]int64
+	for _, filter := range ws.RegexFilters {
+		if !filter.IsFileMatchFilter() || !filter.Take || filter.Matches == nil {
+			continue
+		}
+		if counts == nil {
+			counts = make(map[string]int64)
+		}
+		for path, count := range filter.Matches {
+			counts[path] += count
+		}
+	}
+	return counts
+}
+
 // SetTimeFilter sets the time range filter
 func (ws *WorkingSet) SetTimeFilter(timeRange *TimeRange) {
 	ws.TimeFilter = timeRange
@@ -196,6 +305,26 @@ func (ws *WorkingSet) HasTimeFilter() bool {
 	return ws.TimeFilter != nil && !ws.TimeFilter.IsZero()
 }
 
+// HasLineFilters returns true if any valid content filter applies to exported lines
+func (ws *WorkingSet) HasLineFilters() bool {
+	for _, filter := range ws.RegexFilters {
+		if filter.IsContentFilter() && filter.Valid && filter.Compiled != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// PassesTimeFilter returns true if the file's time range overlaps the time filter (bounds inclusive).
+// Files without a known time range cannot be judged and always pass, as does every file when
+// no time filter is set.
+func (ws *WorkingSet) PassesTimeFilter(file *FileInfo) bool {
+	if !ws.HasTimeFilter() || file.TimeRange == nil {
+		return true
+	}
+	return !file.TimeRange.End.Before(ws.TimeFilter.Start) && !file.TimeRange.Start.After(ws.TimeFilter.End)
+}
+
 // GetEffectiveTimeRange returns the effective time range considering filters
 func (ws *WorkingSet) GetEffectiveTimeRange() *TimeRange {
 	if ws.HasTimeFilter() {
@@ -251,3 +380,31 @@ func (ws *WorkingSet) GetSelectedTotalSize() int64 {
 	}
 	return totalSize
 }
+
+// GetSelectedWindowSize estimates how many bytes of the selected files fall inside the time
+// filter, assuming each file's bytes are spread evenly over its time range. Files without a
+// time range count in full. Equals GetSelectedTotalSize when no time filter is set.
+func (ws *WorkingSet) GetSelectedWindowSize() int64 {
+	if ws.Bundle == nil {
+		return 0
+	}
+
+	var totalSize int64
+	for _, file := range ws.Bundle.Files {
+		if !ws.IsFileSelected(file.Path) || !ws.PassesTimeFilter(&file) {
+			continue
+		}
+		if !ws.HasTimeFilter() || file.TimeRange == nil || file.TimeRange.Duration() <= 0 {
+			totalSize += file.Size
+			continue
+		}
+
+		overlap := file.TimeRange.Intersection(ws.TimeFilter)
+		if overlap == nil {
+			continue
+		}
+		fraction := float64(overlap.Duration()) / float64(file.TimeRange.Duration())
+		totalSize += int64(fraction * float64(file.Size))
+	}
+	return totalSize
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPassesTimeFilter(t *testing.T) {
+	hour := func(h int) time.Time { return time.Date(2024, 1, 2, h, 0, 0, 0, time.UTC) }
+	filter := &TimeRange{Start: hour(10), End: hour(12)}
+
+	tests := []struct {
+		name   string
+		filter *TimeRange
+		file   *TimeRange
+		want   bool
+	}{
+		{"no filter", nil, &TimeRange{Start: hour(1), End: hour(2)}, true},
+		{"zero filter", &TimeRange{}, &TimeRange{Start: hour(1), End: hour(2)}, true},
+		{"unknown file range", filter, nil, true},
+		{"inside", filter, &TimeRange{Start: hour(10), End: hour(11)}, true},
+		{"spanning", filter, &TimeRange{Start: hour(8), End: hour(14)}, true},
+		{"overlapping the start", filter, &TimeRange{Start: hour(9), End: hour(10)}, true},
+		{"overlapping the end", filter, &TimeRange{Start: hour(12), End: hour(13)}, true},
+		{"before", filter, &TimeRange{Start: hour(8), End: hour(9)}, false},
+		{"after", filter, &TimeRange{Start: hour(13), End: hour(14)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := &WorkingSet{TimeFilter: tt.filter}
+			if got := ws.PassesTimeFilter(&FileInfo{Path: "app.log", TimeRange: tt.file}); got != tt.want {
+				t.Errorf("PassesTimeFilter(%v) with filter %v = %v, want %v", tt.file, tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetEffectiveTimeRange(t *testing.T) {
+	bundleRange := &TimeRange{Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)}
+	filter := &TimeRange{Start: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC), End: time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		name   string
+		bundle *Bundle
+		filter *TimeRange
+		want   *TimeRange
+	}{
+		{"filter wins", &Bundle{TimeRange: bundleRange}, filter, filter},
+		{"bundle range without a filter", &Bundle{TimeRange: bundleRange}, nil, bundleRange},
+		{"zero filter is ignored", &Bundle{TimeRange: bundleRange}, &TimeRange{}, bundleRange},
+		{"nothing known", &Bundle{}, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := &WorkingSet{Bundle: tt.bundle}
+			ws.SetTimeFilter(tt.filter)
+			if got := ws.GetEffectiveTimeRange(); got != tt.want {
+				t.Errorf("GetEffectiveTimeRange() = %v, want %v", got, tt.want)
+			}
+
+			ws.ClearTimeFilter()
+			if ws.HasTimeFilter() {
+				t.Error("HasTimeFilter() = true after ClearTimeFilter()")
+			}
+		})
+	}
+}
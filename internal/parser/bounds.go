@@ -2,6 +2,7 @@ package parser
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
 	"strings"
@@ -16,6 +17,9 @@ const (
 	// MaxLinesToCheckForTimestamp is the maximum number of lines we check when extracting bounds
 	// This is higher than PeakLines since we really need to find valid timestamps for bounds
 	MaxLinesToCheckForTimestamp = 100
+
+	// tailReadSize is how many trailing bytes are examined when looking for the latest timestamp
+	tailReadSize = 64 * 1024
 )
 
 // TimeBounds represents the earliest and latest timestamps found in a file
@@ -45,13 +49,42 @@ func NewBoundsExtractor(fs afero.Fs) *BoundsExtractor {
 
 // ExtractBounds finds the earliest and latest timestamps in a file using efficient linear search
 func (be *BoundsExtractor) ExtractBounds(filePath string) (*TimeBounds, error) {
+	return be.ExtractBoundsContext(context.Background(), filePath)
+}
+
+// ExtractBoundsContext is ExtractBounds that gives up with ctx.Err() once ctx is cancelled,
+// including while a compressed file is decompressed to reach its tail
+func (be *BoundsExtractor) ExtractBoundsContext(ctx context.Context, filePath string) (*TimeBounds, error) {
+	return be.extractBounds(ctx, filePath, func(filePath string) ([]byte, error) {
+		return be.readTail(ctx, filePath)
+	})
+}
+
+// ExtractBoundsWithTail is ExtractBounds for a file whose last bytes were already collected
+// with a TailWriter while streaming it, so a compressed file isn't decompressed again
+func (be *BoundsExtractor) ExtractBoundsWithTail(filePath string, tail []byte) (*TimeBounds, error) {
+	return be.extractBounds(context.Background(), filePath, func(string) ([]byte, error) {
+		return tail, nil
+	})
+}
+
+// extractBounds finds the bounds of a file, reading its last bytes with readTail
+func (be *BoundsExtractor) extractBounds(ctx context.Context, filePath string, readTail func(filePath string) ([]byte, error)) (*TimeBounds, error) {
 	bounds := &TimeBounds{
 		FilePath: filePath,
 		Valid:    false,
 	}
+	if err := ctx.Err(); err != nil {
+		return bounds, err
+	}
+
+	// Timestamps without an offset are read in the zone configured for this file. The first
+	// and last timestamps are read in order, so the years of a file wrapping past December
+	// follow on from each other.
+	extractor := be.timestampExtractor.ForFile(filePath).InOrder()
 
 	// First, detect the best timestamp pattern for this file
-	patternResult, err := be.timestampExtractor.DetectBestPattern(filePath)
+	patternResult, err := extractor.DetectBestPattern(filePath)
 	if err != nil {
 		return bounds, fmt.Errorf("failed to detect timestamp pattern for %s: %w", filePath, err)
 	}
@@ -64,13 +97,17 @@ func (be *BoundsExtractor) ExtractBounds(filePath string) (*TimeBounds, error) {
 	bounds.BestPattern = patternResult.BestPattern
 
 	// Find earliest timestamp (linear search from top)
-	earliest, earliestLine, err := be.findEarliestTimestamp(filePath, bounds.BestPattern)
+	earliest, earliestLine, err := be.findEarliestTimestamp(extractor, filePath, bounds.BestPattern)
 	if err != nil {
 		return bounds, fmt.Errorf("failed to find earliest timestamp in %s: %w", filePath, err)
 	}
 
 	// Find latest timestamp (linear search from bottom)
-	latest, latestLine, err := be.findLatestTimestamp(filePath, bounds.BestPattern)
+	tail, err := readTail(filePath)
+	if err != nil {
+		return bounds, fmt.Errorf("failed to find latest timestamp in %s: %w", filePath, err)
+	}
+	latest, latestLine, err := be.findLatestTimestampInBuffer(extractor, tail, bounds.BestPattern)
 	if err != nil {
 		return bounds, fmt.Errorf("failed to find latest timestamp in %s: %w", filePath, err)
 	}
@@ -85,21 +122,35 @@ func (be *BoundsExtractor) ExtractBounds(filePath string) (*TimeBounds, error) {
 }
 
 // findEarliestTimestamp performs linear search from top of file to find first valid timestamp
-func (be *BoundsExtractor) findEarliestTimestamp(filePath string, bestPattern *TimestampPattern) (time.Time, string, error) {
-	file, err := be.fs.Open(filePath)
+func (be *BoundsExtractor) findEarliestTimestamp(extractor *TimestampExtractor, filePath string, bestPattern *TimestampPattern) (time.Time, string, error) {
+	file, _, err := OpenDecompressed(be.fs, filePath)
 	if err != nil {
 		return time.Time{}, "", fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
-	return be.timestampExtractor.FindLineWithTimestamp(file, bestPattern, MaxLinesToCheckForTimestamp)
+	return extractor.FindLineWithTimestamp(file, bestPattern, MaxLinesToCheckForTimestamp)
 }
 
-// findLatestTimestamp performs linear search from bottom of file to find last valid timestamp
-func (be *BoundsExtractor) findLatestTimestamp(filePath string, bestPattern *TimestampPattern) (time.Time, string, error) {
+// readTail reads the last tailReadSize bytes of a file's content, where the latest timestamp
+// is searched for from the bottom up
+func (be *BoundsExtractor) readTail(ctx context.Context, filePath string) ([]byte, error) {
+	seekable, err := IsSeekableText(be.fs, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if seekable {
+		return be.readPlainTail(filePath)
+	}
+	return be.readStreamedTail(ctx, filePath)
+}
+
+// readPlainTail seeks to the end of an uncompressed file and reads the last tailReadSize bytes
+func (be *BoundsExtractor) readPlainTail(filePath string) ([]byte, error) {
 	file, err := be.fs.Open(filePath)
 	if err != nil {
-		return time.Time{}, "", fmt.Errorf("failed to open file: %w", err)
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
@@ -107,13 +158,13 @@ func (be *BoundsExtractor) findLatestTimestamp(filePath string, bestPattern *Tim
 	// For large files, we'll read the last few KB and scan backwards
 	stat, err := file.Stat()
 	if err != nil {
-		return time.Time{}, "", fmt.Errorf("failed to stat file: %w", err)
+		return nil, fmt.Errorf("failed to stat file: %w", err)
 	}
 
 	fileSize := stat.Size()
 
 	// Read last 64KB or entire file if smaller
-	readSize := int64(64 * 1024) // 64KB
+	readSize := int64(tailReadSize)
 	if fileSize < readSize {
 		readSize = fileSize
 	}
@@ -126,26 +177,88 @@ func (be *BoundsExtractor) findLatestTimestamp(filePath string, bestPattern *Tim
 
 	_, err = file.Seek(seekPos, io.SeekStart)
 	if err != nil {
-		return time.Time{}, "", fmt.Errorf("failed to seek in file: %w", err)
+		return nil, fmt.Errorf("failed to seek in file: %w", err)
 	}
 
 	// Read the tail into memory and scan backwards
 	buffer := make([]byte, readSize)
-	n, err := file.Read(buffer)
-	if err != nil && err != io.EOF {
-		return time.Time{}, "", fmt.Errorf("failed to read file tail: %w", err)
+	n, err := io.ReadFull(file, buffer)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("failed to read file tail: %w", err)
 	}
 
-	return be.findLatestTimestampInBuffer(buffer[:n], bestPattern)
+	return buffer[:n], nil
+}
+
+// readStreamedTail streams a compressed or UTF-16 file to the end, keeping only the last
+// tailReadSize bytes. Decoded streams cannot seek, so the whole file has to be read once.
+func (be *BoundsExtractor) readStreamedTail(ctx context.Context, filePath string) ([]byte, error) {
+	reader, _, err := OpenDecompressed(be.fs, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer reader.Close()
+
+	var tail TailWriter
+	if _, err := io.Copy(&tail, &contextReader{ctx: ctx, reader: reader}); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("failed to decompress file tail: %w", err)
+	}
+
+	return tail.Bytes(), nil
+}
+
+// contextReader fails reads with ctx.Err() once ctx is cancelled
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (cr *contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cr.reader.Read(p)
+}
+
+// TailWriter keeps the last bytes written to it, as many as the latest timestamp of a file
+// is searched for in. Used as the target of an io.TeeReader, it collects the tail of a
+// stream that is read for another purpose.
+type TailWriter struct {
+	tail []byte
+}
+
+// Write appends p, dropping what no longer falls within the tail
+func (tw *TailWriter) Write(p []byte) (int, error) {
+	if len(p) >= tailReadSize {
+		tw.tail = append(tw.tail[:0], p[len(p)-tailReadSize:]...)
+		return len(p), nil
+	}
+
+	tw.tail = append(tw.tail, p...)
+	if len(tw.tail) > 2*tailReadSize {
+		tw.tail = append(tw.tail[:0], tw.tail[len(tw.tail)-tailReadSize:]...)
+	}
+	return len(p), nil
+}
+
+// Bytes returns the last bytes written, at most the tail size
+func (tw *TailWriter) Bytes() []byte {
+	if len(tw.tail) > tailReadSize {
+		return tw.tail[len(tw.tail)-tailReadSize:]
+	}
+	return tw.tail
 }
 
 // findLatestTimestampInBuffer scans a buffer backwards to find the latest timestamp
-func (be *BoundsExtractor) findLatestTimestampInBuffer(buffer []byte, bestPattern *TimestampPattern) (time.Time, string, error) {
+func (be *BoundsExtractor) findLatestTimestampInBuffer(extractor *TimestampExtractor, buffer []byte, bestPattern *TimestampPattern) (time.Time, string, error) {
 	// Split buffer into lines and scan from bottom up
 	lines := splitLinesReverse(buffer)
 
 	for _, line := range lines {
-		if timestamp, err := be.timestampExtractor.ParseTimestamp(line, bestPattern); err == nil {
+		if timestamp, err := extractor.ParseEntryTimestamp(line, bestPattern); err == nil {
 			return timestamp, line, nil
 		}
 	}
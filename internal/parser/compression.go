@@ -0,0 +1,241 @@
+package parser
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/spf13/afero"
+	"github.com/ulikunitz/xz"
+)
+
+// CompressionFormat identifies the compression applied to a file
+type CompressionFormat string
+
+const (
+	CompressionNone  CompressionFormat = ""
+	CompressionGzip  CompressionFormat = "gzip"
+	CompressionBzip2 CompressionFormat = "bzip2"
+	CompressionXz    CompressionFormat = "xz"
+	CompressionZstd  CompressionFormat = "zstd"
+)
+
+// compressionMagic maps each supported format to its leading magic bytes
+var compressionMagic = []struct {
+	format CompressionFormat
+	magic  []byte
+}{
+	{CompressionGzip, []byte{0x1f, 0x8b}},
+	{CompressionBzip2, []byte("BZh")},
+	{CompressionXz, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}},
+	{CompressionZstd, []byte{0x28, 0xb5, 0x2f, 0xfd}},
+}
+
+// compressionExtensions lists the file suffixes conventionally used for each format
+var compressionExtensions = map[CompressionFormat][]string{
+	CompressionGzip:  {".gz"},
+	CompressionBzip2: {".bz2"},
+	CompressionXz:    {".xz"},
+	CompressionZstd:  {".zst", ".zstd"},
+}
+
+// TrimCompressionExtension removes the conventional suffix of format from name, if present
+func TrimCompressionExtension(name string, format CompressionFormat) string {
+	for _, ext := range compressionExtensions[format] {
+		if strings.HasSuffix(strings.ToLower(name), ext) {
+			return name[:len(name)-len(ext)]
+		}
+	}
+	return name
+}
+
+// maxMagicLength is the number of header bytes needed to identify any supported format
+const maxMagicLength = 6
+
+// CompressionFromHeader identifies the compression format from the first bytes of a file
+func CompressionFromHeader(header []byte) CompressionFormat {
+	for _, entry := range compressionMagic {
+		if bytes.HasPrefix(header, entry.magic) {
+			return entry.format
+		}
+	}
+	return CompressionNone
+}
+
+// DetectCompression sniffs the magic bytes of a file to determine its compression format.
+// Rotated logs are not always named consistently, so content is used rather than extension.
+func DetectCompression(fs afero.Fs, filePath string) (CompressionFormat, error) {
+	header, err := readHeader(fs, filePath, maxMagicLength)
+	if err != nil {
+		return CompressionNone, err
+	}
+	return CompressionFromHeader(header), nil
+}
+
+// readHeader reads up to n leading bytes of a file as stored on disk
+func readHeader(fs afero.Fs, filePath string, n int) ([]byte, error) {
+	file, err := fs.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	header := make([]byte, n)
+	read, err := io.ReadFull(file, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("failed to read header of %s: %w", filePath, err)
+	}
+	return header[:read], nil
+}
+
+// IsSeekableText reports whether byte offsets in a file as stored on disk are offsets in the
+// text OpenDecompressed reads: false for compressed files and for UTF-16 files, which are
+// transcoded to UTF-8
+func IsSeekableText(fs afero.Fs, filePath string) (bool, error) {
+	header, err := readHeader(fs, filePath, EncodingSniffSize)
+	if err != nil {
+		return false, err
+	}
+	return checkSeekable(header) == nil, nil
+}
+
+// checkSeekable returns an error if byte offsets in a file starting with header aren't
+// offsets in its text, so it has to be read as a stream rather than searched by offset
+func checkSeekable(header []byte) error {
+	if format := CompressionFromHeader(header); format != CompressionNone {
+		return fmt.Errorf("cannot binary search %s-compressed file", format)
+	}
+	if len(header) > EncodingSniffSize {
+		header = header[:EncodingSniffSize]
+	}
+	if encoding := EncodingFromHeader(header); encoding.IsUTF16() {
+		return fmt.Errorf("cannot binary search %s file", encoding)
+	}
+	return nil
+}
+
+// decompressedReader couples a decompression stream with the resources it must release
+type decompressedReader struct {
+	io.Reader
+	closers []func() error
+}
+
+// Close releases the decompressor and the underlying file
+func (dr *decompressedReader) Close() error {
+	var firstErr error
+	for _, closeFn := range dr.closers {
+		if err := closeFn(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// OpenDecompressed opens a file and transparently decompresses it if it is compressed.
+// UTF-16 content is transcoded to UTF-8 and byte order marks are skipped. Other plain files are
+// returned as-is, still seekable, so callers can treat every log file uniformly.
+func OpenDecompressed(fs afero.Fs, filePath string) (io.ReadCloser, CompressionFormat, error) {
+	header, err := readHeader(fs, filePath, EncodingSniffSize)
+	if err != nil {
+		return nil, CompressionNone, err
+	}
+	format := CompressionFromHeader(header)
+
+	file, err := fs.Open(filePath)
+	if err != nil {
+		return nil, CompressionNone, fmt.Errorf("failed to open file %s: %w", filePath, err)
+	}
+
+	if format == CompressionNone {
+		switch encoding := EncodingFromHeader(header); {
+		case encoding.IsUTF16():
+			return &decompressedReader{Reader: newUTF16Reader(file, encoding), closers: []func() error{file.Close}}, format, nil
+		case encoding == EncodingUTF8:
+			// Offsets stay those of the file, so seeking still lands on the same bytes
+			if _, err := file.Seek(int64(len(utf8BOM)), io.SeekStart); err != nil {
+				file.Close()
+				return nil, format, fmt.Errorf("failed to skip byte order mark of %s: %w", filePath, err)
+			}
+		}
+		return file, format, nil
+	}
+
+	reader, err := newDecompressor(file, format)
+	if err != nil {
+		file.Close()
+		return nil, format, fmt.Errorf("failed to open %s stream for %s: %w", format, filePath, err)
+	}
+
+	// The encoding of compressed files is only known once decompressed
+	return &decompressedReader{Reader: newTextReader(reader), closers: []func() error{reader.Close}}, format, nil
+}
+
+// ReadHead returns up to n leading bytes of a file's content, decompressed but not transcoded,
+// along with its compression format
+func ReadHead(fs afero.Fs, filePath string, n int) ([]byte, CompressionFormat, error) {
+	format, err := DetectCompression(fs, filePath)
+	if err != nil {
+		return nil, CompressionNone, err
+	}
+
+	file, err := fs.Open(filePath)
+	if err != nil {
+		return nil, format, fmt.Errorf("failed to open file %s: %w", filePath, err)
+	}
+
+	reader, err := newDecompressor(file, format)
+	if err != nil {
+		file.Close()
+		return nil, format, fmt.Errorf("failed to open %s stream for %s: %w", format, filePath, err)
+	}
+	defer reader.Close()
+
+	head := make([]byte, n)
+	read, err := io.ReadFull(reader, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, format, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	return head[:read], format, nil
+}
+
+// newDecompressor wraps a file in the decompression stream for the given format
+func newDecompressor(file afero.File, format CompressionFormat) (io.ReadCloser, error) {
+	switch format {
+	case CompressionNone:
+		return file, nil
+
+	case CompressionGzip:
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, err
+		}
+		return &decompressedReader{Reader: gzipReader, closers: []func() error{gzipReader.Close, file.Close}}, nil
+
+	case CompressionBzip2:
+		return &decompressedReader{Reader: bzip2.NewReader(file), closers: []func() error{file.Close}}, nil
+
+	case CompressionXz:
+		xzReader, err := xz.NewReader(file)
+		if err != nil {
+			return nil, err
+		}
+		return &decompressedReader{Reader: xzReader, closers: []func() error{file.Close}}, nil
+
+	case CompressionZstd:
+		zstdReader, err := zstd.NewReader(file)
+		if err != nil {
+			return nil, err
+		}
+		closeDecoder := func() error {
+			zstdReader.Close()
+			return nil
+		}
+		return &decompressedReader{Reader: zstdReader, closers: []func() error{closeDecoder, file.Close}}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported compression format %q", format)
+}
@@ -0,0 +1,185 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// TextEncoding identifies the character encoding of a text file
+type TextEncoding string
+
+const (
+	EncodingUnknown TextEncoding = ""         // No byte order mark; read as UTF-8 (or any ASCII superset)
+	EncodingUTF8    TextEncoding = "utf-8"    // UTF-8 with a byte order mark
+	EncodingUTF16LE TextEncoding = "utf-16le" // Little-endian UTF-16, as written by Windows tools
+	EncodingUTF16BE TextEncoding = "utf-16be" // Big-endian UTF-16
+)
+
+// EncodingSniffSize is the number of leading bytes used to detect the encoding of a file
+const EncodingSniffSize = 4 * 1024
+
+// IsUTF16 returns true for the UTF-16 encodings, whose content is transcoded to UTF-8 when read
+func (e TextEncoding) IsUTF16() bool {
+	return e == EncodingUTF16LE || e == EncodingUTF16BE
+}
+
+// utf8BOM is the UTF-8 byte order mark, which some Windows tools write ahead of UTF-8 text
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+// byteOrderMarks maps the encodings with a byte order mark to the mark
+var byteOrderMarks = []struct {
+	encoding TextEncoding
+	mark     []byte
+}{
+	{EncodingUTF8, utf8BOM},
+	{EncodingUTF16LE, []byte{0xff, 0xfe}},
+	{EncodingUTF16BE, []byte{0xfe, 0xff}},
+}
+
+// EncodingFromHeader identifies the encoding of text from its first bytes: by its byte order
+// mark, or for UTF-16 without one, by NUL bytes falling on every other byte as ASCII does
+func EncodingFromHeader(header []byte) TextEncoding {
+	for _, entry := range byteOrderMarks {
+		if bytes.HasPrefix(header, entry.mark) {
+			return entry.encoding
+		}
+	}
+
+	// ASCII text in UTF-16 has a NUL high byte per character, on the odd bytes for
+	// little-endian and the even ones for big-endian. Require most pairs to look that way,
+	// so binary data with scattered NULs isn't mistaken for text.
+	pairs := len(header) / 2
+	if pairs < 8 {
+		return EncodingUnknown
+	}
+	var evenNuls, oddNuls int
+	for i := 0; i+1 < len(header); i += 2 {
+		if header[i] == 0 && header[i+1] != 0 {
+			evenNuls++
+		} else if header[i] != 0 && header[i+1] == 0 {
+			oddNuls++
+		}
+	}
+	switch {
+	case oddNuls*10 >= pairs*9:
+		return EncodingUTF16LE
+	case evenNuls*10 >= pairs*9:
+		return EncodingUTF16BE
+	}
+	return EncodingUnknown
+}
+
+// utf16Reader transcodes a UTF-16 stream to UTF-8, dropping its byte order mark.
+// Unpaired surrogates and a trailing odd byte become U+FFFD.
+type utf16Reader struct {
+	source    *bufio.Reader
+	bigEndian bool
+	started   bool
+	pending   []byte // Transcoded bytes not yet returned
+	err       error
+}
+
+// newUTF16Reader wraps a UTF-16 stream of the given encoding in a UTF-8 reader
+func newUTF16Reader(source io.Reader, encoding TextEncoding) io.Reader {
+	return &utf16Reader{
+		source:    bufio.NewReader(source),
+		bigEndian: encoding == EncodingUTF16BE,
+	}
+}
+
+// Read fills p with transcoded UTF-8
+func (ur *utf16Reader) Read(p []byte) (int, error) {
+	for len(ur.pending) == 0 {
+		if ur.err != nil {
+			return 0, ur.err
+		}
+		ur.fill(len(p))
+	}
+
+	n := copy(p, ur.pending)
+	ur.pending = ur.pending[n:]
+	return n, nil
+}
+
+// fill transcodes roughly size bytes worth of code units into pending
+func (ur *utf16Reader) fill(size int) {
+	if size < utf8.UTFMax {
+		size = utf8.UTFMax
+	}
+	out := make([]byte, 0, size+utf8.UTFMax)
+
+	for len(out) < size {
+		unit, err := ur.readUnit()
+		if err != nil {
+			ur.err = err
+			break
+		}
+		if !ur.started {
+			ur.started = true
+			if unit == 0xfeff {
+				continue // Byte order mark
+			}
+		}
+
+		r := rune(unit)
+		if utf16.IsSurrogate(r) {
+			r = utf8.RuneError
+			if unit < 0xdc00 {
+				// High surrogate; only valid if a low one follows
+				if next, ok := ur.peekUnit(); ok && next >= 0xdc00 && next <= 0xdfff {
+					ur.readUnit()
+					r = utf16.DecodeRune(rune(unit), rune(next))
+				}
+			}
+		}
+		out = utf8.AppendRune(out, r)
+	}
+
+	ur.pending = out
+}
+
+// readUnit reads one 16-bit code unit
+func (ur *utf16Reader) readUnit() (uint16, error) {
+	var pair [2]byte
+	n, err := io.ReadFull(ur.source, pair[:])
+	switch {
+	case n == 1:
+		return uint16(utf8.RuneError), nil // Truncated final unit; the next read returns EOF
+	case err != nil:
+		return 0, err
+	}
+	return ur.decodeUnit(pair[:]), nil
+}
+
+// peekUnit returns the next code unit without consuming it
+func (ur *utf16Reader) peekUnit() (uint16, bool) {
+	pair, err := ur.source.Peek(2)
+	if err != nil {
+		return 0, false
+	}
+	return ur.decodeUnit(pair), true
+}
+
+func (ur *utf16Reader) decodeUnit(pair []byte) uint16 {
+	if ur.bigEndian {
+		return uint16(pair[0])<<8 | uint16(pair[1])
+	}
+	return uint16(pair[1])<<8 | uint16(pair[0])
+}
+
+// newTextReader transcodes UTF-16 content to UTF-8 and drops a UTF-8 byte order mark, as
+// detected from the first bytes of the stream; any other content is passed through unchanged
+func newTextReader(source io.Reader) io.Reader {
+	buffered := bufio.NewReaderSize(source, EncodingSniffSize)
+	header, _ := buffered.Peek(EncodingSniffSize) // Read errors resurface on the next Read
+	switch encoding := EncodingFromHeader(header); {
+	case encoding.IsUTF16():
+		return newUTF16Reader(buffered, encoding)
+	case encoding == EncodingUTF8:
+		buffered.Discard(len(utf8BOM))
+	}
+	return buffered
+}
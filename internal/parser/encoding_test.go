@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"testing/iotest"
+	"unicode/utf16"
+)
+
+// encodeUTF16 encodes text as UTF-16 in the given byte order, with an optional byte order mark
+func encodeUTF16(text string, bigEndian, bom bool) []byte {
+	units := utf16.Encode([]rune(text))
+	if bom {
+		units = append([]uint16{0xfeff}, units...)
+	}
+
+	var out []byte
+	for _, unit := range units {
+		if bigEndian {
+			out = append(out, byte(unit>>8), byte(unit))
+		} else {
+			out = append(out, byte(unit), byte(unit>>8))
+		}
+	}
+	return out
+}
+
+func TestEncodingFromHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   TextEncoding
+	}{
+		{"plain ASCII", []byte("2024-01-02 10:00:00 started\n"), EncodingUnknown},
+		{"UTF-8 BOM", append([]byte{0xef, 0xbb, 0xbf}, "hello"...), EncodingUTF8},
+		{"UTF-16LE BOM", encodeUTF16("hello", false, true), EncodingUTF16LE},
+		{"UTF-16BE BOM", encodeUTF16("hello", true, true), EncodingUTF16BE},
+		{"UTF-16LE without BOM", encodeUTF16("2024-01-02 10:00:00 started", false, false), EncodingUTF16LE},
+		{"UTF-16BE without BOM", encodeUTF16("2024-01-02 10:00:00 started", true, false), EncodingUTF16BE},
+		{"too short to tell", encodeUTF16("abc", false, false), EncodingUnknown},
+		{"binary with scattered NULs", []byte{0x7f, 'E', 'L', 'F', 2, 1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 3, 0, 0x3e, 0}, EncodingUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EncodingFromHeader(tt.header); got != tt.want {
+				t.Errorf("EncodingFromHeader() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUTF16Reader(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		encoding TextEncoding
+		want     string
+	}{
+		{"little-endian with BOM", encodeUTF16("line one\nline two\n", false, true), EncodingUTF16LE, "line one\nline two\n"},
+		{"big-endian with BOM", encodeUTF16("line one\n", true, true), EncodingUTF16BE, "line one\n"},
+		{"without BOM", encodeUTF16("no mark", false, false), EncodingUTF16LE, "no mark"},
+		{"non-ASCII", encodeUTF16("größe: 5 €", false, true), EncodingUTF16LE, "größe: 5 €"},
+		{"surrogate pair", encodeUTF16("ok 🚀 done", true, false), EncodingUTF16BE, "ok 🚀 done"},
+		{"unpaired high surrogate", []byte{'a', 0, 0x3d, 0xd8, 'b', 0}, EncodingUTF16LE, "a�b"},
+		{"unpaired low surrogate", []byte{'a', 0, 0x00, 0xdc}, EncodingUTF16LE, "a�"},
+		{"trailing odd byte", []byte{'a', 0, 'b'}, EncodingUTF16LE, "a�"},
+		{"empty", nil, EncodingUTF16LE, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := io.ReadAll(newUTF16Reader(bytes.NewReader(tt.input), tt.encoding))
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ReadAll() = %q, want %q", got, tt.want)
+			}
+
+			// Reading a byte at a time splits multi-byte characters across reads
+			got, err = io.ReadAll(iotest.OneByteReader(newUTF16Reader(bytes.NewReader(tt.input), tt.encoding)))
+			if err != nil {
+				t.Fatalf("ReadAll() one byte at a time error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ReadAll() one byte at a time = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTextReader(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		want  string
+	}{
+		{"plain text unchanged", []byte("plain\n"), "plain\n"},
+		{"UTF-8 BOM dropped", append([]byte{0xef, 0xbb, 0xbf}, "bom\n"...), "bom\n"},
+		{"UTF-16LE transcoded", encodeUTF16("wide\n", false, true), "wide\n"},
+		{"UTF-16BE without BOM transcoded", encodeUTF16("2024-01-02 10:00:00 wide\n", true, false), "2024-01-02 10:00:00 wide\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := io.ReadAll(newTextReader(bytes.NewReader(tt.input)))
+			if err != nil {
+				t.Fatalf("ReadAll() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ReadAll() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
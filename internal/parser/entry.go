@@ -0,0 +1,177 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/cheerioskun/logninja/internal/models"
+)
+
+const (
+	// maxEntryLines and maxEntryBytes bound how much is grouped into one entry, so a file
+	// that loses its timestamps doesn't turn into a single entry held in memory
+	maxEntryLines = 10000
+	maxEntryBytes = 4 * 1024 * 1024
+
+	// maxHeaderPrefix is how far into a line an entry's timestamp may start: after a
+	// bracket, a syslog priority, a client address, but not deep inside a message
+	maxHeaderPrefix = 48
+)
+
+// continuationPrefixes start lines that always continue the entry above them, even when
+// they quote a timestamp
+var continuationPrefixes = [][]byte{
+	[]byte("Caused by:"),
+	[]byte("Traceback (most recent call last)"),
+	[]byte("During handling of the above exception"),
+	[]byte("The above exception was the direct cause"),
+}
+
+// EntryDetector decides which lines start a log entry. An entry is a timestamped line
+// together with the lines after it that have no timestamp of their own: stack frames,
+// wrapped messages, pretty-printed payloads.
+type EntryDetector struct {
+	extractor *TimestampExtractor
+	pattern   *TimestampPattern
+}
+
+// NewEntryDetector creates a detector trying a file's timestamp pattern first, like
+// ParseTimestamp, and then every known format. pattern may be nil.
+func NewEntryDetector(extractor *TimestampExtractor, pattern *TimestampPattern) *EntryDetector {
+	return &EntryDetector{
+		extractor: extractor,
+		pattern:   pattern,
+	}
+}
+
+// IsEntryStart returns true if the line (without its newline) begins a new entry
+func (ed *EntryDetector) IsEntryStart(line []byte) bool {
+	if IsContinuationLine(line) {
+		return false
+	}
+	return ed.extractor.startsEntry(line, ed.pattern)
+}
+
+// headerMatch returns true if the pattern matches a timestamp in the line's header. The text
+// before it must be short and must not read like a message ("Exception: failed at ..."),
+// except in JSON objects, whose timestamp field may come after others.
+func headerMatch(line []byte, pattern *TimestampPattern) bool {
+	if pattern.Field != "" {
+		return pattern.matchesLine(string(line))
+	}
+
+	loc := pattern.Regex.FindIndex(line)
+	if loc == nil {
+		return false
+	}
+
+	prefix := line[:loc[0]]
+	if len(prefix) > 0 && prefix[0] == '{' {
+		return true
+	}
+	return len(prefix) <= maxHeaderPrefix && !bytes.Contains(prefix, []byte(": "))
+}
+
+// IsContinuationLine returns true for lines that can only continue an entry: blank and
+// indented lines (stack frames, wrapped text) and the headers of chained stack traces
+func IsContinuationLine(line []byte) bool {
+	if len(line) == 0 || line[0] == ' ' || line[0] == '\t' || line[0] == '\r' {
+		return true
+	}
+	for _, prefix := range continuationPrefixes {
+		if bytes.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// EntryTracker follows entry boundaries line by line. Each entry is attributed to the
+// level of its first line, so a stack frame mentioning "ERROR" doesn't change the level
+// of the warning it belongs to.
+type EntryTracker struct {
+	detector *EntryDetector
+	started  bool
+	level    models.LogLevel
+}
+
+// NewEntryTracker creates a tracker positioned before the first line of a file or span
+func NewEntryTracker(detector *EntryDetector) *EntryTracker {
+	return &EntryTracker{
+		detector: detector,
+		level:    models.LevelUnknown,
+	}
+}
+
+// Next reports whether a line starts a new entry, and the level of the entry it belongs to.
+// The first line always starts an entry, even if it continues one from before the span.
+func (et *EntryTracker) Next(line []byte) (start bool, level models.LogLevel) {
+	if et.started && !et.detector.IsEntryStart(line) {
+		return false, et.level
+	}
+
+	et.started = true
+	et.level = models.LevelUnknown
+	if detected, ok := DetectLevel(line); ok {
+		et.level = detected
+	}
+	return true, et.level
+}
+
+// ForEachEntry reads r and calls fn with the lines of every entry, each including its
+// newline. The slices are only valid during the call. Oversized entries are passed on in
+// several parts.
+func ForEachEntry(r io.Reader, detector *EntryDetector, fn func(lines [][]byte) error) error {
+	reader := bufio.NewReaderSize(r, 64*1024)
+
+	var buffer []byte // Bytes of the current entry
+	var ends []int    // End offset of each of its lines in buffer
+	lines := make([][]byte, 0, 16)
+
+	flush := func() error {
+		if len(ends) == 0 {
+			return nil
+		}
+		lines = lines[:0]
+		start := 0
+		for _, end := range ends {
+			lines = append(lines, buffer[start:end])
+			start = end
+		}
+		err := fn(lines)
+		buffer, ends = buffer[:0], ends[:0]
+		return err
+	}
+
+	for {
+		line, readErr := reader.ReadSlice('\n')
+		if readErr == bufio.ErrBufferFull {
+			// Gather lines longer than the buffer
+			line = append([]byte(nil), line...)
+			for readErr == bufio.ErrBufferFull {
+				var more []byte
+				more, readErr = reader.ReadSlice('\n')
+				line = append(line, more...)
+			}
+		}
+
+		if len(line) > 0 {
+			full := len(ends) >= maxEntryLines || len(buffer) >= maxEntryBytes
+			if full || detector.IsEntryStart(bytes.TrimRight(line, "\r\n")) {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+			buffer = append(buffer, line...)
+			ends = append(ends, len(buffer))
+		}
+
+		if readErr == io.EOF {
+			return flush()
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/cheerioskun/logninja/internal/models"
+)
+
+func TestEntryTracker(t *testing.T) {
+	extractor := NewTimestampExtractor(nil)
+
+	type line struct {
+		text  string
+		start bool
+		level models.LogLevel
+	}
+	tests := []struct {
+		name    string
+		pattern string
+		lines   []line
+	}{
+		{
+			name:    "java stack trace",
+			pattern: "DateTime_Dash",
+			lines: []line{
+				{"2024-01-02 10:00:00 INFO started", true, models.LevelInfo},
+				{"2024-01-02 10:00:01 WARN request failed", true, models.LevelWarn},
+				{"java.lang.IllegalStateException: ERROR in handler", false, models.LevelWarn},
+				{"\tat com.example.Handler.run(Handler.java:42)", false, models.LevelWarn},
+				{"Caused by: java.io.IOException: 2024-01-02 09:59:59 timeout", false, models.LevelWarn},
+				{"\t... 12 more", false, models.LevelWarn},
+				{"2024-01-02 10:00:02 ERROR giving up", true, models.LevelError},
+			},
+		},
+		{
+			name:    "python traceback",
+			pattern: "DateTime_Milli",
+			lines: []line{
+				{"2024-01-02 10:00:00,123 ERROR worker crashed", true, models.LevelError},
+				{"Traceback (most recent call last):", false, models.LevelError},
+				{`  File "worker.py", line 10, in run`, false, models.LevelError},
+				{"ValueError: bad input", false, models.LevelError},
+				{"", false, models.LevelError},
+				{"2024-01-02 10:00:01,000 INFO restarted", true, models.LevelInfo},
+			},
+		},
+		{
+			name:    "span starting mid-entry",
+			pattern: "DateTime_Dash",
+			lines: []line{
+				{"\tat com.example.Handler.run(Handler.java:42)", true, models.LevelUnknown},
+				{"2024-01-02 10:00:00 request handled", true, models.LevelUnknown},
+				{"  wrapped message text", false, models.LevelUnknown},
+			},
+		},
+		{
+			name:    "timestamp quoted in a message",
+			pattern: "DateTime_Dash",
+			lines: []line{
+				{"2024-01-02 10:00:00 INFO retrying", true, models.LevelInfo},
+				{"Exception: failed at 2024-01-02 09:00:00", false, models.LevelInfo},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pattern := extractor.PatternByName(tt.pattern)
+			if pattern == nil {
+				t.Fatalf("no pattern %q", tt.pattern)
+			}
+			tracker := NewEntryTracker(NewEntryDetector(extractor, pattern))
+
+			for _, l := range tt.lines {
+				start, level := tracker.Next([]byte(l.text))
+				if start != l.start || level != l.level {
+					t.Errorf("Next(%q) = %v, %q, want %v, %q", l.text, start, level, l.start, l.level)
+				}
+			}
+		})
+	}
+}
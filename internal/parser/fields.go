@@ -0,0 +1,185 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/cheerioskun/logninja/internal/structured"
+)
+
+// LineFormat is the structure of the lines a field pattern reads its timestamp from
+type LineFormat string
+
+const (
+	FormatJSON   LineFormat = "json"   // One JSON object per line
+	FormatLogfmt LineFormat = "logfmt" // key=value pairs
+)
+
+// layoutAuto marks field patterns without a layout. Their values may be epoch numbers
+// (s, ms, µs or ns) or strings in any known format.
+const layoutAuto = "auto"
+
+// Keys commonly holding the timestamp of a structured log line, in order of preference
+var (
+	jsonTimestampFields   = []string{"@timestamp", "timestamp", "time", "ts", "datetime", "date", "eventTime"}
+	logfmtTimestampFields = []string{"time", "ts", "t"}
+)
+
+// Regexes identifying the lines of each format
+var (
+	jsonLineRegex   = regexp.MustCompile(`^\s*\{`)
+	logfmtLineRegex = regexp.MustCompile(`^[A-Za-z_][\w.\-]*=`)
+)
+
+// compileFieldPatterns returns one pattern per common timestamp field of each structured format.
+// They rank ahead of every text pattern, since a timestamp found in its field beats one found
+// anywhere in the line.
+func compileFieldPatterns() []TimestampPattern {
+	patterns := make([]TimestampPattern, 0, len(jsonTimestampFields)+len(logfmtTimestampFields))
+	for _, field := range jsonTimestampFields {
+		patterns = append(patterns, fieldPattern(FormatJSON, field, 0))
+	}
+	for _, field := range logfmtTimestampFields {
+		patterns = append(patterns, fieldPattern(FormatLogfmt, field, 0))
+	}
+
+	for i := range patterns {
+		patterns[i].Priority = i - len(patterns)
+	}
+	return patterns
+}
+
+// fieldPattern creates a pattern reading the timestamp from a field, named after the format
+// and field ("JSON_ts", "Logfmt_time")
+func fieldPattern(format LineFormat, field string, priority int) TimestampPattern {
+	pattern := TimestampPattern{
+		Name:     "JSON_" + field,
+		Regex:    jsonLineRegex,
+		Layout:   layoutAuto,
+		Priority: priority,
+		Field:    field,
+		Format:   FormatJSON,
+	}
+	if format == FormatLogfmt {
+		pattern.Name = "Logfmt_" + field
+		pattern.Regex = logfmtLineRegex
+		pattern.Format = FormatLogfmt
+	}
+	return pattern
+}
+
+// fieldValue returns the raw value of a field pattern's key in a line
+func (p *TimestampPattern) fieldValue(line []byte) ([]byte, bool) {
+	if p.Format == FormatLogfmt {
+		return structured.LogfmtField(line, p.Field)
+	}
+	return structured.JSONField(line, p.Field)
+}
+
+// matchesLine reports whether a pattern finds a timestamp candidate in a line;
+// field patterns also need the field to hold a number or a string with digits
+func (p *TimestampPattern) matchesLine(line string) bool {
+	if !p.Regex.MatchString(line) {
+		return false
+	}
+	if p.Field != "" {
+		value, ok := p.fieldValue([]byte(line))
+		return ok && bytes.ContainsAny(value, "0123456789")
+	}
+	return true
+}
+
+// parseFieldTimestamp parses the timestamp field of a structured line. Strings are read with the
+// pattern's layout if it has one, and otherwise as epoch numbers, ISO 8601 or any text pattern.
+func (te *TimestampExtractor) parseFieldTimestamp(line string, pattern *TimestampPattern) (time.Time, error) {
+	value, ok := pattern.fieldValue([]byte(line))
+	if !ok || len(value) == 0 {
+		return time.Time{}, fmt.Errorf("no %q field found", pattern.Field)
+	}
+	quoted := value[0] == '"'
+	text := string(structured.Unquote(value))
+
+	if pattern.Layout != layoutAuto {
+		return te.parseMatch(text, "", pattern.Layout)
+	}
+
+	if isDigits(text) || !quoted && isNumber(text) {
+		return parseEpochNumber(text)
+	}
+	for _, layout := range isoLayouts {
+		if parsed, err := time.ParseInLocation(layout, text, te.location); err == nil {
+			return parsed, nil
+		}
+	}
+	for i := range te.patterns {
+		if te.patterns[i].Field == "" {
+			if parsed, err := te.parseTimestampWithPattern(text, &te.patterns[i]); err == nil {
+				return parsed, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised timestamp %q in field %q", text, pattern.Field)
+}
+
+// isoLayouts are the ISO 8601 variants tried first on string timestamp fields;
+// values without an offset are read in the file's zone
+var isoLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// parseEpochNumber reads an epoch timestamp, inferring its unit from its magnitude:
+// seconds (optionally fractional), milliseconds, microseconds or nanoseconds
+func parseEpochNumber(text string) (time.Time, error) {
+	if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+		switch abs := max(n, -n); {
+		case abs < 1e11:
+			return time.Unix(n, 0), nil
+		case abs < 1e14:
+			return time.UnixMilli(n), nil
+		case abs < 1e17:
+			return time.UnixMicro(n), nil
+		default:
+			return time.Unix(0, n), nil
+		}
+	}
+
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return time.Time{}, fmt.Errorf("invalid epoch timestamp %q", text)
+	}
+	unit := 1e9 // Nanoseconds per unit
+	switch abs := math.Abs(f); {
+	case abs >= 1e17:
+		unit = 1
+	case abs >= 1e14:
+		unit = 1e3
+	case abs >= 1e11:
+		unit = 1e6
+	}
+	return time.Unix(0, int64(f*unit)), nil
+}
+
+func isNumber(text string) bool {
+	_, err := strconv.ParseFloat(text, 64)
+	return err == nil
+}
+
+func isDigits(text string) bool {
+	if text == "" {
+		return false
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] < '0' || text[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
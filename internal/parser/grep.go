@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sync"
+
+	"github.com/cheerioskun/logninja/internal/models"
+	"github.com/cheerioskun/logninja/internal/utils"
+	"github.com/spf13/afero"
+)
+
+// grepCancelCheckLines is how many lines are searched between cancellation checks
+const grepCancelCheckLines = 4096
+
+// ContentSearcher counts the lines of bundle files matching a pattern, streaming
+// (decompressed) file contents through a pool of workers
+type ContentSearcher struct {
+	fs      afero.Fs
+	workers int
+}
+
+// NewContentSearcher creates a content searcher reading through fs
+func NewContentSearcher(fs afero.Fs) *ContentSearcher {
+	return &ContentSearcher{
+		fs:      fs,
+		workers: runtime.NumCPU(),
+	}
+}
+
+// SetWorkers sets how many files are searched concurrently
+func (cs *ContentSearcher) SetWorkers(workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	cs.workers = workers
+}
+
+// SearchBundle counts the matching lines of every file in the bundle. The result is keyed by
+// bundle-relative path and leaves out files without a match. Unreadable files are skipped;
+// a cancelled ctx stops the search with ctx.Err().
+func (cs *ContentSearcher) SearchBundle(ctx context.Context, bundle *models.Bundle, pattern *regexp.Regexp) (map[string]int64, error) {
+	if bundle == nil {
+		return nil, fmt.Errorf("bundle cannot be nil")
+	}
+
+	matches := make(map[string]int64)
+	var merge sync.Mutex
+
+	err := forEachIndex(ctx, cs.workers, len(bundle.Files), func(index int) {
+		file := bundle.Files[index]
+		count, err := cs.CountMatches(ctx, filepath.Join(bundle.Path, file.Path), pattern)
+		if err != nil {
+			if ctx.Err() == nil {
+				utils.Debug("Skipping %s in content search: %v", file.Path, err)
+			}
+			return
+		}
+		if count == 0 {
+			return
+		}
+
+		merge.Lock()
+		matches[file.Path] = count
+		merge.Unlock()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+// CountMatches counts the lines of a file matching pattern, decompressing it if needed
+func (cs *ContentSearcher) CountMatches(ctx context.Context, filePath string, pattern *regexp.Regexp) (int64, error) {
+	reader, _, err := OpenDecompressed(cs.fs, filePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer reader.Close()
+
+	// Literal patterns skip the regex engine
+	match := pattern.Match
+	if literal, complete := pattern.LiteralPrefix(); complete {
+		needle := []byte(literal)
+		match = func(line []byte) bool { return bytes.Contains(line, needle) }
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, readerAtBlockSize), maxScanLineSize)
+
+	var count, lines int64
+	for scanner.Scan() {
+		lines++
+		if lines%grepCancelCheckLines == 0 && ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		if match(scanner.Bytes()) {
+			count++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return count, nil
+}
@@ -2,7 +2,11 @@ package parser
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"runtime"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/cheerioskun/logninja/internal/models"
@@ -14,6 +18,7 @@ import (
 type HistogramBuilder struct {
 	boundsExtractor *BoundsExtractor
 	fs              afero.Fs
+	workers         int
 }
 
 // NewHistogramBuilder creates a new histogram builder
@@ -21,19 +26,35 @@ func NewHistogramBuilder(fs afero.Fs) *HistogramBuilder {
 	return &HistogramBuilder{
 		boundsExtractor: NewBoundsExtractor(fs),
 		fs:              fs,
+		workers:         runtime.NumCPU(),
 	}
 }
 
+// SetWorkers sets how many files are processed concurrently
+func (hb *HistogramBuilder) SetWorkers(workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	hb.workers = workers
+}
+
 // BuildHistogram creates a log volume histogram from a working set
 func (hb *HistogramBuilder) BuildHistogram(workingSet *models.WorkingSet, binCount int) ([]models.VolumePoint, error) {
+	return hb.BuildHistogramContext(context.Background(), workingSet, binCount)
+}
+
+// BuildHistogramContext creates a log volume histogram like BuildHistogram, giving up
+// with ctx.Err() once ctx is cancelled (e.g. because the selection changed again).
+// Files are processed in parallel, each in a single pass that fills every bin at once.
+func (hb *HistogramBuilder) BuildHistogramContext(ctx context.Context, workingSet *models.WorkingSet, binCount int) ([]models.VolumePoint, error) {
 	if binCount <= 0 {
 		binCount = 20 // Default
 	}
 
 	// Step 1: Extract time bounds from all selected files
-	fileBounds, err := hb.boundsExtractor.ExtractBoundsFromWorkingSet(workingSet)
+	fileBounds, err := hb.extractBounds(ctx, workingSet)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract file bounds: %w", err)
+		return nil, err
 	}
 
 	if len(fileBounds) == 0 {
@@ -49,45 +70,135 @@ func (hb *HistogramBuilder) BuildHistogram(workingSet *models.WorkingSet, binCou
 	// Step 3: Create time bins
 	bins := hb.createTimeBins(globalRange, binCount)
 
-	// Step 4: Populate histogram by binary searching each file for each bin
 	volumePoints := make([]models.VolumePoint, len(bins))
-
 	for i, bin := range bins {
 		volumePoints[i] = models.VolumePoint{
-			BinStart:  bin.Start,
-			BinEnd:    bin.End,
-			Count:     0,
-			Size:      0,
-			FileCount: 0,
+			BinStart: bin.Start,
+			BinEnd:   bin.End,
 		}
+	}
 
-		// For each file, binary search to find bytes within this time bin
-		for _, fileBound := range fileBounds {
-			if !fileBound.Valid {
-				continue
+	// Step 4: Count every file's bytes and lines per bin, merging as files finish
+	var merge sync.Mutex
+	err = forEachIndex(ctx, hb.workers, len(fileBounds), func(index int) {
+		fileBound := fileBounds[index]
+
+		counts, err := hb.countPerBin(ctx, fileBound, bins)
+		if err != nil {
+			if ctx.Err() == nil {
+				// Log warning but continue with other files
+				utils.Warning("failed to count volume in %s: %v", fileBound.FilePath, err)
 			}
+			return
+		}
 
-			// Skip files that don't overlap with this bin
-			if fileBound.Latest.Before(bin.Start) || fileBound.Earliest.After(bin.End) {
-				continue
+		merge.Lock()
+		defer merge.Unlock()
+		for i, count := range counts {
+			if count.size > 0 {
+				volumePoints[i].Size += count.size
+				volumePoints[i].Count += count.entries
+				volumePoints[i].FileCount++
+				if volumePoints[i].Levels == nil {
+					volumePoints[i].Levels = make(models.LevelCounts)
+				}
+				volumePoints[i].Levels.Add(count.levels)
 			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return volumePoints, nil
+}
+
+// extractBounds finds the time bounds of every selected log file, keeping only files with
+// valid timestamps. The range and pattern recorded by the scan are used where present; other
+// files are read in parallel.
+func (hb *HistogramBuilder) extractBounds(ctx context.Context, workingSet *models.WorkingSet) ([]TimeBounds, error) {
+	if workingSet == nil || workingSet.Bundle == nil {
+		return nil, fmt.Errorf("invalid working set")
+	}
+
+	var bounds []TimeBounds
+	var unscanned []string
+	for _, file := range workingSet.Bundle.Files {
+		if !workingSet.SelectedFiles[file.Path] || !file.IsLogFile {
+			continue
+		}
 
-			byteCount, err := hb.countBytesInTimeRange(fileBound.FilePath, fileBound.BestPattern, bin.Start, bin.End)
-			if err != nil {
-				// Log warning but continue
-				utils.Warning("failed to count bytes in %s for bin %v-%v: %v",
-					fileBound.FilePath, bin.Start.Format(time.RFC3339), bin.End.Format(time.RFC3339), err)
-				continue
+		path := workingSet.Bundle.GetAbsolutePath(file.Path)
+		pattern := hb.boundsExtractor.timestampExtractor.PatternByName(file.TimestampPattern)
+		if file.TimeRange == nil || pattern == nil {
+			unscanned = append(unscanned, path)
+			continue
+		}
+		bounds = append(bounds, TimeBounds{
+			Earliest:    file.TimeRange.Start,
+			Latest:      file.TimeRange.End,
+			FilePath:    path,
+			Valid:       true,
+			BestPattern: pattern,
+		})
+	}
+
+	results := make([]*TimeBounds, len(unscanned))
+	err := forEachIndex(ctx, hb.workers, len(unscanned), func(index int) {
+		fileBounds, err := hb.boundsExtractor.ExtractBoundsContext(ctx, unscanned[index])
+		if err != nil {
+			if ctx.Err() == nil {
+				// Log warning but continue with other files
+				utils.Warning("failed to extract bounds from %s: %v", unscanned[index], err)
 			}
+			return
+		}
+		results[index] = fileBounds
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-			if byteCount > 0 {
-				volumePoints[i].Size += byteCount
-				volumePoints[i].FileCount++
+	for _, fileBounds := range results {
+		if fileBounds != nil && fileBounds.Valid {
+			bounds = append(bounds, *fileBounds)
+		}
+	}
+
+	return bounds, nil
+}
+
+// forEachIndex calls process for every index in [0, count) using a pool of workers,
+// returning ctx.Err() if ctx is cancelled before all indexes were dispatched
+func forEachIndex(ctx context.Context, workers, count int, process func(index int)) error {
+	jobs := make(chan int)
+
+	var running sync.WaitGroup
+	for w := 0; w < workers && w < count; w++ {
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			for index := range jobs {
+				process(index)
 			}
+		}()
+	}
+
+dispatch:
+	for index := 0; index < count; index++ {
+		select {
+		case jobs <- index:
+		case <-ctx.Done():
+			break dispatch
 		}
 	}
+	close(jobs)
+	running.Wait()
 
-	return volumePoints, nil
+	return ctx.Err()
 }
 
 // TimeBin represents a time range for histogram binning
@@ -118,63 +229,150 @@ func (hb *HistogramBuilder) createTimeBins(globalRange *models.TimeRange, binCou
 	return bins
 }
 
-// countBytesInTimeRange counts bytes within a time range using mmap + binary search
-func (hb *HistogramBuilder) countBytesInTimeRange(filePath string, bestPattern *TimestampPattern, startTime, endTime time.Time) (int64, error) {
-	// Create memory-mapped file searcher for binary search
-	searcher, err := NewMmapFileSearcher(hb.fs, filePath, hb.boundsExtractor.timestampExtractor, bestPattern)
+// binCount is one file's contribution to a histogram bin
+type binCount struct {
+	size    int64
+	entries int64
+	levels  models.LevelCounts
+}
+
+// countPerBin counts a file's bytes, entries and entries per level in every bin. Bins are
+// treated as half-open [Start, End) except the last, which includes its End, so an entry
+// exactly on a boundary is counted once. Boundaries fall on entry starts, so continuation
+// lines are counted in the bin of the entry they belong to. Entries and levels are counted
+// in a single pass over the span the bins cover.
+func (hb *HistogramBuilder) countPerBin(ctx context.Context, fileBound TimeBounds, bins []TimeBin) ([]binCount, error) {
+	searcher, err := NewTimeSearcher(hb.fs, fileBound.FilePath, hb.boundsExtractor.timestampExtractor, fileBound.BestPattern)
 	if err != nil {
-		// Fallback to linear scan for files that can't be mmapped (e.g., non-OsFs)
-		return hb.countBytesInTimeRangeLinear(filePath, bestPattern, startTime, endTime)
+		// Fallback to linear scan for files that can't be searched by offset (compressed or UTF-16)
+		return hb.countPerBinLinear(ctx, fileBound, bins)
 	}
 	defer searcher.Close()
 
-	// Use binary search to find byte boundaries for the time range
-	byteCount := searcher.BinarySearchTimeRange(startTime, endTime)
+	// One binary search per bin boundary; boundaries outside the file need no search
+	fileSize := searcher.GetFileStats()
+	boundaries := make([]int64, len(bins)+1)
+	for i, bin := range bins {
+		switch {
+		case !bin.Start.After(fileBound.Earliest):
+			boundaries[i] = 0
+		case bin.Start.After(fileBound.Latest):
+			boundaries[i] = fileSize
+		default:
+			boundaries[i] = searcher.BinarySearchTime(bin.Start, true)
+		}
+	}
+	boundaries[len(bins)] = searcher.BinarySearchTime(bins[len(bins)-1].End, false)
+
+	// Out-of-order lines can make boundaries regress; such bins count as empty
+	for i := 1; i < len(boundaries); i++ {
+		boundaries[i] = max(boundaries[i], boundaries[i-1])
+	}
+
+	counts := make([]binCount, len(bins))
+	for i := range counts {
+		counts[i] = binCount{size: boundaries[i+1] - boundaries[i], levels: make(models.LevelCounts)}
+	}
+
+	// Boundaries fall on entry starts, so one tracker carries across bins
+	entries := NewEntryTracker(NewEntryDetector(hb.boundsExtractor.timestampExtractor.ForFile(fileBound.FilePath), fileBound.BestPattern))
+	current := 0
+	offset := boundaries[0]
+	var lineNumber int
+	err = searcher.ForEachLine(boundaries[0], boundaries[len(bins)], func(line []byte) error {
+		if lineNumber++; lineNumber%4096 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		for current < len(bins)-1 && offset >= boundaries[current+1] {
+			current++
+		}
+		offset += int64(len(line)) + 1 // +1 for newline
+
+		if start, level := entries.Next(line); start {
+			counts[current].entries++
+			counts[current].levels[level]++
+		}
+		return nil
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("error scanning file: %w", err)
+	}
 
-	return byteCount, nil
+	return counts, nil
 }
 
-// countBytesInTimeRangeLinear provides fallback linear scanning for non-mmap filesystems
-func (hb *HistogramBuilder) countBytesInTimeRangeLinear(filePath string, bestPattern *TimestampPattern, startTime, endTime time.Time) (int64, error) {
-	file, err := hb.fs.Open(filePath)
+// countPerBinLinear is the single-pass fallback for compressed files;
+// byte counts refer to the decompressed content
+func (hb *HistogramBuilder) countPerBinLinear(ctx context.Context, fileBound TimeBounds, bins []TimeBin) ([]binCount, error) {
+	file, _, err := OpenDecompressed(hb.fs, fileBound.FilePath)
 	if err != nil {
-		return 0, fmt.Errorf("failed to open file: %w", err)
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
-	var byteCount int64
+	counts := make([]binCount, len(bins))
+	for i := range counts {
+		counts[i].levels = make(models.LevelCounts)
+	}
+	current := -1 // Bin of the current entry, -1 if outside all bins
+	extractor := hb.boundsExtractor.timestampExtractor.ForFile(fileBound.FilePath).InOrder()
+	entries := NewEntryTracker(NewEntryDetector(extractor, fileBound.BestPattern))
 
 	scanner := bufio.NewScanner(file)
-	inRange := false
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		lineBytes := int64(len(line) + 1) // +1 for newline
-
-		// Try to parse timestamp
-		if timestamp, err := hb.boundsExtractor.timestampExtractor.ParseTimestamp(line, bestPattern); err == nil {
-			// Line has a timestamp - check if it's in range
-			if !timestamp.Before(startTime) && !timestamp.After(endTime) {
-				inRange = true
-				byteCount += lineBytes
-			} else if timestamp.After(endTime) {
-				// We've gone past the end time, stop scanning
-				break
-			} else if timestamp.Before(startTime) {
-				inRange = false
+	for lineNumber := 0; scanner.Scan(); lineNumber++ {
+		if lineNumber%4096 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+
+		line := scanner.Bytes()
+
+		// Continuation lines belong to the bin of the entry above them
+		start, level := entries.Next(line)
+		if start {
+			if timestamp, err := extractor.ParseTimestamp(string(line), fileBound.BestPattern); err == nil {
+				current = binIndex(bins, timestamp)
+			}
+		}
+
+		if current >= 0 {
+			counts[current].size += int64(len(line) + 1) // +1 for newline
+			if start {
+				counts[current].entries++
+				counts[current].levels[level]++
 			}
-		} else if inRange {
-			// Line without timestamp but we're in the time range
-			// Include it as part of the log entry
-			byteCount += lineBytes
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
-		return 0, fmt.Errorf("error scanning file: %w", err)
+		return nil, fmt.Errorf("error scanning file: %w", err)
+	}
+
+	return counts, nil
+}
+
+// binIndex returns the bin containing timestamp, or -1 if it falls outside all bins
+func binIndex(bins []TimeBin, timestamp time.Time) int {
+	if len(bins) == 0 || timestamp.Before(bins[0].Start) {
+		return -1
 	}
 
-	return byteCount, nil
+	index := sort.Search(len(bins), func(i int) bool {
+		return bins[i].End.After(timestamp)
+	})
+	if index == len(bins) {
+		if timestamp.Equal(bins[len(bins)-1].End) {
+			return len(bins) - 1 // The last bin includes its end
+		}
+		return -1
+	}
+	return index
 }
 
 // UpdateWorkingSetHistogram updates the VolumeData in a working set
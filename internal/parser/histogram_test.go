@@ -0,0 +1,211 @@
+package parser
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cheerioskun/logninja/internal/models"
+	"github.com/spf13/afero"
+)
+
+// testLogStart is the timestamp of the first entry of generated test logs
+var testLogStart = time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+
+// testLogEntry is an entry of a generated log and its byte offset
+type testLogEntry struct {
+	time   time.Time
+	offset int64
+}
+
+// generateLog writes entries two seconds apart, every seventh followed by a stack trace
+// whose lines quote a timestamp, and returns the content with its entries
+func generateLog(count int) ([]byte, []testLogEntry) {
+	var content bytes.Buffer
+	entries := make([]testLogEntry, 0, count)
+	for i := 0; i < count; i++ {
+		timestamp := testLogStart.Add(time.Duration(2*i) * time.Second)
+		entries = append(entries, testLogEntry{time: timestamp, offset: int64(content.Len())})
+
+		level := []string{"INFO", "WARN", "ERROR"}[i%3]
+		fmt.Fprintf(&content, "%s %s worker-%d handled request %d\n", timestamp.Format("2006-01-02 15:04:05"), level, i%5, i)
+		if i%7 == 0 {
+			fmt.Fprintf(&content, "java.lang.RuntimeException: retry at %s\n", timestamp.Add(time.Hour).Format("2006-01-02 15:04:05"))
+			content.WriteString("\tat com.example.Worker.run(Worker.java:42)\n\tat java.lang.Thread.run(Thread.java:750)\n")
+		}
+	}
+	return content.Bytes(), entries
+}
+
+// generatedLevels returns the entries per level of a log written by generateLog
+func generatedLevels(count int) models.LevelCounts {
+	levels := make(models.LevelCounts)
+	for i := 0; i < count; i++ {
+		levels[[]models.LogLevel{models.LevelInfo, models.LevelWarn, models.LevelError}[i%3]]++
+	}
+	return levels
+}
+
+// histogramBundle writes a plain and a gzip-compressed log into root on fs and returns a
+// working set with both selected
+func histogramBundle(t *testing.T, fs afero.Fs, root string, plain, compressed []byte) *models.WorkingSet {
+	t.Helper()
+
+	var gzipped bytes.Buffer
+	writer := gzip.NewWriter(&gzipped)
+	writer.Write(compressed)
+	writer.Close()
+
+	files := map[string][]byte{
+		"app.log":        plain,
+		"old/app.log.gz": gzipped.Bytes(),
+	}
+	bundle := models.NewBundle(root, fs)
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := afero.WriteFile(fs, path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		bundle.AddFile(models.FileInfo{Path: name, Size: int64(len(content)), IsLogFile: true})
+	}
+
+	workingSet := models.NewWorkingSet(bundle)
+	for _, file := range bundle.Files {
+		workingSet.SelectedFiles[file.Path] = true
+	}
+	return workingSet
+}
+
+func TestHistogramTotals(t *testing.T) {
+	plain, plainEntries := generateLog(3000)
+	compressed, compressedEntries := generateLog(600)
+
+	fs := afero.NewOsFs()
+	workingSet := histogramBundle(t, fs, t.TempDir(), plain, compressed)
+
+	for _, binCount := range []int{1, 7, 20, 100} {
+		t.Run(fmt.Sprintf("%d bins", binCount), func(t *testing.T) {
+			points, err := NewHistogramBuilder(fs).BuildHistogram(workingSet, binCount)
+			if err != nil {
+				t.Fatalf("BuildHistogram() error = %v", err)
+			}
+			if len(points) != binCount {
+				t.Fatalf("BuildHistogram() returned %d bins, want %d", len(points), binCount)
+			}
+
+			var size, count int64
+			levels := make(models.LevelCounts)
+			for i, point := range points {
+				size += point.Size
+				count += point.Count
+				levels.Add(point.Levels)
+				if i > 0 && !point.BinStart.Equal(points[i-1].BinEnd) {
+					t.Errorf("bin %d starts at %v, previous bin ends at %v", i, point.BinStart, points[i-1].BinEnd)
+				}
+			}
+			if !points[0].BinStart.Equal(testLogStart) || !points[binCount-1].BinEnd.Equal(plainEntries[len(plainEntries)-1].time) {
+				t.Errorf("histogram spans %v to %v, want %v to %v", points[0].BinStart, points[binCount-1].BinEnd, testLogStart, plainEntries[len(plainEntries)-1].time)
+			}
+			if want := int64(len(plain) + len(compressed)); size != want {
+				t.Errorf("histogram holds %d bytes, want %d", size, want)
+			}
+			if count != levels.Total() {
+				t.Errorf("histogram holds %d entries, but %d entries per level", count, levels.Total())
+			}
+
+			if wantCount := int64(len(plainEntries) + len(compressedEntries)); count != wantCount {
+				t.Errorf("histogram holds %d entries, want %d", count, wantCount)
+			}
+			want := generatedLevels(3000)
+			want.Add(generatedLevels(600))
+			if !reflect.DeepEqual(levels, want) {
+				t.Errorf("histogram levels = %v, want %v", levels, want)
+			}
+		})
+	}
+}
+
+func TestHistogramSearchersAgree(t *testing.T) {
+	plain, _ := generateLog(3000)
+	compressed, _ := generateLog(600)
+
+	filesystems := map[string]struct {
+		fs   afero.Fs
+		root string
+	}{
+		"os":     {afero.NewOsFs(), t.TempDir()},   // Memory-mapped searcher
+		"memory": {afero.NewMemMapFs(), "/bundle"}, // ReaderAt searcher
+	}
+
+	histograms := make(map[string][]models.VolumePoint)
+	for name, filesystem := range filesystems {
+		workingSet := histogramBundle(t, filesystem.fs, filesystem.root, plain, compressed)
+		points, err := NewHistogramBuilder(filesystem.fs).BuildHistogram(workingSet, 20)
+		if err != nil {
+			t.Fatalf("%s: BuildHistogram() error = %v", name, err)
+		}
+		histograms[name] = points
+	}
+
+	if !reflect.DeepEqual(histograms["os"], histograms["memory"]) {
+		t.Errorf("histograms differ:\nos:     %+v\nmemory: %+v", histograms["os"], histograms["memory"])
+	}
+}
+
+func TestHistogramUsesScannedBounds(t *testing.T) {
+	plain, plainEntries := generateLog(3000)
+	compressed, compressedEntries := generateLog(600)
+
+	fs := afero.NewOsFs()
+	workingSet := histogramBundle(t, fs, t.TempDir(), plain, compressed)
+	extracted, err := NewHistogramBuilder(fs).BuildHistogram(workingSet, 20)
+	if err != nil {
+		t.Fatalf("BuildHistogram() error = %v", err)
+	}
+
+	// The range and pattern the scan recorded give the same histogram without reading bounds
+	ends := map[string]time.Time{
+		"app.log":        plainEntries[len(plainEntries)-1].time,
+		"old/app.log.gz": compressedEntries[len(compressedEntries)-1].time,
+	}
+	for i := range workingSet.Bundle.Files {
+		file := &workingSet.Bundle.Files[i]
+		file.TimeRange = &models.TimeRange{Start: testLogStart, End: ends[file.Path]}
+		file.TimestampPattern = "DateTime_Dash"
+	}
+	scanned, err := NewHistogramBuilder(fs).BuildHistogram(workingSet, 20)
+	if err != nil {
+		t.Fatalf("BuildHistogram() with scanned bounds error = %v", err)
+	}
+	if !reflect.DeepEqual(scanned, extracted) {
+		t.Errorf("histograms differ:\nscanned:   %+v\nextracted: %+v", scanned, extracted)
+	}
+
+	// A recorded range is trusted as is
+	workingSet.Bundle.Files[0].TimeRange.End = testLogStart.Add(time.Hour)
+	workingSet.Bundle.Files[1].TimeRange.End = testLogStart.Add(time.Hour)
+	points, err := NewHistogramBuilder(fs).BuildHistogram(workingSet, 20)
+	if err != nil {
+		t.Fatalf("BuildHistogram() error = %v", err)
+	}
+	if end := points[len(points)-1].BinEnd; !end.Equal(testLogStart.Add(time.Hour)) {
+		t.Errorf("histogram ends at %v, want the recorded %v", end, testLogStart.Add(time.Hour))
+	}
+
+	// Files without a recorded range are read, unless the build is cancelled
+	workingSet.Bundle.Files[0].TimeRange = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewHistogramBuilder(fs).BuildHistogramContext(ctx, workingSet, 20); !errors.Is(err, context.Canceled) {
+		t.Errorf("BuildHistogramContext() with a cancelled context error = %v, want %v", err, context.Canceled)
+	}
+}
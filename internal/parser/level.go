@@ -0,0 +1,248 @@
+package parser
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"github.com/cheerioskun/logninja/internal/models"
+	"github.com/cheerioskun/logninja/internal/structured"
+)
+
+// levelSearchWindow is how far into a line a bracketed or bare level word is looked for;
+// levels appear next to the timestamp, and further in they are usually part of the message
+const levelSearchWindow = 96
+
+// levelWords maps lower-cased level names to their normalised level
+var levelWords = map[string]models.LogLevel{
+	"fatal":     models.LevelFatal,
+	"critical":  models.LevelFatal,
+	"crit":      models.LevelFatal,
+	"panic":     models.LevelFatal,
+	"emerg":     models.LevelFatal,
+	"emergency": models.LevelFatal,
+	"alert":     models.LevelFatal,
+	"error":     models.LevelError,
+	"err":       models.LevelError,
+	"eror":      models.LevelError,
+	"warn":      models.LevelWarn,
+	"warning":   models.LevelWarn,
+	"wrn":       models.LevelWarn,
+	"info":      models.LevelInfo,
+	"inf":       models.LevelInfo,
+	"notice":    models.LevelInfo,
+	"debug":     models.LevelDebug,
+	"dbg":       models.LevelDebug,
+	"trace":     models.LevelTrace,
+	"trc":       models.LevelTrace,
+}
+
+// glogLevels maps glog's single-letter severity prefixes
+var glogLevels = map[byte]models.LogLevel{
+	'I': models.LevelInfo,
+	'W': models.LevelWarn,
+	'E': models.LevelError,
+	'F': models.LevelFatal,
+}
+
+// syslogSeverities maps syslog PRI severities (PRI % 8) to levels
+var syslogSeverities = [8]models.LogLevel{
+	models.LevelFatal, // 0 emergency
+	models.LevelFatal, // 1 alert
+	models.LevelFatal, // 2 critical
+	models.LevelError, // 3 error
+	models.LevelWarn,  // 4 warning
+	models.LevelInfo,  // 5 notice
+	models.LevelInfo,  // 6 informational
+	models.LevelDebug, // 7 debug
+}
+
+// jsonLevelKeys and logfmtLevelKeys are the field names that carry a level
+var (
+	jsonLevelKeys   = []string{"level", "lvl", "severity", "loglevel"}
+	logfmtLevelKeys = []string{"level", "lvl", "severity"}
+)
+
+// DetectLevel finds the severity of a log line. It recognises, in order: syslog PRI
+// headers ("<34>"), glog prefixes ("E0102 15:04:05"), JSON level fields, logfmt level
+// keys, and level words near the start of the line, either bracketed in any case
+// ("[error]") or bare in upper case ("ERROR"). ok is false if the line has no level.
+func DetectLevel(line []byte) (level models.LogLevel, ok bool) {
+	if len(line) == 0 {
+		return models.LevelUnknown, false
+	}
+
+	if level, ok := syslogLevel(line); ok {
+		return level, true
+	}
+	if level, ok := glogLevel(line); ok {
+		return level, true
+	}
+	if line[0] == '{' {
+		if level, ok := jsonLevel(line); ok {
+			return level, true
+		}
+	}
+	if level, ok := logfmtLevel(line); ok {
+		return level, true
+	}
+	return wordLevel(line)
+}
+
+// syslogLevel reads the severity of a "<PRI>" header
+func syslogLevel(line []byte) (models.LogLevel, bool) {
+	if line[0] != '<' {
+		return "", false
+	}
+
+	end := bytes.IndexByte(line, '>')
+	if end < 2 || end > 4 {
+		return "", false
+	}
+
+	pri, err := strconv.Atoi(string(line[1:end]))
+	if err != nil || pri < 0 || pri > 191 {
+		return "", false
+	}
+	return syslogSeverities[pri%8], true
+}
+
+// glogLevel reads the severity letter of a glog header ("I0102 ...", "E20060102 ...")
+func glogLevel(line []byte) (models.LogLevel, bool) {
+	level, ok := glogLevels[line[0]]
+	if !ok || len(line) < 6 {
+		return "", false
+	}
+
+	for _, c := range line[1:5] {
+		if c < '0' || c > '9' {
+			return "", false
+		}
+	}
+	return level, true
+}
+
+// jsonLevel reads a top-level level field of a JSON object, so a "level" key of a nested
+// object or inside a message string is never mistaken for it. Numeric levels follow the
+// bunyan/pino convention (10 trace ... 60 fatal).
+func jsonLevel(line []byte) (models.LogLevel, bool) {
+	for _, key := range jsonLevelKeys {
+		value, ok := structured.JSONField(line, key)
+		if !ok {
+			continue
+		}
+
+		if len(value) > 0 && value[0] == '"' {
+			if level, ok := levelFromWord(structured.Unquote(value)); ok {
+				return level, true
+			}
+			continue
+		}
+		if number, err := strconv.Atoi(string(value)); err == nil {
+			return levelFromNumber(number), true
+		}
+	}
+	return "", false
+}
+
+// logfmtLevel reads a "level=..." style key
+func logfmtLevel(line []byte) (models.LogLevel, bool) {
+	if bytes.IndexByte(line, '=') < 0 {
+		return "", false
+	}
+
+	for _, key := range logfmtLevelKeys {
+		value, ok := structured.LogfmtField(line, key)
+		if !ok {
+			continue
+		}
+		value = structured.Unquote(value)
+		end := 0
+		for end < len(value) && isLetter(value[end]) {
+			end++
+		}
+		if level, ok := levelFromWord(value[:end]); ok {
+			return level, true
+		}
+	}
+	return "", false
+}
+
+// wordLevel looks for a level word near the start of the line
+func wordLevel(line []byte) (models.LogLevel, bool) {
+	head := line
+	if len(head) > levelSearchWindow {
+		head = head[:levelSearchWindow]
+	}
+
+	for start := 0; start < len(head); {
+		if !isLetter(head[start]) {
+			start++
+			continue
+		}
+
+		end := start
+		for end < len(head) && isLetter(head[end]) {
+			end++
+		}
+		word := head[start:end]
+
+		bracketed := start > 0 && (head[start-1] == '[' || head[start-1] == '<') &&
+			end < len(head) && (head[end] == ']' || head[end] == '>')
+		wordStart := start == 0 || !isWordByte(head[start-1])
+		wordEnd := end == len(head) || !isWordByte(head[end])
+
+		if bracketed || (wordStart && wordEnd && isUpper(word)) {
+			if level, ok := levelFromWord(word); ok {
+				return level, true
+			}
+		}
+		start = end
+	}
+
+	return models.LevelUnknown, false
+}
+
+// levelFromWord normalises a level name
+func levelFromWord(word []byte) (models.LogLevel, bool) {
+	if len(word) < 3 || len(word) > 9 {
+		return "", false
+	}
+	level, ok := levelWords[strings.ToLower(string(word))]
+	return level, ok
+}
+
+// levelFromNumber maps bunyan/pino numeric levels
+func levelFromNumber(number int) models.LogLevel {
+	switch {
+	case number >= 60:
+		return models.LevelFatal
+	case number >= 50:
+		return models.LevelError
+	case number >= 40:
+		return models.LevelWarn
+	case number >= 30:
+		return models.LevelInfo
+	case number >= 20:
+		return models.LevelDebug
+	default:
+		return models.LevelTrace
+	}
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isWordByte(c byte) bool {
+	return isLetter(c) || (c >= '0' && c <= '9') || c == '_'
+}
+
+func isUpper(word []byte) bool {
+	for _, c := range word {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
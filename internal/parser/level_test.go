@@ -0,0 +1,40 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/cheerioskun/logninja/internal/models"
+)
+
+func TestDetectLevel(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		want   models.LogLevel
+		wantOK bool
+	}{
+		{"glog error", "E0102 10:00:00.000000 1234 main.go:10] failed", models.LevelError, true},
+		{"glog info", "I0102 10:00:00.000000 1234 main.go:10] started", models.LevelInfo, true},
+		{"bracketed", "2024-01-02 10:00:00 [WARN] disk almost full", models.LevelWarn, true},
+		{"bare word", "2024-01-02 10:00:00 ERROR connection refused", models.LevelError, true},
+		{"logfmt", `time=2024-01-02T10:00:00Z level=debug msg="cache miss"`, models.LevelDebug, true},
+		{"logfmt quoted", `ts=1704189600 level="warning" msg=slow`, models.LevelWarn, true},
+		{"JSON string", `{"ts":"2024-01-02T10:00:00Z","level":"error","msg":"boom"}`, models.LevelError, true},
+		{"JSON severity", `{"severity":"INFO","message":"ok"}`, models.LevelInfo, true},
+		{"JSON numeric", `{"level":50,"msg":"boom"}`, models.LevelError, true},
+		{"JSON level quoted in message", `{"msg":"saw \"level\":\"error\" upstream","level":"info"}`, models.LevelInfo, true},
+		{"JSON nested level ignored", `{"ts":1,"ctx":{"level":"error"},"msg":"x"}`, "", false},
+		{"syslog priority", "<11>Jan  2 10:00:00 host app: failed", models.LevelError, true},
+		{"no level", "2024-01-02 10:00:00 request handled", "", false},
+		{"empty", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := DetectLevel([]byte(tt.line))
+			if ok != tt.wantOK || (ok && got != tt.want) {
+				t.Errorf("DetectLevel(%q) = %q, %v, want %q, %v", tt.line, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"syscall"
@@ -73,6 +74,13 @@ func (mfs *MmapFileSearcher) mapFile() error {
 		return fmt.Errorf("failed to mmap file: %w", err)
 	}
 
+	// Byte offsets in a compressed or UTF-16 file don't correspond to log lines
+	if err := checkSeekable(data); err != nil {
+		syscall.Munmap(data)
+		file.Close()
+		return err
+	}
+
 	mfs.file = file
 	mfs.data = data
 	mfs.mapped = true
@@ -124,29 +132,15 @@ func (mfs *MmapFileSearcher) extractLineAt(pos int64) (string, int64, int64) {
 	return line, lineStart, lineEnd
 }
 
-// parseTimestampAt extracts and parses timestamp from line at given position
-func (mfs *MmapFileSearcher) parseTimestampAt(pos int64) (time.Time, error) {
-	line, _, _ := mfs.extractLineAt(pos)
-	if line == "" {
-		return time.Time{}, fmt.Errorf("empty line")
-	}
-
-	return mfs.timestampExtractor.ParseTimestamp(line, mfs.bestPattern)
+// size returns the number of mapped bytes
+func (mfs *MmapFileSearcher) size() int64 {
+	return int64(len(mfs.data))
 }
 
 // BinarySearchTimeRange uses binary search on byte positions to find time range boundaries
 // This is O(log n) without any O(n) preprocessing
 func (mfs *MmapFileSearcher) BinarySearchTimeRange(startTime, endTime time.Time) (byteCount int64) {
-	fileSize := int64(len(mfs.data))
-	if fileSize == 0 {
-		return 0
-	}
-
-	// Binary search for start position
-	startPos := mfs.binarySearchTime(startTime, true)
-
-	// Binary search for end position
-	endPos := mfs.binarySearchTime(endTime, false)
+	startPos, endPos := mfs.ByteRangeForTimeRange(startTime, endTime)
 
 	// Calculate byte count
 	if endPos > startPos {
@@ -156,77 +150,53 @@ func (mfs *MmapFileSearcher) BinarySearchTimeRange(startTime, endTime time.Time)
 	return byteCount
 }
 
-// binarySearchTime performs binary search to find byte position for a target time
-// searchStart=true finds first position >= targetTime
-// searchStart=false finds first position > targetTime
-func (mfs *MmapFileSearcher) binarySearchTime(targetTime time.Time, searchStart bool) int64 {
-	left := int64(0)
-	right := int64(len(mfs.data))
-	result := right
-
-	for left < right {
-		mid := left + (right-left)/2
-
-		// Find the actual line containing mid position
-		lineStart := mfs.findLineStart(mid)
-
-		// Parse timestamp from this line
-		timestamp, err := mfs.parseTimestampAt(lineStart)
-		if err != nil {
-			// No valid timestamp, try to move forward to find one
-			nextValidPos := mfs.findNextValidTimestamp(lineStart)
-			if nextValidPos == -1 {
-				// No more valid timestamps, search in left half
-				right = mid
-				continue
-			}
-			lineStart = nextValidPos
-			timestamp, err = mfs.parseTimestampAt(lineStart)
-			if err != nil {
-				right = mid
-				continue
-			}
-		}
+// ForEachLine calls fn with every line (without its newline) in the byte span [startPos, endPos)
+func (mfs *MmapFileSearcher) ForEachLine(startPos, endPos int64, fn func(line []byte) error) error {
+	if endPos > int64(len(mfs.data)) {
+		endPos = int64(len(mfs.data))
+	}
+	if startPos < 0 {
+		startPos = 0
+	}
+	if endPos <= startPos {
+		return nil
+	}
 
-		// Compare timestamps
-		var condition bool
-		if searchStart {
-			condition = !timestamp.Before(targetTime) // timestamp >= targetTime
-		} else {
-			condition = timestamp.After(targetTime) // timestamp > targetTime
+	span := mfs.data[startPos:endPos]
+	for len(span) > 0 {
+		end := bytes.IndexByte(span, '\n')
+		if end < 0 {
+			return fn(span)
 		}
-
-		if condition {
-			result = lineStart
-			right = mid
-		} else {
-			left = mfs.findLineEnd(lineStart) + 1 // Move to next line
+		if err := fn(span[:end]); err != nil {
+			return err
 		}
+		span = span[end+1:]
 	}
-
-	return result
+	return nil
 }
 
-// findNextValidTimestamp searches forward from a position to find the next line with a valid timestamp
-func (mfs *MmapFileSearcher) findNextValidTimestamp(startPos int64) int64 {
-	maxPos := int64(len(mfs.data))
-	pos := startPos
+// ByteRangeForTimeRange returns the half-open byte span [startPos, endPos) covering the lines
+// timestamped within [startTime, endTime]; startPos >= endPos means no line falls in the range
+func (mfs *MmapFileSearcher) ByteRangeForTimeRange(startTime, endTime time.Time) (startPos, endPos int64) {
+	if len(mfs.data) == 0 {
+		return 0, 0
+	}
 
-	for pos < maxPos {
-		lineStart := mfs.findLineStart(pos)
-		lineEnd := mfs.findLineEnd(lineStart)
+	// Binary search for start position
+	startPos = mfs.BinarySearchTime(startTime, true)
 
-		if lineEnd > lineStart {
-			line := string(mfs.data[lineStart:lineEnd])
-			if _, err := mfs.timestampExtractor.ParseTimestamp(line, mfs.bestPattern); err == nil {
-				return lineStart
-			}
-		}
+	// Binary search for end position
+	endPos = mfs.BinarySearchTime(endTime, false)
 
-		pos = lineEnd + 1 // Move to next line
-	}
+	return startPos, endPos
+}
 
-	return -1 // No valid timestamp found
+// BinarySearchTime performs binary search to find byte position for a target time
+// searchStart=true finds first position >= targetTime
+// searchStart=false finds first position > targetTime
+func (mfs *MmapFileSearcher) BinarySearchTime(targetTime time.Time, searchStart bool) int64 {
+	return searchTime(mfs, mfs.timestampExtractor, mfs.bestPattern, targetTime, searchStart)
 }
 
 // Close unmaps the file and releases resources
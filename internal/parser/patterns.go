@@ -0,0 +1,178 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PatternDefinition is a user-defined timestamp format, as read from the config file.
+// If Regex has a capture group, the first group is parsed instead of the whole match.
+// A definition with a Field reads the timestamp from that key of JSON (or logfmt) lines instead
+// of a regex; its Layout is optional.
+type PatternDefinition struct {
+	Name     string `mapstructure:"name" json:"name"`
+	Regex    string `mapstructure:"regex" json:"regex,omitempty"`
+	Layout   string `mapstructure:"layout" json:"layout,omitempty"` // Go time layout, or "unix"
+	Field    string `mapstructure:"field" json:"field,omitempty"`   // JSON key, dots for nesting
+	Format   string `mapstructure:"format" json:"format,omitempty"` // "json" (default) or "logfmt"
+	Priority int    `mapstructure:"priority" json:"priority"`
+}
+
+// Custom patterns are registered once at startup and merged into every extractor created afterwards
+var (
+	customPatternsMu          sync.RWMutex
+	customPatterns            []TimestampPattern
+	customPatternsFingerprint string
+)
+
+// SetCustomPatterns registers user-defined timestamp patterns. Each pattern is also tried
+// anchored to the start of the line, as "<name>_Anchored", ahead of its unanchored form.
+// Lower priorities are tried first; the built-in patterns use -10 to 21 (fields below 0),
+// and custom patterns win ties.
+// Invalid definitions are skipped and reported in the returned error; the valid ones still apply.
+func SetCustomPatterns(defs []PatternDefinition) error {
+	builtin := make(map[string]bool)
+	for _, p := range append(compileFieldPatterns(), compileDefaultPatterns()...) {
+		builtin[p.Name] = true
+	}
+
+	var compiled []TimestampPattern
+	var valid []PatternDefinition
+	var errs []error
+	seen := make(map[string]bool)
+
+	for _, def := range defs {
+		patterns, err := compileCustomPattern(def)
+		if err == nil && (builtin[def.Name] || seen[def.Name]) {
+			err = fmt.Errorf("pattern %q: name already in use", def.Name)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		seen[def.Name] = true
+		compiled = append(compiled, patterns...)
+		valid = append(valid, def)
+	}
+
+	fingerprint := ""
+	if len(valid) > 0 {
+		data, _ := json.Marshal(valid)
+		sum := sha256.Sum256(data)
+		fingerprint = hex.EncodeToString(sum[:8])
+	}
+
+	customPatternsMu.Lock()
+	customPatterns = compiled
+	customPatternsFingerprint = fingerprint
+	customPatternsMu.Unlock()
+
+	return errors.Join(errs...)
+}
+
+// PatternsFingerprint identifies the registered custom patterns, so results cached under a
+// different set can be discarded. It is empty when only the built-in patterns are in use.
+func PatternsFingerprint() string {
+	customPatternsMu.RLock()
+	defer customPatternsMu.RUnlock()
+	return customPatternsFingerprint
+}
+
+// compileCustomPattern validates a definition and returns its anchored and unanchored forms
+func compileCustomPattern(def PatternDefinition) ([]TimestampPattern, error) {
+	if def.Name == "" {
+		return nil, fmt.Errorf("pattern with regex %q has no name", def.Regex)
+	}
+	if def.Field != "" {
+		return compileFieldPattern(def)
+	}
+	if def.Regex == "" || def.Layout == "" {
+		return nil, fmt.Errorf("pattern %q: regex and layout are required", def.Name)
+	}
+
+	regex, err := regexp.Compile(def.Regex)
+	if err != nil {
+		return nil, fmt.Errorf("pattern %q: invalid regex: %w", def.Name, err)
+	}
+	if def.Layout != "unix" && !hasLayoutFields(def.Layout) {
+		return nil, fmt.Errorf("pattern %q: layout %q has no date or time fields", def.Name, def.Layout)
+	}
+
+	pattern := TimestampPattern{
+		Name:     def.Name,
+		Regex:    regex,
+		Layout:   def.Layout,
+		Priority: def.Priority,
+		Custom:   true,
+	}
+	if strings.HasPrefix(def.Regex, "^") {
+		return []TimestampPattern{pattern}, nil
+	}
+
+	anchored := pattern
+	anchored.Name = def.Name + "_Anchored"
+	anchored.Regex = regexp.MustCompile("^(?:" + def.Regex + ")")
+	return []TimestampPattern{anchored, pattern}, nil
+}
+
+// compileFieldPattern validates a definition reading a field of structured lines
+func compileFieldPattern(def PatternDefinition) ([]TimestampPattern, error) {
+	if def.Regex != "" {
+		return nil, fmt.Errorf("pattern %q: regex and field are mutually exclusive", def.Name)
+	}
+
+	format := LineFormat(def.Format)
+	switch format {
+	case "":
+		format = FormatJSON
+	case FormatJSON, FormatLogfmt:
+	default:
+		return nil, fmt.Errorf("pattern %q: unknown format %q", def.Name, def.Format)
+	}
+
+	pattern := fieldPattern(format, def.Field, def.Priority)
+	pattern.Name = def.Name
+	pattern.Custom = true
+
+	switch def.Layout {
+	case "":
+	case "unix":
+		pattern.Layout = def.Layout
+	default:
+		if !hasLayoutFields(def.Layout) {
+			return nil, fmt.Errorf("pattern %q: layout %q has no date or time fields", def.Name, def.Layout)
+		}
+		pattern.Layout = def.Layout
+	}
+	return []TimestampPattern{pattern}, nil
+}
+
+// hasLayoutFields reports whether a Go time layout contains any reference time element.
+// A layout without any formats to itself and only ever parses to the zero time.
+func hasLayoutFields(layout string) bool {
+	probe := time.Date(1999, 12, 31, 23, 58, 59, 987654321, time.FixedZone("XYZ", 3600))
+	return probe.Format(layout) != layout
+}
+
+// mergePatterns combines the built-in patterns with the registered custom ones, ordered by priority
+func mergePatterns(builtin []TimestampPattern) []TimestampPattern {
+	customPatternsMu.RLock()
+	merged := make([]TimestampPattern, 0, len(customPatterns)+len(builtin))
+	merged = append(merged, customPatterns...)
+	customPatternsMu.RUnlock()
+
+	merged = append(merged, builtin...)
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Priority < merged[j].Priority
+	})
+	return merged
+}
@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// inHousePattern is a custom format with a trailing zone name
+var inHousePattern = PatternDefinition{
+	Name:   "InHouse",
+	Regex:  `\[\d{2}/\d{2}/\d{4} \d{2}:\d{2}:\d{2}\.\d{3} [A-Z]{3,4}\]`,
+	Layout: "[02/01/2006 15:04:05.000 MST]",
+}
+
+func TestSetCustomPatterns(t *testing.T) {
+	t.Cleanup(func() { SetCustomPatterns(nil) })
+
+	tests := []struct {
+		name    string
+		def     PatternDefinition
+		wantErr string
+	}{
+		{"valid", inHousePattern, ""},
+		{"missing name", PatternDefinition{Regex: `\d+`, Layout: "unix"}, "has no name"},
+		{"missing layout", PatternDefinition{Name: "NoLayout", Regex: `\d+`}, "regex and layout are required"},
+		{"invalid regex", PatternDefinition{Name: "BadRegex", Regex: `(\d+`, Layout: "unix"}, "invalid regex"},
+		{"layout without fields", PatternDefinition{Name: "NoFields", Regex: `\d+`, Layout: "timestamp"}, "no date or time fields"},
+		{"unknown field format", PatternDefinition{Name: "Stamp", Field: "stamp", Format: "xml"}, "unknown format"},
+		{"built-in name", PatternDefinition{Name: "ISO8601", Regex: `\d+`, Layout: "unix"}, "name already in use"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SetCustomPatterns([]PatternDefinition{tt.def})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("SetCustomPatterns() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("SetCustomPatterns() error = %v, want %q", err, tt.wantErr)
+			}
+			if PatternsFingerprint() != "" {
+				t.Error("an invalid pattern was registered")
+			}
+		})
+	}
+
+	// Valid definitions still apply next to invalid ones, and names must be unique
+	err := SetCustomPatterns([]PatternDefinition{inHousePattern, inHousePattern})
+	if err == nil || !strings.Contains(err.Error(), "name already in use") {
+		t.Errorf("SetCustomPatterns() with a duplicate error = %v", err)
+	}
+	if PatternsFingerprint() == "" {
+		t.Error("the valid pattern was not registered")
+	}
+}
+
+func TestCustomPatternExtraction(t *testing.T) {
+	if err := SetCustomPatterns([]PatternDefinition{inHousePattern}); err != nil {
+		t.Fatalf("SetCustomPatterns() error = %v", err)
+	}
+	t.Cleanup(func() { SetCustomPatterns(nil) })
+
+	extractor := NewTimestampExtractor(nil)
+	for _, name := range []string{"InHouse_Anchored", "InHouse"} {
+		if pattern := extractor.PatternByName(name); pattern == nil || !pattern.Custom {
+			t.Errorf("PatternByName(%q) = %v, want a custom pattern", name, pattern)
+		}
+	}
+
+	want := time.Date(2026, 10, 16, 14, 5, 1, 123000000, time.UTC)
+	lines := []string{
+		"[16/10/2026 14:05:01.123 UTC] worker started",
+		"host-1 [16/10/2026 14:05:01.123 UTC] worker started",
+	}
+	for _, line := range lines {
+		got, err := extractor.ParseTimestamp(line, nil)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("ParseTimestamp(%q) = %v, %v, want %v", line, got, err, want)
+		}
+	}
+
+	result, err := extractor.DetectBestPatternFromReader(strings.NewReader(strings.Repeat(lines[0]+"\n", 10)), "app.log")
+	if err != nil {
+		t.Fatalf("DetectBestPatternFromReader() error = %v", err)
+	}
+	if result.BestPattern == nil || result.BestPattern.Name != "InHouse_Anchored" {
+		t.Errorf("DetectBestPatternFromReader() picked %v, want InHouse_Anchored", result.BestPattern)
+	}
+
+	// Extractors created after the patterns are cleared only know the built-in ones
+	SetCustomPatterns(nil)
+	if PatternsFingerprint() != "" || NewTimestampExtractor(nil).PatternByName("InHouse") != nil {
+		t.Error("custom patterns survived SetCustomPatterns(nil)")
+	}
+}
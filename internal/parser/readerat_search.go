@@ -0,0 +1,247 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	// readerAtBlockSize is the unit in which file contents are read and cached
+	readerAtBlockSize = 32 * 1024
+
+	// readerAtMaxBlocks bounds the block cache; a binary search touches only a few
+	// blocks per probe, so this comfortably holds a whole search
+	readerAtMaxBlocks = 64
+
+	// maxScanLineSize is the longest line ForEachLine can return
+	maxScanLineSize = 16 * 1024 * 1024
+)
+
+// ReaderAtFileSearcher provides the same binary search as MmapFileSearcher on any afero.Fs,
+// reading the blocks around each probe through io.ReaderAt instead of mapping the file
+type ReaderAtFileSearcher struct {
+	filePath           string
+	timestampExtractor *TimestampExtractor
+	bestPattern        *TimestampPattern
+
+	file     afero.File
+	fileSize int64
+
+	// Block cache keyed by block index
+	blocks map[int64][]byte
+}
+
+// NewReaderAtFileSearcher opens a file for binary searching through io.ReaderAt
+func NewReaderAtFileSearcher(fs afero.Fs, filePath string, timestampExtractor *TimestampExtractor, bestPattern *TimestampPattern) (*ReaderAtFileSearcher, error) {
+	file, err := fs.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	searcher := &ReaderAtFileSearcher{
+		filePath:           filePath,
+		timestampExtractor: timestampExtractor,
+		bestPattern:        bestPattern,
+		file:               file,
+		fileSize:           stat.Size(),
+		blocks:             make(map[int64][]byte),
+	}
+
+	// Byte offsets in a compressed or UTF-16 file don't correspond to log lines
+	if err := checkSeekable(searcher.readRange(0, EncodingSniffSize)); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return searcher, nil
+}
+
+// block returns the contents of a block, reading it on first use.
+// Read errors yield a short or empty block, which the search treats as end of data.
+func (rs *ReaderAtFileSearcher) block(index int64) []byte {
+	if data, ok := rs.blocks[index]; ok {
+		return data
+	}
+
+	if len(rs.blocks) >= readerAtMaxBlocks {
+		rs.blocks = make(map[int64][]byte)
+	}
+
+	data := make([]byte, readerAtBlockSize)
+	n, err := rs.file.ReadAt(data, index*readerAtBlockSize)
+	if err != nil && err != io.EOF {
+		n = 0
+	}
+	data = data[:n]
+
+	rs.blocks[index] = data
+	return data
+}
+
+// readRange returns the bytes in [start, end)
+func (rs *ReaderAtFileSearcher) readRange(start, end int64) []byte {
+	if end > rs.fileSize {
+		end = rs.fileSize
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end <= start {
+		return nil
+	}
+
+	result := make([]byte, 0, end-start)
+	for pos := start; pos < end; {
+		index := pos / readerAtBlockSize
+		data := rs.block(index)
+		offset := pos - index*readerAtBlockSize
+		if offset >= int64(len(data)) {
+			break // Short read
+		}
+
+		chunk := data[offset:]
+		if remaining := end - pos; int64(len(chunk)) > remaining {
+			chunk = chunk[:remaining]
+		}
+		result = append(result, chunk...)
+		pos += int64(len(chunk))
+	}
+
+	return result
+}
+
+// size returns the file size in bytes
+func (rs *ReaderAtFileSearcher) size() int64 {
+	return rs.fileSize
+}
+
+// findLineStart rolls back from a byte position to find the start of the current line
+func (rs *ReaderAtFileSearcher) findLineStart(pos int64) int64 {
+	if pos > rs.fileSize {
+		pos = rs.fileSize
+	}
+
+	// Scan backwards block by block for the previous newline
+	for cursor := pos - 1; cursor >= 0; {
+		index := cursor / readerAtBlockSize
+		data := rs.block(index)
+		offset := cursor - index*readerAtBlockSize
+		if offset >= int64(len(data)) {
+			return 0 // Unreadable block
+		}
+
+		if i := bytes.LastIndexByte(data[:offset+1], '\n'); i >= 0 {
+			return index*readerAtBlockSize + int64(i) + 1 // Line starts after the newline
+		}
+		cursor = index*readerAtBlockSize - 1
+	}
+
+	return 0 // Beginning of file
+}
+
+// findLineEnd rolls forward from a byte position to find the end of the current line
+// (excluding the newline)
+func (rs *ReaderAtFileSearcher) findLineEnd(pos int64) int64 {
+	for cursor := pos; cursor < rs.fileSize; {
+		index := cursor / readerAtBlockSize
+		data := rs.block(index)
+		offset := cursor - index*readerAtBlockSize
+		if offset >= int64(len(data)) {
+			return rs.fileSize // Unreadable block
+		}
+
+		if i := bytes.IndexByte(data[offset:], '\n'); i >= 0 {
+			return cursor + int64(i)
+		}
+		cursor = (index + 1) * readerAtBlockSize
+	}
+
+	return rs.fileSize // End of file
+}
+
+// extractLineAt extracts the complete line containing the byte position
+func (rs *ReaderAtFileSearcher) extractLineAt(pos int64) (string, int64, int64) {
+	lineStart := rs.findLineStart(pos)
+	lineEnd := rs.findLineEnd(lineStart)
+
+	if lineStart >= lineEnd {
+		return "", lineStart, lineEnd
+	}
+
+	return string(rs.readRange(lineStart, lineEnd)), lineStart, lineEnd
+}
+
+// BinarySearchTime performs binary search to find byte position for a target time
+func (rs *ReaderAtFileSearcher) BinarySearchTime(targetTime time.Time, searchStart bool) int64 {
+	return searchTime(rs, rs.timestampExtractor, rs.bestPattern, targetTime, searchStart)
+}
+
+// BinarySearchTimeRange uses binary search on byte positions to find time range boundaries
+func (rs *ReaderAtFileSearcher) BinarySearchTimeRange(startTime, endTime time.Time) (byteCount int64) {
+	startPos, endPos := rs.ByteRangeForTimeRange(startTime, endTime)
+	if endPos > startPos {
+		byteCount = endPos - startPos
+	}
+	return byteCount
+}
+
+// ByteRangeForTimeRange returns the half-open byte span [startPos, endPos) covering the lines
+// timestamped within [startTime, endTime]; startPos >= endPos means no line falls in the range
+func (rs *ReaderAtFileSearcher) ByteRangeForTimeRange(startTime, endTime time.Time) (startPos, endPos int64) {
+	if rs.fileSize == 0 {
+		return 0, 0
+	}
+
+	startPos = rs.BinarySearchTime(startTime, true)
+	endPos = rs.BinarySearchTime(endTime, false)
+
+	return startPos, endPos
+}
+
+// ForEachLine calls fn with every line (without its newline) in the byte span [startPos, endPos).
+// The span is streamed rather than cached, since it may cover most of the file.
+func (rs *ReaderAtFileSearcher) ForEachLine(startPos, endPos int64, fn func(line []byte) error) error {
+	if endPos > rs.fileSize {
+		endPos = rs.fileSize
+	}
+	if startPos < 0 {
+		startPos = 0
+	}
+	if endPos <= startPos {
+		return nil
+	}
+
+	scanner := bufio.NewScanner(io.NewSectionReader(rs.file, startPos, endPos-startPos))
+	scanner.Buffer(make([]byte, readerAtBlockSize), maxScanLineSize)
+	for scanner.Scan() {
+		if err := fn(scanner.Bytes()); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// GetFileStats returns the size of the file in bytes
+func (rs *ReaderAtFileSearcher) GetFileStats() int64 {
+	return rs.fileSize
+}
+
+// Close closes the underlying file
+func (rs *ReaderAtFileSearcher) Close() error {
+	rs.blocks = nil
+	if err := rs.file.Close(); err != nil {
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+	return nil
+}
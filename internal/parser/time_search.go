@@ -0,0 +1,141 @@
+package parser
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cheerioskun/logninja/internal/utils"
+	"github.com/spf13/afero"
+)
+
+// TimeSearcher binary searches the lines of an uncompressed, time-ordered log file
+// for the byte offsets where a time range begins and ends
+type TimeSearcher interface {
+	// ByteRangeForTimeRange returns the half-open byte span [startPos, endPos) covering the
+	// lines timestamped within [startTime, endTime]; startPos >= endPos means no line matched
+	ByteRangeForTimeRange(startTime, endTime time.Time) (startPos, endPos int64)
+
+	// BinarySearchTimeRange returns the number of bytes timestamped within [startTime, endTime]
+	BinarySearchTimeRange(startTime, endTime time.Time) int64
+
+	// ForEachLine calls fn with every line (without its newline) in the byte span
+	// [startPos, endPos); the slice is only valid during the call. It stops at the first
+	// error fn returns, and returns it or the error that interrupted reading.
+	ForEachLine(startPos, endPos int64, fn func(line []byte) error) error
+
+	// GetFileStats returns the size of the file in bytes
+	GetFileStats() int64
+
+	// Close releases the underlying file
+	Close() error
+
+	// BinarySearchTime finds the first line at or after (searchStart) or strictly after
+	// targetTime, returning the file size if there is none
+	BinarySearchTime(targetTime time.Time, searchStart bool) int64
+}
+
+// NewTimeSearcher opens a binary searcher for a log file: memory-mapped on the OS
+// filesystem, and reading through io.ReaderAt on any other afero.Fs.
+// Compressed and UTF-16 files cannot be searched by offset and return an error.
+func NewTimeSearcher(fs afero.Fs, filePath string, timestampExtractor *TimestampExtractor, bestPattern *TimestampPattern) (TimeSearcher, error) {
+	timestampExtractor = timestampExtractor.ForFile(filePath)
+	if _, ok := fs.(*afero.OsFs); ok {
+		searcher, err := NewMmapFileSearcher(fs, filePath, timestampExtractor, bestPattern)
+		if err == nil {
+			return searcher, nil
+		}
+		utils.Debug("mmap unavailable for %s, reading through ReaderAt: %v", filePath, err)
+	}
+
+	return NewReaderAtFileSearcher(fs, filePath, timestampExtractor, bestPattern)
+}
+
+// lineSource gives random access to the lines of a file for binary searching
+type lineSource interface {
+	size() int64
+	findLineStart(pos int64) int64
+	findLineEnd(pos int64) int64
+	extractLineAt(pos int64) (string, int64, int64)
+}
+
+// searchTime performs binary search over byte positions to find the offset of a target time.
+// searchStart=true finds the first line with timestamp >= targetTime;
+// searchStart=false finds the first line with timestamp > targetTime.
+// Lines without a timestamp continue the entry above them and are skipped forward to the
+// start of the next entry, so a returned offset never splits an entry.
+func searchTime(source lineSource, extractor *TimestampExtractor, bestPattern *TimestampPattern, targetTime time.Time, searchStart bool) int64 {
+	left := int64(0)
+	right := source.size()
+	result := right
+
+	for left < right {
+		mid := left + (right-left)/2
+
+		// Find the actual line containing mid position
+		lineStart := source.findLineStart(mid)
+
+		// Parse timestamp from this line
+		timestamp, err := parseTimestampAt(source, extractor, bestPattern, lineStart)
+		if err != nil {
+			// No valid timestamp, try to move forward to find one
+			nextValidPos := findNextValidTimestamp(source, extractor, bestPattern, lineStart)
+			if nextValidPos == -1 {
+				// No more valid timestamps, search in left half
+				right = mid
+				continue
+			}
+			lineStart = nextValidPos
+			timestamp, err = parseTimestampAt(source, extractor, bestPattern, lineStart)
+			if err != nil {
+				right = mid
+				continue
+			}
+		}
+
+		// Compare timestamps
+		var condition bool
+		if searchStart {
+			condition = !timestamp.Before(targetTime) // timestamp >= targetTime
+		} else {
+			condition = timestamp.After(targetTime) // timestamp > targetTime
+		}
+
+		if condition {
+			result = lineStart
+			right = mid
+		} else {
+			left = source.findLineEnd(lineStart) + 1 // Move to next line
+		}
+	}
+
+	return result
+}
+
+// parseTimestampAt extracts and parses the timestamp of the line at the given position
+func parseTimestampAt(source lineSource, extractor *TimestampExtractor, bestPattern *TimestampPattern, pos int64) (time.Time, error) {
+	line, _, _ := source.extractLineAt(pos)
+	if line == "" {
+		return time.Time{}, fmt.Errorf("empty line")
+	}
+
+	return extractor.ParseEntryTimestamp(line, bestPattern)
+}
+
+// findNextValidTimestamp searches forward from a position to find the start of the next entry
+func findNextValidTimestamp(source lineSource, extractor *TimestampExtractor, bestPattern *TimestampPattern, startPos int64) int64 {
+	maxPos := source.size()
+	pos := startPos
+
+	for pos < maxPos {
+		line, lineStart, lineEnd := source.extractLineAt(pos)
+		if line != "" {
+			if _, err := extractor.ParseEntryTimestamp(line, bestPattern); err == nil {
+				return lineStart
+			}
+		}
+
+		pos = lineEnd + 1 // Move to next line
+	}
+
+	return -1 // No valid timestamp found
+}
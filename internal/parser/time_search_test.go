@@ -0,0 +1,177 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// openSearchers opens the same content with the memory-mapped searcher on the OS filesystem
+// and the ReaderAt searcher on an in-memory one
+func openSearchers(t *testing.T, content []byte) map[string]TimeSearcher {
+	t.Helper()
+
+	osPath := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(osPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	memFs := afero.NewMemMapFs()
+	memPath := "/bundle/app.log"
+	if err := afero.WriteFile(memFs, memPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	extractor := NewTimestampExtractor(nil)
+	pattern := extractor.PatternByName("DateTime_Dash")
+
+	mmap, err := NewMmapFileSearcher(afero.NewOsFs(), osPath, extractor, pattern)
+	if err != nil {
+		t.Fatalf("NewMmapFileSearcher() error = %v", err)
+	}
+	t.Cleanup(func() { mmap.Close() })
+
+	readerAt, err := NewReaderAtFileSearcher(memFs, memPath, extractor, pattern)
+	if err != nil {
+		t.Fatalf("NewReaderAtFileSearcher() error = %v", err)
+	}
+	t.Cleanup(func() { readerAt.Close() })
+
+	return map[string]TimeSearcher{"mmap": mmap, "readerAt": readerAt}
+}
+
+func TestSearchersAgree(t *testing.T) {
+	content, entries := generateLog(5000) // Spans many ReaderAt blocks
+	searchers := openSearchers(t, content)
+	size := int64(len(content))
+	last := entries[len(entries)-1]
+
+	tests := []struct {
+		name        string
+		target      time.Time
+		searchStart bool
+		want        int64
+	}{
+		{"before the file", testLogStart.Add(-time.Hour), true, 0},
+		{"first entry, at or after", testLogStart, true, 0},
+		{"first entry, strictly after", testLogStart, false, entries[1].offset},
+		{"exact entry with a stack trace", entries[700].time, true, entries[700].offset},
+		{"after an entry with a stack trace", entries[700].time, false, entries[701].offset},
+		{"between entries", entries[1234].time.Add(time.Second), true, entries[1235].offset},
+		{"between entries, strictly after", entries[1234].time.Add(time.Second), false, entries[1235].offset},
+		{"last entry, at or after", last.time, true, last.offset},
+		{"last entry, strictly after", last.time, false, size},
+		{"after the file", last.time.Add(time.Hour), true, size},
+	}
+
+	for _, tt := range tests {
+		for name, searcher := range searchers {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				if got := searcher.BinarySearchTime(tt.target, tt.searchStart); got != tt.want {
+					t.Errorf("BinarySearchTime(%v, %v) = %d, want %d", tt.target, tt.searchStart, got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestSearchersRangesAndLines(t *testing.T) {
+	content, entries := generateLog(3000)
+	searchers := openSearchers(t, content)
+
+	ranges := []struct {
+		name       string
+		start, end time.Time
+	}{
+		{"whole file", testLogStart.Add(-time.Hour), entries[len(entries)-1].time.Add(time.Hour)},
+		{"middle", entries[100].time, entries[2100].time},
+		{"single entry", entries[1400].time, entries[1400].time},
+		{"no entries", entries[10].time.Add(time.Second), entries[10].time.Add(time.Second)},
+	}
+
+	for _, tt := range ranges {
+		t.Run(tt.name, func(t *testing.T) {
+			type result struct {
+				start, end, count int64
+				lines             []string
+			}
+			results := make(map[string]result)
+			for name, searcher := range searchers {
+				start, end := searcher.ByteRangeForTimeRange(tt.start, tt.end)
+				r := result{start: start, end: end, count: searcher.BinarySearchTimeRange(tt.start, tt.end)}
+				err := searcher.ForEachLine(start, end, func(line []byte) error {
+					r.lines = append(r.lines, string(line))
+					return nil
+				})
+				if err != nil {
+					t.Fatalf("%s ForEachLine() error = %v", name, err)
+				}
+				results[name] = r
+			}
+
+			mmap, readerAt := results["mmap"], results["readerAt"]
+			if mmap.start != readerAt.start || mmap.end != readerAt.end || mmap.count != readerAt.count {
+				t.Errorf("mmap range [%d, %d) of %d bytes, readerAt [%d, %d) of %d bytes",
+					mmap.start, mmap.end, mmap.count, readerAt.start, readerAt.end, readerAt.count)
+			}
+			if len(mmap.lines) != len(readerAt.lines) {
+				t.Fatalf("mmap read %d lines, readerAt %d", len(mmap.lines), len(readerAt.lines))
+			}
+			for i := range mmap.lines {
+				if mmap.lines[i] != readerAt.lines[i] {
+					t.Fatalf("line %d: mmap %q, readerAt %q", i, mmap.lines[i], readerAt.lines[i])
+				}
+			}
+		})
+	}
+}
+
+func TestForEachLineErrors(t *testing.T) {
+	content := []byte("2024-01-02 10:00:00 INFO one\n2024-01-02 10:00:01 INFO two\n2024-01-02 10:00:02 INFO three\n")
+	stop := errors.New("stop")
+	for name, searcher := range openSearchers(t, content) {
+		var lines int
+		err := searcher.ForEachLine(0, int64(len(content)), func(line []byte) error {
+			if lines++; lines == 2 {
+				return stop
+			}
+			return nil
+		})
+		if err != stop || lines != 2 {
+			t.Errorf("%s ForEachLine() read %d lines with error %v, want to stop after 2 with %v", name, lines, err, stop)
+		}
+	}
+
+	// Reading errors are returned rather than ending the lines early
+	fs := afero.NewMemMapFs()
+	long := append(bytes.Repeat([]byte("x"), maxScanLineSize+1), '\n')
+	if err := afero.WriteFile(fs, "/bundle/long.log", long, 0644); err != nil {
+		t.Fatal(err)
+	}
+	extractor := NewTimestampExtractor(nil)
+	searcher, err := NewReaderAtFileSearcher(fs, "/bundle/long.log", extractor, extractor.PatternByName("DateTime_Dash"))
+	if err != nil {
+		t.Fatalf("NewReaderAtFileSearcher() error = %v", err)
+	}
+	defer searcher.Close()
+	if err := searcher.ForEachLine(0, int64(len(long)), func([]byte) error { return nil }); !errors.Is(err, bufio.ErrTooLong) {
+		t.Errorf("ForEachLine() over a too long line error = %v, want %v", err, bufio.ErrTooLong)
+	}
+}
+
+func TestTimeSearcherRejectsCompressed(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	gzipHeader := []byte{0x1f, 0x8b, 0x08, 0x00, 0, 0, 0, 0, 0, 0xff}
+	if err := afero.WriteFile(fs, "/bundle/app.log.gz", gzipHeader, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewTimeSearcher(fs, "/bundle/app.log.gz", NewTimestampExtractor(fs), nil); err == nil {
+		t.Error("NewTimeSearcher() opened a compressed file for searching by offset")
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/cheerioskun/logninja/internal/models"
 	"github.com/spf13/afero"
 )
 
@@ -18,22 +20,89 @@ type TimestampPattern struct {
 	Regex    *regexp.Regexp // Compiled regex pattern
 	Layout   string         // Go time layout for parsing
 	Priority int            // Lower number = higher priority
+	Custom   bool           // Defined in the config file rather than built in
+	Field    string         // For structured lines, the key holding the timestamp
+	Format   LineFormat     // For structured lines, how they are laid out
 }
 
 // TimestampExtractor handles timestamp detection and parsing from log files
 type TimestampExtractor struct {
 	patterns []TimestampPattern
 	fs       afero.Fs
+	location *time.Location // Zone of timestamps without an offset
+	display  *time.Location // Zone parsed times are returned in
+
+	// yearAnchor is the file's modification time, used to infer the year of timestamps
+	// logged without one; the current time if unknown
+	yearAnchor time.Time
+
+	// years follows the inferred years through a file read in order, nil unless made by InOrder
+	years *yearTracker
+}
+
+// yearTracker remembers the last timestamp whose year was inferred, so a file read in order
+// moves on to the next year when its months wrap from December to January
+type yearTracker struct {
+	mu   sync.Mutex
+	last time.Time
 }
 
-// NewTimestampExtractor creates a new timestamp extractor with default patterns
+// yearInferenceSlack tolerates timestamps slightly after the file's modification time,
+// such as from clock skew, before assuming they belong to the previous year
+const yearInferenceSlack = 24 * time.Hour
+
+// NewTimestampExtractor creates a new timestamp extractor with the default patterns
+// and any registered with SetCustomPatterns
 func NewTimestampExtractor(fs afero.Fs) *TimestampExtractor {
 	return &TimestampExtractor{
-		patterns: compileDefaultPatterns(),
+		patterns: mergePatterns(append(compileFieldPatterns(), compileDefaultPatterns()...)),
 		fs:       fs,
+		location: LocationForFile(""),
+		display:  models.DisplayLocation(),
 	}
 }
 
+// ForFile returns an extractor reading timestamps without an offset in the zone configured
+// for filePath, and inferring missing years from the file's modification time.
+// It shares the patterns of te, so pattern pointers remain valid.
+func (te *TimestampExtractor) ForFile(filePath string) *TimestampExtractor {
+	perFile := *te
+	perFile.location = LocationForFile(filePath)
+	perFile.yearAnchor = time.Time{}
+	perFile.years = nil
+	if te.fs != nil {
+		if info, err := te.fs.Stat(filePath); err == nil {
+			perFile.yearAnchor = info.ModTime()
+		}
+	}
+	return &perFile
+}
+
+// InOrder returns an extractor for reading one file's lines in order, from start to end.
+// Years inferred for timestamps without one carry on from line to line, so a file that wraps
+// from December to January moves on to the next year. Extractors reading a file out of order,
+// as binary searches do, must not use it.
+func (te *TimestampExtractor) InOrder() *TimestampExtractor {
+	inOrder := *te
+	inOrder.years = &yearTracker{}
+	return &inOrder
+}
+
+// PatternByName returns the pattern with the given name, or nil if there is none
+func (te *TimestampExtractor) PatternByName(name string) *TimestampPattern {
+	for i := range te.patterns {
+		if te.patterns[i].Name == name {
+			return &te.patterns[i]
+		}
+	}
+	return nil
+}
+
+// Patterns returns the patterns the extractor tries, in priority order
+func (te *TimestampExtractor) Patterns() []TimestampPattern {
+	return te.patterns
+}
+
 // compileDefaultPatterns returns pre-compiled regex patterns for timestamp matching
 // Based on the user's existing implementation with optimizations
 func compileDefaultPatterns() []TimestampPattern {
@@ -52,15 +121,15 @@ func compileDefaultPatterns() []TimestampPattern {
 		{"DateTime_Micro", `\d{4}-\d{2}-\d{2}\s+\d{2}:\d{2}:\d{2}\.\d+`, "2006-01-02 15:04:05.000000"},
 		{"DateTime_Milli", `\d{4}-\d{2}-\d{2}\s+\d{2}:\d{2}:\d{2},\d{3}Z?`, "2006-01-02 15:04:05,000Z"},
 
-		// Glog formats
-		{"Glog_Short", `[IWEF]\d{4}\s+\d{2}:\d{2}:\d{2}\.\d+Z?`, "I0102 15:04:05.000000Z"},
-		{"Glog_Long", `[IWEF]\d{8}\s+\d{2}:\d{2}:\d{2}\.\d+Z?`, "I20060102 15:04:05.000000Z"},
+		// Glog formats; the severity letter is left out of the capture so every level parses
+		{"Glog_Short", `[IWEF](\d{4}\s+\d{2}:\d{2}:\d{2}\.\d+)Z?`, "0102 15:04:05.000000"},
+		{"Glog_Long", `[IWEF](\d{8}\s+\d{2}:\d{2}:\d{2}\.\d+)Z?`, "20060102 15:04:05.000000"},
 
 		// Syslog format
-		{"Syslog", `\w{3}\s+\d{1,2}\s+\d{2}:\d{2}:\d{2}`, "Jan 2 15:04:05"},
+		{"Syslog", `\w{3}\s+\d{1,2}\s+\d{2}:\d{2}:\d{2}`, "Jan _2 15:04:05"},
 
 		// Apache log format
-		{"Apache", `\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2}\s+\+\d{4}`, "02/Jan/2006:15:04:05 +0000"},
+		{"Apache", `\d{2}/\w{3}/\d{4}:\d{2}:\d{2}:\d{2}\s+[+-]\d{4}`, "02/Jan/2006:15:04:05 -0700"},
 
 		// Audit log format (Unix timestamp)
 		{"Audit", `msg=audit\((\d{10,19}):\d+\)`, "unix"},
@@ -103,22 +172,77 @@ type PatternDetectionResult struct {
 
 // DetectBestPattern implements the hybrid approach: check first 10 lines,
 // find the pattern with most matches, use it as primary with fallback to all patterns
+// Compressed files are decompressed transparently before sampling.
 func (te *TimestampExtractor) DetectBestPattern(filePath string) (*PatternDetectionResult, error) {
-	file, err := te.fs.Open(filePath)
+	file, _, err := OpenDecompressed(te.fs, filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file %s: %w", filePath, err)
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	lineCount := 0
-	maxLines := 10
+	return te.ForFile(filePath).DetectBestPatternFromReader(file, filePath)
+}
+
+// DetectBestPatternFromReader runs pattern detection over the first lines of an already opened
+// (and decompressed) stream; filePath is only used for error messages
+func (te *TimestampExtractor) DetectBestPatternFromReader(reader io.Reader, filePath string) (*PatternDetectionResult, error) {
+	scores, lineCount, err := te.ScorePatterns(reader, defaultSampleLines)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %s: %w", filePath, err)
+	}
+
+	best := BestScore(scores)
+	if best == nil {
+		return &PatternDetectionResult{
+			BestPattern: nil,
+			MatchCount:  0,
+			Confidence:  0.0,
+		}, nil
+	}
 
-	// Track matches per pattern
-	patternMatches := make(map[int]int)       // pattern index -> match count
-	firstTimestamp := make(map[int]time.Time) // pattern index -> first parsed time
+	return &PatternDetectionResult{
+		BestPattern: best.Pattern,
+		MatchCount:  best.Matches,
+		SampleTime:  best.FirstTime,
+		Confidence:  float64(best.Matches) / float64(lineCount),
+	}, nil
+}
+
+// defaultSampleLines is how many non-empty lines pattern detection samples
+const defaultSampleLines = 10
 
-	for scanner.Scan() && lineCount < maxLines {
+// PatternScore is how one pattern fared on a sample of lines
+type PatternScore struct {
+	Pattern   *TimestampPattern
+	Matches   int       // Lines the regex matched
+	Parsed    int       // Matched lines whose timestamp also parsed
+	FirstTime time.Time // First parsed timestamp, zero if none parsed
+}
+
+// BestScore returns the pattern that matched the most lines, or nil if none matched.
+// Scores are in priority order, so ties go to the higher priority pattern.
+func BestScore(scores []PatternScore) *PatternScore {
+	var best *PatternScore
+	for i := range scores {
+		if scores[i].Matches > 0 && (best == nil || scores[i].Matches > best.Matches) {
+			best = &scores[i]
+		}
+	}
+	return best
+}
+
+// ScorePatterns tests every pattern against the first maxLines non-empty lines of a stream.
+// Scores are returned in priority order, one per pattern, along with the number of lines sampled.
+func (te *TimestampExtractor) ScorePatterns(reader io.Reader, maxLines int) ([]PatternScore, int, error) {
+	scores := make([]PatternScore, len(te.patterns))
+	for i := range te.patterns {
+		scores[i].Pattern = &te.patterns[i]
+	}
+
+	scanner := bufio.NewScanner(reader)
+	lineCount := 0
+
+	for lineCount < maxLines && scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 		if line == "" {
 			continue
@@ -127,71 +251,75 @@ func (te *TimestampExtractor) DetectBestPattern(filePath string) (*PatternDetect
 		lineCount++
 
 		// Test each pattern against this line
-		for i, pattern := range te.patterns {
-			if pattern.Regex.MatchString(line) {
-				patternMatches[i]++
-
-				// Try to parse the timestamp to verify it's valid
-				if timestamp, err := te.parseTimestampWithPattern(line, &pattern); err == nil {
-					if _, exists := firstTimestamp[i]; !exists {
-						firstTimestamp[i] = timestamp
-					}
+		for i := range te.patterns {
+			if !te.patterns[i].matchesLine(line) {
+				continue
+			}
+			scores[i].Matches++
+
+			// Try to parse the timestamp to verify it's valid
+			if timestamp, err := te.parseTimestampWithPattern(line, &te.patterns[i]); err == nil {
+				if scores[i].Parsed == 0 {
+					scores[i].FirstTime = timestamp
 				}
+				scores[i].Parsed++
 			}
 		}
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading file %s: %w", filePath, err)
+		return nil, lineCount, err
 	}
 
-	// Find the pattern with the most matches
-	bestPatternIndex := -1
-	maxMatches := 0
+	return scores, lineCount, nil
+}
 
-	for patternIndex, matches := range patternMatches {
-		if matches > maxMatches {
-			maxMatches = matches
-			bestPatternIndex = patternIndex
+// SampleLines returns the first n non-empty lines of data, trimmed, as ScorePatterns samples them
+func SampleLines(data []byte, n int) []string {
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if len(lines) == n {
+			break
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
 		}
 	}
-
-	if bestPatternIndex == -1 {
-		return &PatternDetectionResult{
-			BestPattern: nil,
-			MatchCount:  0,
-			Confidence:  0.0,
-		}, nil
-	}
-
-	bestPattern := &te.patterns[bestPatternIndex]
-	confidence := float64(maxMatches) / float64(lineCount)
-	sampleTime := firstTimestamp[bestPatternIndex]
-
-	return &PatternDetectionResult{
-		BestPattern: bestPattern,
-		MatchCount:  maxMatches,
-		SampleTime:  sampleTime,
-		Confidence:  confidence,
-	}, nil
+	return lines
 }
 
 // parseTimestampWithPattern extracts and parses a timestamp from a line using a specific pattern
 func (te *TimestampExtractor) parseTimestampWithPattern(line string, pattern *TimestampPattern) (time.Time, error) {
-	matches := pattern.Regex.FindStringSubmatch(line)
-	if len(matches) == 0 {
+	if pattern.Field != "" {
+		timestamp, err := te.parseFieldTimestamp(line, pattern)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return timestamp.In(te.display), nil
+	}
+
+	indexes := pattern.Regex.FindStringSubmatchIndex(line)
+	if indexes == nil {
 		return time.Time{}, fmt.Errorf("no timestamp match found")
 	}
 
-	timestampStr := matches[0]
+	// A capture group narrows the match down to the timestamp itself
+	start, end := indexes[0], indexes[1]
+	if len(indexes) > 3 && indexes[3] > indexes[2] {
+		start, end = indexes[2], indexes[3]
+	}
 
-	// Handle special cases
-	switch pattern.Layout {
-	case "unix":
-		// Extract Unix timestamp from audit log format
-		if len(matches) > 1 {
-			timestampStr = matches[1] // Use the captured group
-		}
+	timestamp, err := te.parseMatch(line[start:end], line[end:], pattern.Layout)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return timestamp.In(te.display), nil
+}
+
+// parseMatch parses a matched timestamp with a layout. rest is the remainder of the line,
+// which may state the zone of a timestamp whose pattern doesn't include one.
+func (te *TimestampExtractor) parseMatch(timestampStr, rest, layout string) (time.Time, error) {
+	if layout == "unix" {
 		if unixTime, err := strconv.ParseInt(timestampStr, 10, 64); err == nil {
 			// Handle different Unix timestamp precisions
 			switch len(timestampStr) {
@@ -206,30 +334,89 @@ func (te *TimestampExtractor) parseTimestampWithPattern(line string, pattern *Ti
 			}
 		}
 		return time.Time{}, fmt.Errorf("invalid unix timestamp: %s", timestampStr)
+	}
 
-	case "Jan 2 15:04:05": // Syslog format needs year
-		// Add current year for syslog timestamps
-		currentYear := time.Now().Year()
-		timestampWithYear := fmt.Sprintf("%d %s", currentYear, timestampStr)
-		return time.Parse("2006 Jan 2 15:04:05", timestampWithYear)
-
-	default:
-		// Handle microsecond precision by trying multiple layouts
-		layouts := []string{
-			pattern.Layout,
-			strings.Replace(pattern.Layout, ".000000", ".000000000", 1), // nanoseconds
-			strings.Replace(pattern.Layout, ".000000", ".000", 1),       // milliseconds
-			strings.Replace(pattern.Layout, ".000000", "", 1),           // no sub-seconds
+	// Timestamps without an offset are in the file's zone unless a "Z", offset or zone name
+	// follows them. A layout's trailing "Z" is a literal marking UTC, present or not.
+	location := te.location
+	if trimmed, ok := strings.CutSuffix(layout, "Z"); ok {
+		layout = trimmed
+		if trimmed, ok := strings.CutSuffix(timestampStr, "Z"); ok {
+			timestampStr = trimmed
+			location = time.UTC
+		} else if zone, ok := explicitZone(rest); ok {
+			location = zone
 		}
+	} else if zone, ok := explicitZone(rest); ok {
+		location = zone
+	}
 
-		for _, layout := range layouts {
-			if parsed, err := time.Parse(layout, timestampStr); err == nil {
-				return parsed, nil
+	// Handle microsecond precision by trying multiple layouts
+	layouts := []string{
+		layout,
+		strings.Replace(layout, ".000000", ".000000000", 1), // nanoseconds
+		strings.Replace(layout, ".000000", ".000", 1),       // milliseconds
+		strings.Replace(layout, ".000000", "", 1),           // no sub-seconds
+	}
+
+	for _, candidate := range layouts {
+		if parsed, err := time.ParseInLocation(candidate, timestampStr, location); err == nil {
+			if parsed.Year() == 0 { // Layout has no year (syslog, glog)
+				parsed = te.inferYear(parsed)
 			}
+			return parsed, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("failed to parse timestamp: %s with layout: %s", timestampStr, layout)
+}
+
+// inferYear places a timestamp logged without a year in the latest year that doesn't put it
+// after the file's modification time (plus yearInferenceSlack); February 29 goes to the latest
+// leap year. Read in order (see InOrder), a January line after a December one moves on to the
+// next year, and the lines after it stay there, even past the modification time.
+func (te *TimestampExtractor) inferYear(t time.Time) time.Time {
+	anchor := te.yearAnchor
+	if anchor.IsZero() {
+		anchor = time.Now()
+	}
+	anchor = anchor.In(t.Location())
+	limit := anchor.Add(yearInferenceSlack)
+
+	// Years in which the date doesn't exist are skipped, leap years are at most 8 apart
+	inYear := func(year int) (time.Time, bool) {
+		inferred := time.Date(year, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+		return inferred, inferred.Day() == t.Day()
+	}
+
+	var inferred time.Time
+	for year := anchor.Year(); year > anchor.Year()-9; year-- {
+		if candidate, ok := inYear(year); ok && !candidate.After(limit) {
+			inferred = candidate
+			break
 		}
 	}
+	if te.years == nil {
+		return inferred
+	}
 
-	return time.Time{}, fmt.Errorf("failed to parse timestamp: %s with layout: %s", timestampStr, pattern.Layout)
+	te.years.mu.Lock()
+	defer te.years.mu.Unlock()
+	last := te.years.last
+	switch {
+	case last.IsZero():
+	case last.Month() == time.December && t.Month() == time.January && inferred.Year() <= last.Year():
+		if candidate, ok := inYear(last.Year() + 1); ok {
+			inferred = candidate
+		}
+	case last.After(limit) && t.Month() >= last.Month():
+		// Still in the year the file wrapped into
+		if candidate, ok := inYear(last.Year()); ok {
+			inferred = candidate
+		}
+	}
+	te.years.last = inferred
+	return inferred
 }
 
 // ParseTimestamp extracts a timestamp from a line using the hybrid approach:
@@ -252,7 +439,34 @@ func (te *TimestampExtractor) ParseTimestamp(line string, bestPattern *Timestamp
 	return time.Time{}, fmt.Errorf("no timestamp found in line")
 }
 
-// FindLineWithTimestamp searches for the first line in a reader that contains a valid timestamp
+// ParseEntryTimestamp extracts the timestamp of a line that starts a log entry. Continuation
+// lines (stack frames, wrapped text) belong to the entry above them and have no timestamp
+// of their own, even when they quote one.
+func (te *TimestampExtractor) ParseEntryTimestamp(line string, bestPattern *TimestampPattern) (time.Time, error) {
+	if !te.startsEntry([]byte(line), bestPattern) {
+		return time.Time{}, fmt.Errorf("line continues the entry above it")
+	}
+	return te.ParseTimestamp(line, bestPattern)
+}
+
+// startsEntry returns true if the line has a timestamp in any known format, best pattern
+// first, in its header: not a continuation line, and not a timestamp quoted in a message
+func (te *TimestampExtractor) startsEntry(line []byte, bestPattern *TimestampPattern) bool {
+	if IsContinuationLine(line) {
+		return false
+	}
+	if bestPattern != nil && headerMatch(line, bestPattern) {
+		return true
+	}
+	for i := range te.patterns {
+		if headerMatch(line, &te.patterns[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindLineWithTimestamp searches for the first line in a reader that starts an entry with a valid timestamp
 // Used for finding earliest/latest timestamps efficiently
 func (te *TimestampExtractor) FindLineWithTimestamp(reader io.Reader, bestPattern *TimestampPattern, maxLines int) (time.Time, string, error) {
 	scanner := bufio.NewScanner(reader)
@@ -262,7 +476,7 @@ func (te *TimestampExtractor) FindLineWithTimestamp(reader io.Reader, bestPatter
 		line := scanner.Text()
 		lineCount++
 
-		if timestamp, err := te.ParseTimestamp(line, bestPattern); err == nil {
+		if timestamp, err := te.ParseEntryTimestamp(line, bestPattern); err == nil {
 			return timestamp, line, nil
 		}
 	}
@@ -0,0 +1,223 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFieldTimestamps(t *testing.T) {
+	extractor := NewTimestampExtractor(nil)
+
+	tests := []struct {
+		name    string
+		pattern string
+		line    string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name:    "JSON RFC 3339",
+			pattern: "JSON_ts",
+			line:    `{"level":"info","ts":"2024-01-02T10:00:00.250Z","msg":"ok"}`,
+			want:    time.Date(2024, 1, 2, 10, 0, 0, 250e6, time.UTC),
+		},
+		{
+			name:    "JSON with offset",
+			pattern: "JSON_timestamp",
+			line:    `{"timestamp":"2024-01-02T12:00:00+02:00"}`,
+			want:    time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "JSON epoch seconds",
+			pattern: "JSON_ts",
+			line:    `{"ts":1704189600,"msg":"ok"}`,
+			want:    time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "JSON fractional epoch seconds",
+			pattern: "JSON_ts",
+			line:    `{"ts":1704189600.5,"msg":"ok"}`,
+			want:    time.Date(2024, 1, 2, 10, 0, 0, 500e6, time.UTC),
+		},
+		{
+			name:    "JSON epoch milliseconds",
+			pattern: "JSON_time",
+			line:    `{"time":1704189600123}`,
+			want:    time.Date(2024, 1, 2, 10, 0, 0, 123e6, time.UTC),
+		},
+		{
+			name:    "JSON epoch microseconds",
+			pattern: "JSON_time",
+			line:    `{"time":1704189600123456}`,
+			want:    time.Date(2024, 1, 2, 10, 0, 0, 123456e3, time.UTC),
+		},
+		{
+			name:    "JSON epoch nanoseconds",
+			pattern: "JSON_time",
+			line:    `{"time":1704189600123456789}`,
+			want:    time.Date(2024, 1, 2, 10, 0, 0, 123456789, time.UTC),
+		},
+		{
+			name:    "JSON quoted epoch",
+			pattern: "JSON_ts",
+			line:    `{"ts":"1704189600"}`,
+			want:    time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "JSON text format",
+			pattern: "JSON_@timestamp",
+			line:    `{"@timestamp":"2024/01/02 10:00:00","msg":"ok"}`,
+			want:    time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "JSON field not at the top level",
+			pattern: "JSON_ts",
+			line:    `{"msg":"ok","request":{"ts":1704189600}}`,
+			wantErr: true,
+		},
+		{
+			name:    "logfmt",
+			pattern: "Logfmt_time",
+			line:    `time=2024-01-02T10:00:00Z level=info msg="request handled"`,
+			want:    time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "logfmt quoted",
+			pattern: "Logfmt_ts",
+			line:    `level=info ts="2024-01-02 10:00:00.5" msg=ok`,
+			want:    time.Date(2024, 1, 2, 10, 0, 0, 500e6, time.UTC),
+		},
+		{
+			name:    "logfmt epoch",
+			pattern: "Logfmt_t",
+			line:    `t=1704189600 lvl=info`,
+			want:    time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "logfmt key inside a value",
+			pattern: "Logfmt_ts",
+			line:    `level=info msg="ts=1704189600"`,
+			wantErr: true,
+		},
+		{
+			name:    "audit epoch",
+			pattern: "Audit",
+			line:    `type=SYSCALL msg=audit(1704189600:42): arch=c000003e`,
+			want:    time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pattern := extractor.PatternByName(tt.pattern)
+			if pattern == nil {
+				t.Fatalf("no pattern %q", tt.pattern)
+			}
+
+			got, err := extractor.parseTimestampWithPattern(tt.line, pattern)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseTimestampWithPattern(%q) = %v, want error", tt.line, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTimestampWithPattern(%q) error = %v", tt.line, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseTimestampWithPattern(%q) = %v, want %v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInferYear(t *testing.T) {
+	tests := []struct {
+		name     string
+		anchor   time.Time // The file's modification time
+		previous []string  // Lines read before, in order
+		line     string
+		want     time.Time
+	}{
+		{
+			name:   "same year",
+			anchor: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			line:   "Mar  4 10:00:00 host app: started",
+			want:   time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "December line of a file modified in January",
+			anchor: time.Date(2025, 1, 2, 8, 0, 0, 0, time.UTC),
+			line:   "Dec 31 23:59:59 host app: last of the year",
+			want:   time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC),
+		},
+		{
+			name:   "January line of a file modified in January",
+			anchor: time.Date(2025, 1, 2, 8, 0, 0, 0, time.UTC),
+			line:   "Jan  1 00:00:01 host app: first of the year",
+			want:   time.Date(2025, 1, 1, 0, 0, 1, 0, time.UTC),
+		},
+		{
+			name:   "clock skew within the slack",
+			anchor: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			line:   "Jun  1 12:00:00 host app: ahead of the file",
+			want:   time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "glog across new year",
+			anchor: time.Date(2025, 1, 1, 0, 5, 0, 0, time.UTC),
+			line:   "I1231 23:58:00.000000 1234 main.go:10] tick",
+			want:   time.Date(2024, 12, 31, 23, 58, 0, 0, time.UTC),
+		},
+		{
+			name:   "leap day skips years without one",
+			anchor: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+			line:   "Feb 29 10:00:00 host app: leap day",
+			want:   time.Date(2024, 2, 29, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "January after December moves on to the next year",
+			anchor:   time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC),
+			previous: []string{"Dec 29 23:00:00 host app: before the new year"},
+			line:     "Jan  2 10:00:00 host app: after the modification time",
+			want:     time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:   "lines after the wrap stay in the new year",
+			anchor: time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC),
+			previous: []string{
+				"Dec 29 23:00:00 host app: before the new year",
+				"Jan  2 10:00:00 host app: after the modification time",
+			},
+			line: "Mar  3 10:00:00 host app: still going",
+			want: time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "January before December stays in its year",
+			anchor:   time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC),
+			previous: []string{"Jan  2 10:00:00 host app: start of the year"},
+			line:     "Dec 29 23:00:00 host app: end of the year",
+			want:     time.Date(2024, 12, 29, 23, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extractor := NewTimestampExtractor(nil).InOrder()
+			extractor.yearAnchor = tt.anchor
+			for _, line := range tt.previous {
+				if _, err := extractor.ParseTimestamp(line, nil); err != nil {
+					t.Fatalf("ParseTimestamp(%q) error = %v", line, err)
+				}
+			}
+
+			got, err := extractor.ParseTimestamp(tt.line, nil)
+			if err != nil {
+				t.Fatalf("ParseTimestamp(%q) error = %v", tt.line, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseTimestamp(%q) = %v, want %v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
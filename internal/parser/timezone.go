@@ -0,0 +1,217 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ZoneOverride assigns a timezone to the files whose path matches Glob. A glob without a slash
+// is matched against the file name, otherwise against the trailing segments of the path
+// (so "var/log/*" matches ".../host1/var/log/messages").
+type ZoneOverride struct {
+	Glob string `mapstructure:"glob" json:"glob"`
+	Zone string `mapstructure:"zone" json:"zone"`
+}
+
+// TimezoneConfig sets how timestamps without an explicit offset are interpreted
+type TimezoneConfig struct {
+	Default   string         `mapstructure:"default" json:"default"` // Zone for all other files, UTC if empty
+	Overrides []ZoneOverride `mapstructure:"overrides" json:"overrides"`
+}
+
+// zoneRule is a validated ZoneOverride
+type zoneRule struct {
+	glob     string
+	location *time.Location
+}
+
+// Timezone settings are registered once at startup, like custom patterns
+var (
+	zonesMu          sync.RWMutex
+	defaultLocation  = time.UTC
+	zoneRules        []zoneRule
+	zonesFingerprint string
+)
+
+// SetTimezones registers the default timezone and per-file overrides. Invalid overrides are
+// skipped and reported in the returned error; an invalid default leaves UTC in place.
+func SetTimezones(config TimezoneConfig) error {
+	var errs []error
+
+	location := time.UTC
+	if config.Default != "" {
+		loaded, err := LoadZone(config.Default)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("default timezone: %w", err))
+		} else {
+			location = loaded
+		}
+	}
+
+	var rules []zoneRule
+	fingerprint := []string{location.String()}
+	for _, override := range config.Overrides {
+		if _, err := path.Match(override.Glob, ""); err != nil || override.Glob == "" {
+			errs = append(errs, fmt.Errorf("timezone override %q: invalid glob", override.Glob))
+			continue
+		}
+		loaded, err := LoadZone(override.Zone)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("timezone override %q: %w", override.Glob, err))
+			continue
+		}
+		rules = append(rules, zoneRule{glob: override.Glob, location: loaded})
+		fingerprint = append(fingerprint, override.Glob+"="+loaded.String())
+	}
+
+	zonesMu.Lock()
+	defaultLocation = location
+	zoneRules = rules
+	zonesFingerprint = ""
+	if len(rules) > 0 || location != time.UTC {
+		zonesFingerprint = strings.Join(fingerprint, ";")
+	}
+	zonesMu.Unlock()
+
+	return errors.Join(errs...)
+}
+
+// TimezonesFingerprint identifies the registered timezone settings, so results cached under
+// different ones can be discarded. It is empty when every file is read as UTC.
+func TimezonesFingerprint() string {
+	zonesMu.RLock()
+	defer zonesMu.RUnlock()
+	return zonesFingerprint
+}
+
+// LocationForFile returns the zone timestamps without an offset are read in for a file:
+// that of the first matching override, or the default
+func LocationForFile(filePath string) *time.Location {
+	zonesMu.RLock()
+	defer zonesMu.RUnlock()
+
+	for _, rule := range zoneRules {
+		if matchPathGlob(rule.glob, filePath) {
+			return rule.location
+		}
+	}
+	return defaultLocation
+}
+
+// matchPathGlob matches a glob against a file name, or against the trailing segments of the
+// path if the glob contains a slash
+func matchPathGlob(glob, filePath string) bool {
+	filePath = filepath.ToSlash(filePath)
+	if !strings.Contains(glob, "/") {
+		matched, _ := path.Match(glob, path.Base(filePath))
+		return matched
+	}
+
+	segments := strings.Split(strings.TrimPrefix(filePath, "/"), "/")
+	for i := range segments {
+		if matched, _ := path.Match(glob, strings.Join(segments[i:], "/")); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// utcOffsetPattern matches fixed offsets such as "+05:30" or "-0800"
+var utcOffsetPattern = regexp.MustCompile(`^[+-]\d{2}:?\d{2}$`)
+
+// LoadZone resolves a zone name: an IANA name ("Europe/Berlin"), "UTC", "Local",
+// or a fixed offset ("+05:30")
+func LoadZone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if utcOffsetPattern.MatchString(name) {
+		offset, ok := parseUTCOffset(name)
+		if !ok {
+			return nil, fmt.Errorf("invalid UTC offset %q", name)
+		}
+		return time.FixedZone(name, offset), nil
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	return location, nil
+}
+
+// parseUTCOffset converts "+hh:mm", "+hhmm" or "+hh" into seconds east of UTC
+func parseUTCOffset(text string) (int, bool) {
+	if len(text) < 3 || (text[0] != '+' && text[0] != '-') {
+		return 0, false
+	}
+	digits := strings.ReplaceAll(text[1:], ":", "")
+	if len(digits) != 2 && len(digits) != 4 {
+		return 0, false
+	}
+
+	hours, err := strconv.Atoi(digits[:2])
+	if err != nil || hours > 14 {
+		return 0, false
+	}
+	minutes := 0
+	if len(digits) == 4 {
+		if minutes, err = strconv.Atoi(digits[2:]); err != nil || minutes > 59 {
+			return 0, false
+		}
+	}
+
+	offset := hours*3600 + minutes*60
+	if text[0] == '-' {
+		offset = -offset
+	}
+	return offset, true
+}
+
+// zoneAbbreviations are the zone names recognised after a timestamp. Abbreviations are
+// ambiguous in general; these are their most common meanings.
+var zoneAbbreviations = map[string]int{
+	"UTC": 0, "GMT": 0, "Z": 0,
+	"EST": -5 * 3600, "EDT": -4 * 3600,
+	"CST": -6 * 3600, "CDT": -5 * 3600,
+	"MST": -7 * 3600, "MDT": -6 * 3600,
+	"PST": -8 * 3600, "PDT": -7 * 3600,
+	"BST": 1 * 3600, "CET": 1 * 3600, "CEST": 2 * 3600,
+	"EET": 2 * 3600, "EEST": 3 * 3600,
+	"IST": 5*3600 + 1800, "JST": 9 * 3600,
+	"AEST": 10 * 3600, "AEDT": 11 * 3600,
+}
+
+// zoneSuffixPattern matches an explicit zone directly after a timestamp:
+// "Z", "+02:00", " -0500", " UTC", " CEST"
+var zoneSuffixPattern = regexp.MustCompile(`^(?:(Z)|\s?([+-]\d{2}:?\d{2})|\s([A-Z]{1,4}))(?:$|[^\w:+-])`)
+
+// explicitZone returns the zone stated right after a timestamp, if any
+func explicitZone(rest string) (*time.Location, bool) {
+	matches := zoneSuffixPattern.FindStringSubmatch(rest)
+	if matches == nil {
+		return nil, false
+	}
+
+	switch {
+	case matches[1] != "":
+		return time.UTC, true
+	case matches[2] != "":
+		if offset, ok := parseUTCOffset(matches[2]); ok {
+			return time.FixedZone(matches[2], offset), true
+		}
+	case matches[3] != "":
+		if offset, ok := zoneAbbreviations[matches[3]]; ok {
+			if offset == 0 {
+				return time.UTC, true
+			}
+			return time.FixedZone(matches[3], offset), true
+		}
+	}
+	return nil, false
+}
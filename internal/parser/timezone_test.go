@@ -0,0 +1,123 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExplicitZones(t *testing.T) {
+	extractor := NewTimestampExtractor(nil)
+	extractor.location = time.FixedZone("+01:00", 3600) // The file's zone, for lines without one
+
+	tests := []struct {
+		name string
+		line string
+		want time.Time
+	}{
+		{"no zone uses the file's", "2024-01-02 10:00:00 started", time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)},
+		{"Z suffix", "2024-01-02T10:00:00Z started", time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)},
+		{"colon offset", "2024-01-02T10:00:00+02:00 started", time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC)},
+		{"spaced offset", "2024-01-02 10:00:00 -0500 started", time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)},
+		{"abbreviation", "2024-01-02 10:00:00 CEST started", time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC)},
+		{"UTC abbreviation", "2024-01-02 10:00:00 UTC started", time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)},
+		{"unknown abbreviation uses the file's", "2024-01-02 10:00:00 XYZ started", time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)},
+		{"upper-case word is not a zone", "2024-01-02 10:00:00 INFO started", time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)},
+		{"apache offset", `127.0.0.1 - - [02/Jan/2024:10:00:00 +0530] "GET / HTTP/1.1" 200`, time.Date(2024, 1, 2, 4, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractor.ParseTimestamp(tt.line, nil)
+			if err != nil {
+				t.Fatalf("ParseTimestamp(%q) error = %v", tt.line, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseTimestamp(%q) = %v, want %v", tt.line, got, tt.want.In(got.Location()))
+			}
+		})
+	}
+}
+
+func TestZoneOverrides(t *testing.T) {
+	err := SetTimezones(TimezoneConfig{
+		Default: "+02:00",
+		Overrides: []ZoneOverride{
+			{Glob: "*.utc.log", Zone: "UTC"},
+			{Glob: "var/log/*", Zone: "-05:00"},
+			{Glob: "[", Zone: "UTC"},           // Invalid glob, skipped
+			{Glob: "*.log", Zone: "Mars/Base"}, // Unknown zone, skipped
+		},
+	})
+	t.Cleanup(func() { SetTimezones(TimezoneConfig{}) })
+	if err == nil {
+		t.Error("SetTimezones() accepted invalid overrides")
+	}
+	if TimezonesFingerprint() == "" {
+		t.Error("TimezonesFingerprint() is empty with a default zone and overrides set")
+	}
+
+	line := "2024-01-02 10:00:00 started"
+	tests := []struct {
+		name string
+		path string
+		want time.Time
+	}{
+		{"default", "/bundle/app/app.log", time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC)},
+		{"name glob", "/bundle/app/api.utc.log", time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)},
+		{"path glob", "/bundle/host1/var/log/messages", time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)},
+		{"path glob needs a whole segment", "/bundle/host1/myvar/log/messages", time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC)},
+		{"first matching override wins", "/bundle/var/log/api.utc.log", time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)},
+	}
+
+	extractor := NewTimestampExtractor(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := extractor.ForFile(tt.path).ParseTimestamp(line, nil)
+			if err != nil {
+				t.Fatalf("ParseTimestamp(%q) error = %v", line, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseTimestamp(%q) in %s = %v, want %v", line, tt.path, got, tt.want)
+			}
+		})
+	}
+
+	// Explicit offsets win over any configured zone
+	got, err := extractor.ForFile("/bundle/var/log/messages").ParseTimestamp("2024-01-02T10:00:00Z started", nil)
+	if err != nil || !got.Equal(time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("ParseTimestamp() with explicit zone = %v, %v, want 10:00 UTC", got, err)
+	}
+}
+
+func TestLoadZone(t *testing.T) {
+	tests := []struct {
+		name       string
+		wantOffset int
+		wantErr    bool
+	}{
+		{"UTC", 0, false},
+		{"+05:30", 5*3600 + 1800, false},
+		{"-0800", -8 * 3600, false},
+		{"+15:00", 0, true},
+		{"Nowhere/Special", 0, true},
+	}
+
+	reference := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location, err := LoadZone(tt.name)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("LoadZone(%q) = %v, want error", tt.name, location)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadZone(%q) error = %v", tt.name, err)
+			}
+			if _, offset := reference.In(location).Zone(); offset != tt.wantOffset {
+				t.Errorf("LoadZone(%q) offset = %d, want %d", tt.name, offset, tt.wantOffset)
+			}
+		})
+	}
+}
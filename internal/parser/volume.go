@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/cheerioskun/logninja/internal/models"
@@ -28,6 +29,11 @@ func NewVolumeAnalyzer(fs afero.Fs) *VolumeAnalyzer {
 // 3. For each bin, use efficient scanning to count lines within time boundaries
 // 4. Return volume data for histogram display
 func (va *VolumeAnalyzer) GenerateHistogram(workingSet *models.WorkingSet, binCount int) ([]models.VolumePoint, error) {
+	return va.GenerateHistogramContext(context.Background(), workingSet, binCount)
+}
+
+// GenerateHistogramContext is GenerateHistogram with cancellation support
+func (va *VolumeAnalyzer) GenerateHistogramContext(ctx context.Context, workingSet *models.WorkingSet, binCount int) ([]models.VolumePoint, error) {
 	if workingSet == nil {
 		return nil, fmt.Errorf("working set cannot be nil")
 	}
@@ -36,7 +42,7 @@ func (va *VolumeAnalyzer) GenerateHistogram(workingSet *models.WorkingSet, binCo
 		binCount = 20 // Default bin count
 	}
 
-	return va.histogramBuilder.BuildHistogram(workingSet, binCount)
+	return va.histogramBuilder.BuildHistogramContext(ctx, workingSet, binCount)
 }
 
 // UpdateWorkingSetVolumeData updates the VolumeData field in a working set
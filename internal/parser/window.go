@@ -0,0 +1,92 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// ByteRange is a half-open span [Start, End) of bytes within a log file.
+// For compressed files offsets refer to the decompressed content.
+type ByteRange struct {
+	Start int64
+	End   int64
+}
+
+// Len returns the number of bytes in the range
+func (br ByteRange) Len() int64 {
+	if br.End <= br.Start {
+		return 0
+	}
+	return br.End - br.Start
+}
+
+// FindTimeWindow locates the bytes of a log file whose lines fall within [startTime, endTime].
+// Lines without a timestamp are kept with the entry they follow. Plain files are binary
+// searched; compressed files are scanned linearly.
+func FindTimeWindow(fs afero.Fs, filePath string, extractor *TimestampExtractor, bestPattern *TimestampPattern, startTime, endTime time.Time) (ByteRange, error) {
+	extractor = extractor.ForFile(filePath)
+	searcher, err := NewTimeSearcher(fs, filePath, extractor, bestPattern)
+	if err != nil {
+		// Fallback to linear scan for files that can't be searched by offset (compressed or UTF-16)
+		return findTimeWindowLinear(fs, filePath, extractor, bestPattern, startTime, endTime)
+	}
+	defer searcher.Close()
+
+	startPos, endPos := searcher.ByteRangeForTimeRange(startTime, endTime)
+	if endPos < startPos {
+		endPos = startPos
+	}
+
+	return ByteRange{Start: startPos, End: endPos}, nil
+}
+
+// findTimeWindowLinear scans the (decompressed) file once, tracking line offsets
+func findTimeWindowLinear(fs afero.Fs, filePath string, extractor *TimestampExtractor, bestPattern *TimestampPattern, startTime, endTime time.Time) (ByteRange, error) {
+	file, _, err := OpenDecompressed(fs, filePath)
+	if err != nil {
+		return ByteRange{}, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var offset int64
+	window := ByteRange{Start: -1}
+
+	for {
+		line, readErr := reader.ReadString('\n')
+		if len(line) > 0 {
+			if timestamp, err := extractor.ParseEntryTimestamp(line, bestPattern); err == nil {
+				if timestamp.After(endTime) {
+					// First entry past the window ends it
+					if window.Start >= 0 {
+						window.End = offset
+					}
+					break
+				}
+				if window.Start < 0 && !timestamp.Before(startTime) {
+					window.Start = offset
+				}
+			}
+			offset += int64(len(line))
+			if window.Start >= 0 {
+				window.End = offset
+			}
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return ByteRange{}, fmt.Errorf("error scanning file: %w", readErr)
+		}
+	}
+
+	if window.Start < 0 {
+		return ByteRange{Start: offset, End: offset}, nil
+	}
+	return window, nil
+}
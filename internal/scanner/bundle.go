@@ -1,10 +1,13 @@
 package scanner
 
 import (
+	"context"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
 
 	"github.com/cheerioskun/logninja/internal/models"
 	"github.com/cheerioskun/logninja/internal/parser"
@@ -19,15 +22,82 @@ const (
 
 // BundleScanner handles bundle discovery and file enumeration with a two-phase approach:
 // Phase 1: Collect ALL files using WalkDir
-// Phase 2: Filter files based on content peeking only
+// Phase 2: Filter files based on content peeking only, using a pool of workers
 type BundleScanner struct {
 	fs                 afero.Fs
 	maxDepth           int
+	workers            int
 	timestampExtractor *parser.TimestampExtractor
+	boundsExtractor    *parser.BoundsExtractor
 
 	// Two-phase approach data
-	allFiles   []string // Complete file set from WalkDir
-	workingSet []string // Filtered log files after content analysis
+	allFiles        []string                      // Complete file set from WalkDir
+	workingSet      []string                      // Filtered log files after content analysis
+	classifications map[string]fileClassification // Phase 2 results keyed by full path
+
+	// Persistent index of previous scan results
+	indexCache *IndexCache
+	index      *ScanIndex
+	rootPath   string
+
+	progress *progressTracker
+}
+
+// fileClassification is the outcome of peeking at a single file during Phase 2
+type fileClassification struct {
+	isLogFile        bool
+	compression      parser.CompressionFormat
+	kind             models.FileKind     // Sniffed from the file's head
+	encoding         parser.TextEncoding // From the byte order mark, or UTF-16 without one
+	uncompressedSize int64               // Only measured for compressed log files
+	timestampPattern string              // Best timestamp pattern, log files only
+	formatConfidence float64             // Share of peeked lines the best pattern matched
+	timeRange        *models.TimeRange   // Earliest to latest timestamp, log files only
+	estimatedLines   int64               // Line count, log files only; estimated for large files
+	entryCount       int64               // Entry count, log files only; estimated for large files
+	levelCounts      models.LevelCounts  // Entries per log level, log files only; estimated for large files
+
+	// Stat info the classification was made against, used to validate index entries
+	size    int64
+	modTime time.Time
+}
+
+// indexEntry converts a classification into its persistent index form
+func (fc fileClassification) indexEntry() IndexEntry {
+	return IndexEntry{
+		Size:             fc.size,
+		ModTime:          fc.modTime,
+		IsLogFile:        fc.isLogFile,
+		Compression:      string(fc.compression),
+		Kind:             fc.kind,
+		Encoding:         string(fc.encoding),
+		UncompressedSize: fc.uncompressedSize,
+		TimestampPattern: fc.timestampPattern,
+		FormatConfidence: fc.formatConfidence,
+		TimeRange:        fc.timeRange,
+		EstimatedLines:   fc.estimatedLines,
+		EntryCount:       fc.entryCount,
+		LevelCounts:      fc.levelCounts,
+	}
+}
+
+// classificationFromIndex restores a classification from a cached index entry
+func classificationFromIndex(entry IndexEntry) fileClassification {
+	return fileClassification{
+		isLogFile:        entry.IsLogFile,
+		compression:      parser.CompressionFormat(entry.Compression),
+		kind:             entry.Kind,
+		encoding:         parser.TextEncoding(entry.Encoding),
+		uncompressedSize: entry.UncompressedSize,
+		timestampPattern: entry.TimestampPattern,
+		formatConfidence: entry.FormatConfidence,
+		timeRange:        entry.TimeRange.In(models.DisplayLocation()), // Cached times keep the offset they were saved with
+		estimatedLines:   entry.EstimatedLines,
+		entryCount:       entry.EntryCount,
+		levelCounts:      entry.LevelCounts,
+		size:             entry.Size,
+		modTime:          entry.ModTime,
+	}
 }
 
 // NewBundleScanner creates a new BundleScanner with the given filesystem
@@ -35,9 +105,13 @@ func NewBundleScanner(fs afero.Fs) *BundleScanner {
 	return &BundleScanner{
 		fs:                 fs,
 		maxDepth:           10, // Default max depth
+		workers:            runtime.NumCPU(),
 		timestampExtractor: parser.NewTimestampExtractor(fs),
+		boundsExtractor:    parser.NewBoundsExtractor(fs),
 		allFiles:           make([]string, 0),
 		workingSet:         make([]string, 0),
+		classifications:    make(map[string]fileClassification),
+		progress:           &progressTracker{},
 	}
 }
 
@@ -46,8 +120,44 @@ func (bs *BundleScanner) SetMaxDepth(depth int) {
 	bs.maxDepth = depth
 }
 
+// SetWorkers sets how many files are peeked concurrently during Phase 2
+func (bs *BundleScanner) SetWorkers(workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	bs.workers = workers
+}
+
+// SetIndexCache enables reuse of previous scan results; files whose size and
+// modification time are unchanged since the last scan are not peeked again
+func (bs *BundleScanner) SetIndexCache(cache *IndexCache) {
+	bs.indexCache = cache
+}
+
 // ScanBundle scans a directory using a two-phase approach and returns a Bundle with all discovered files
 func (bs *BundleScanner) ScanBundle(path string) (*models.Bundle, error) {
+	return bs.ScanBundleContext(context.Background(), path, nil)
+}
+
+// ScanBundleContext scans a directory like ScanBundle but can be cancelled through ctx.
+// If progress is non-nil, throttled progress snapshots are sent on it while scanning;
+// the channel is not closed by the scanner.
+func (bs *BundleScanner) ScanBundleContext(ctx context.Context, path string, progress chan<- ScanProgress) (*models.Bundle, error) {
+	bs.progress = &progressTracker{}
+	if progress != nil {
+		done := make(chan struct{})
+		var reporter sync.WaitGroup
+		reporter.Add(1)
+		go func() {
+			defer reporter.Done()
+			bs.progress.report(ctx, progress, done)
+		}()
+		defer func() {
+			close(done)
+			reporter.Wait()
+		}()
+	}
+
 	// Verify path exists and is a directory
 	info, err := bs.fs.Stat(path)
 	if err != nil {
@@ -58,19 +168,25 @@ func (bs *BundleScanner) ScanBundle(path string) (*models.Bundle, error) {
 		return nil, fmt.Errorf("path %s is not a directory", path)
 	}
 
+	bs.rootPath = path
+	bs.loadIndex()
+
 	// Phase 1: Collect ALL files using WalkDir
-	err = bs.collectAllFiles(path)
+	bs.progress.setPhase(PhaseWalking)
+	err = bs.collectAllFiles(ctx, path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to collect files: %w", err)
 	}
 
 	// Phase 2: Filter files based on content peeking only
-	err = bs.filterLogFiles()
+	bs.progress.setPhase(PhaseClassifying)
+	err = bs.filterLogFiles(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to filter log files: %w", err)
 	}
 
 	// Create bundle with working set
+	bs.progress.setPhase(PhaseBuilding)
 	bundle := models.NewBundle(path, bs.fs)
 	err = bs.buildBundle(path, bundle)
 	if err != nil {
@@ -79,16 +195,22 @@ func (bs *BundleScanner) ScanBundle(path string) (*models.Bundle, error) {
 
 	// Update bundle metadata
 	bs.updateBundleMetadata(bundle)
+	bs.saveIndex()
+	bs.progress.setPhase(PhaseDone)
 
 	return bundle, nil
 }
 
 // collectAllFiles performs Phase 1: collect ALL files using WalkDir
-func (bs *BundleScanner) collectAllFiles(basePath string) error {
+func (bs *BundleScanner) collectAllFiles(ctx context.Context, basePath string) error {
 	bs.allFiles = make([]string, 0) // Reset the file list
 
 	// Use afero.Walk which is equivalent to filepath.WalkDir
 	err := afero.Walk(bs.fs, basePath, func(fullPath string, info os.FileInfo, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr // Abort the walk on cancellation
+		}
+
 		if err != nil {
 			// Log warning but continue scanning
 			utils.Warning("failed to access %s: %v", fullPath, err)
@@ -112,6 +234,7 @@ func (bs *BundleScanner) collectAllFiles(basePath string) error {
 
 		// Add all regular files to our complete file set
 		bs.allFiles = append(bs.allFiles, fullPath)
+		bs.progress.filesWalked.Add(1)
 		return nil
 	})
 
@@ -122,13 +245,46 @@ func (bs *BundleScanner) collectAllFiles(basePath string) error {
 	return nil
 }
 
-// filterLogFiles performs Phase 2: filter files based on content peeking only
-func (bs *BundleScanner) filterLogFiles() error {
+// filterLogFiles performs Phase 2: filter files based on content peeking only.
+// Files are peeked concurrently by a pool of workers; results keep the Phase 1 order.
+func (bs *BundleScanner) filterLogFiles(ctx context.Context) error {
 	bs.workingSet = make([]string, 0) // Reset the working set
+	bs.classifications = make(map[string]fileClassification, len(bs.allFiles))
+	bs.progress.totalFiles.Store(int64(len(bs.allFiles)))
+
+	results := make([]fileClassification, len(bs.allFiles))
+	jobs := make(chan int)
+
+	var workers sync.WaitGroup
+	for w := 0; w < bs.workers; w++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for index := range jobs {
+				results[index] = bs.classifyOrReuse(ctx, bs.allFiles[index])
+				bs.progress.filesClassified.Add(1)
+			}
+		}()
+	}
+
+dispatch:
+	for index := range bs.allFiles {
+		select {
+		case jobs <- index:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	workers.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
-	for _, filePath := range bs.allFiles {
-		// Check if this file looks like a log file based on content only
-		if bs.isLogFileByContent(filePath) {
+	for index, filePath := range bs.allFiles {
+		bs.classifications[filePath] = results[index]
+		if results[index].isLogFile {
 			bs.workingSet = append(bs.workingSet, filePath)
 		}
 	}
@@ -136,6 +292,112 @@ func (bs *BundleScanner) filterLogFiles() error {
 	return nil
 }
 
+// classifyOrReuse returns the cached classification of a file if it is unchanged since
+// the last scan, and classifies it from its content otherwise
+func (bs *BundleScanner) classifyOrReuse(ctx context.Context, filePath string) fileClassification {
+	info, err := bs.fs.Stat(filePath)
+	if err != nil {
+		// The file is skipped when the bundle is built
+		utils.Warning("failed to stat file %s: %v", filePath, err)
+		return fileClassification{}
+	}
+
+	if bs.index != nil {
+		if entry, ok := bs.index.Entries[bs.relativePath(filePath)]; ok && entry.matches(info) {
+			bs.progress.filesCached.Add(1)
+			return classificationFromIndex(entry)
+		}
+	}
+
+	classification := bs.classifyFile(ctx, filePath, info.Size())
+	classification.size = info.Size()
+	classification.modTime = info.ModTime()
+	return classification
+}
+
+// classifyFile sniffs a file's content and peeks at it to decide whether it is a log file.
+// Binary content is never searched for timestamps. Log files also get their time span
+// extracted and their lines and entries counted, per level, in windows sampled over them.
+// Compressed log files are decompressed once, to measure their uncompressed size and find
+// their latest timestamp in the same pass; non-log archives are skipped to keep scanning fast.
+func (bs *BundleScanner) classifyFile(ctx context.Context, filePath string, size int64) fileClassification {
+	var classification fileClassification
+
+	sniff, err := bs.SniffFile(filePath)
+	if err != nil {
+		utils.Warning("failed to sniff %s: %v", filePath, err)
+		return classification
+	}
+	classification.compression = sniff.Compression
+	classification.kind = sniff.Kind
+	classification.encoding = sniff.Encoding
+
+	if sniff.Binary {
+		return classification
+	}
+	classification.isLogFile, classification.formatConfidence = bs.isLogFileByContent(filePath)
+
+	if !classification.isLogFile {
+		return classification
+	}
+
+	detector := bs.entryDetector(filePath)
+	var pass contentPass
+	if sniff.Compression != parser.CompressionNone || sniff.Encoding.IsUTF16() {
+		pass, err = bs.streamContent(ctx, filePath, detector)
+	} else {
+		pass, err = bs.sampleContent(ctx, filePath, size, detector)
+	}
+	if err != nil {
+		if ctx.Err() == nil {
+			utils.Warning("failed to read %s: %v", filePath, err)
+		}
+		return classification
+	}
+
+	classification.timeRange, classification.timestampPattern = bs.extractTimeRange(filePath, pass)
+	if sniff.Compression != parser.CompressionNone {
+		classification.uncompressedSize = pass.size
+	}
+	classification.estimatedLines, classification.entryCount, classification.levelCounts = pass.counts()
+
+	return classification
+}
+
+// extractTimeRange finds the earliest and latest timestamps of a log file along with the
+// name of the timestamp pattern used. The tail of streamed content is taken from the pass
+// rather than read again. The range is nil if the file has no usable timestamps.
+func (bs *BundleScanner) extractTimeRange(filePath string, pass contentPass) (*models.TimeRange, string) {
+	var bounds *parser.TimeBounds
+	var err error
+	if pass.streamed {
+		bounds, err = bs.boundsExtractor.ExtractBoundsWithTail(filePath, pass.tail)
+	} else {
+		bounds, err = bs.boundsExtractor.ExtractBounds(filePath)
+	}
+	if err != nil {
+		utils.Warning("failed to extract time bounds for %s: %v", filePath, err)
+		return nil, ""
+	}
+
+	patternName := ""
+	if bounds.BestPattern != nil {
+		patternName = bounds.BestPattern.Name
+	}
+	if !bounds.Valid {
+		return nil, patternName
+	}
+
+	timeRange, err := models.NewTimeRange(bounds.Earliest, bounds.Latest)
+	if err != nil {
+		// Out-of-order head and tail; the file is not usable for time-based analysis
+		utils.Warning("invalid time bounds for %s: %v", filePath, err)
+		return nil, patternName
+	}
+
+	return timeRange, patternName
+}
+
 // buildBundle creates a Bundle from the working set
 func (bs *BundleScanner) buildBundle(basePath string, bundle *models.Bundle) error {
 	// Add all files (both log and non-log) to bundle for completeness
@@ -153,15 +415,28 @@ func (bs *BundleScanner) buildBundle(basePath string, bundle *models.Bundle) err
 		}
 
 		// Check if this file is in our working set (i.e., it's a log file)
-		isLogFile := bs.isInWorkingSet(fullPath)
+		classification := bs.classifications[fullPath]
 
 		fileInfo := &models.FileInfo{
-			Path:         relPath,
-			Size:         info.Size(),
-			IsLogFile:    isLogFile,
-			TimeRange:    nil, // Will be populated by log parser if needed
-			Selected:     false,
-			LastModified: info.ModTime(),
+			Path:             relPath,
+			Size:             info.Size(),
+			UncompressedSize: info.Size(),
+			IsLogFile:        classification.isLogFile,
+			TimeRange:        classification.timeRange,
+			TimestampPattern: classification.timestampPattern,
+			FormatConfidence: classification.formatConfidence,
+			EstimatedLines:   classification.estimatedLines,
+			EntryCount:       classification.entryCount,
+			LevelCounts:      classification.levelCounts,
+			Selected:         false,
+			LastModified:     info.ModTime(),
+		}
+
+		fileInfo.Kind = classification.kind
+		fileInfo.Encoding = string(classification.encoding)
+		if classification.compression != parser.CompressionNone {
+			fileInfo.Compression = string(classification.compression)
+			fileInfo.UncompressedSize = classification.uncompressedSize
 		}
 
 		bundle.AddFile(*fileInfo)
@@ -170,96 +445,81 @@ func (bs *BundleScanner) buildBundle(basePath string, bundle *models.Bundle) err
 	return nil
 }
 
-// isInWorkingSet checks if a file path is in the working set
-func (bs *BundleScanner) isInWorkingSet(filePath string) bool {
-	for _, workingFile := range bs.workingSet {
-		if workingFile == filePath {
-			return true
-		}
-	}
-	return false
-}
-
-// isLogFileByContent determines if a file is a log file based ONLY on content peeking
-func (bs *BundleScanner) isLogFileByContent(filePath string) bool {
+// isLogFileByContent determines if a file is a log file based ONLY on content peeking.
+// It also returns the share of peeked lines the best timestamp pattern matched.
+func (bs *BundleScanner) isLogFileByContent(filePath string) (bool, float64) {
 	if bs.timestampExtractor == nil {
-		return false
+		return false, 0
 	}
 
 	// Use the timestamp detection logic to peek at file content
-	result, err := bs.timestampExtractor.DetectBestPattern(filePath)
+	file, _, err := parser.OpenDecompressed(bs.fs, filePath)
 	if err != nil {
-		// If we can't read the file, assume it's not a log file
-		return false
+		return false, 0
 	}
+	defer file.Close()
 
-	// Require a minimum threshold for log file detection
-	// For a file to be considered a log file, we need:
-	// 1. At least 2 timestamp matches in the first 10 lines, AND
-	// 2. At least 30% of lines must have timestamps (confidence >= 0.3)
-	const minTimestampMatches = 2
-	const minConfidence = 0.3
-
-	return result.MatchCount >= minTimestampMatches && result.Confidence >= minConfidence
-}
-
-// estimateLineCount provides a rough estimate of lines in a file
-func (bs *BundleScanner) estimateLineCount(path string) (int64, error) {
-	file, err := bs.fs.Open(path)
+	peeker := &countingReader{reader: file, counter: &bs.progress.bytesPeeked}
+	detection, err := bs.detect(peeker, filePath)
 	if err != nil {
-		return 0, err
+		// If we can't read the file, assume it's not a log file
+		return false, 0
 	}
-	defer file.Close()
 
-	// Sample first 64KB to estimate line count
-	const sampleSize = 64 * 1024
-	buffer := make([]byte, sampleSize)
+	// The best pattern must match enough of the first PeakLines lines, see DetectionThresholds
+	return detection.IsLogFile(), detection.Confidence
+}
 
-	n, err := file.Read(buffer)
-	if err != nil && err != io.EOF {
-		return 0, err
+// loadIndex reads the cached results of the previous scan of this bundle, if caching is enabled
+func (bs *BundleScanner) loadIndex() {
+	bs.index = nil
+	if bs.indexCache == nil {
+		return
 	}
 
-	if n == 0 {
-		return 0, nil
+	index, err := bs.indexCache.Load(bs.rootPath)
+	if err != nil {
+		utils.Warning("ignoring scan index for %s: %v", bs.rootPath, err)
+		return
 	}
+	bs.index = index
+}
 
-	// Count newlines in sample
-	lines := int64(0)
-	for i := 0; i < n; i++ {
-		if buffer[i] == '\n' {
-			lines++
-		}
+// saveIndex stores the results of this scan for the next one, dropping files that no longer exist
+func (bs *BundleScanner) saveIndex() {
+	if bs.indexCache == nil {
+		return
 	}
 
-	// Get file size for estimation
-	stat, err := file.Stat()
-	if err != nil {
-		return lines, nil // Return sample count if we can't get file size
+	index := newScanIndex(bs.rootPath)
+	for fullPath, classification := range bs.classifications {
+		if classification.modTime.IsZero() {
+			continue // Never stat'ed successfully
+		}
+		index.Entries[bs.relativePath(fullPath)] = classification.indexEntry()
 	}
 
-	fileSize := stat.Size()
-
-	// If we read the entire file, return exact count
-	if int64(n) >= fileSize {
-		return lines, nil
+	if err := bs.indexCache.Save(index); err != nil {
+		utils.Warning("failed to save scan index for %s: %v", bs.rootPath, err)
 	}
+}
 
-	// Estimate total lines based on sample
-	if lines > 0 {
-		estimatedLines := (lines * fileSize) / int64(n)
-		return estimatedLines, nil
+// relativePath returns a file path relative to the bundle root, as used for index keys
+func (bs *BundleScanner) relativePath(fullPath string) string {
+	relPath, err := filepath.Rel(bs.rootPath, fullPath)
+	if err != nil {
+		return fullPath
 	}
-
-	return 0, nil
+	return relPath
 }
 
 // updateBundleMetadata updates the bundle metadata after scanning
 func (bs *BundleScanner) updateBundleMetadata(bundle *models.Bundle) {
 	bundle.Metadata.ScanDepth = bs.maxDepth
 
-	// Metadata is already updated by Bundle.AddFile() method
-	// This method can be extended for additional metadata processing
+	// Counts and time ranges are already updated by Bundle.AddFile();
+	// formats are tallied once every file is in
+	bundle.UpdateFormatStats()
 }
 
 // QuickScan performs a quick scan to get basic bundle information without deep analysis
@@ -276,7 +536,7 @@ func (bs *BundleScanner) QuickScan(path string) (*models.BundleMetadata, error)
 	metadata := &models.BundleMetadata{
 		LogFileCount:   0,
 		TotalFileCount: 0,
-		CommonFormats:  make([]string, 0),
+		CommonFormats:  make([]models.FormatStats, 0),
 		ScanDepth:      1, // Quick scan only goes 1 level deep
 	}
 
@@ -290,7 +550,7 @@ func (bs *BundleScanner) QuickScan(path string) (*models.BundleMetadata, error)
 			metadata.TotalFileCount++
 			// For quick scan, we check file content to determine if it's a log file
 			entryFullPath := filepath.Join(path, entry.Name())
-			if bs.isLogFileByContent(entryFullPath) {
+			if isLog, _ := bs.isLogFileByContent(entryFullPath); isLog {
 				metadata.LogFileCount++
 			}
 		}
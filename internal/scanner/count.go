@@ -0,0 +1,286 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/cheerioskun/logninja/internal/models"
+	"github.com/cheerioskun/logninja/internal/parser"
+)
+
+const (
+	// sampleWindowSize is the size of each window of a log file's content in which lines,
+	// entries and levels are counted; the counts of the whole file are extrapolated from them
+	sampleWindowSize = 64 * 1024
+
+	// sampleWindows is how many windows are counted: spread evenly over a plain file, or
+	// kept at most while streaming one whose size isn't known up front
+	sampleWindows = 16
+)
+
+// windowCount holds what was counted in the sampled windows of a file
+type windowCount struct {
+	size   int64 // Bytes of the complete lines counted
+	lines  int64
+	levels models.LevelCounts // Entries per log level
+}
+
+func (wc *windowCount) add(other windowCount) {
+	wc.size += other.size
+	wc.lines += other.lines
+	wc.levels.Add(other.levels)
+}
+
+// contentPass is what a single read of a log file's (decompressed) content collects
+type contentPass struct {
+	streamed bool        // The content can't seek, so it was read to the end
+	size     int64       // Content size; the on-disk size unless streamed
+	lines    int64       // Exact line count, streamed content only
+	tail     []byte      // The last bytes, for the latest timestamp; streamed content only
+	sample   windowCount // Counted in the sampled windows
+}
+
+// counts returns the lines and entries of the file and its entries per log level, scaling
+// the sampled counts up to the whole content
+func (cp contentPass) counts() (int64, int64, models.LevelCounts) {
+	lines := cp.sample.lines
+	levels := make(models.LevelCounts, len(cp.sample.levels))
+	for level, entries := range cp.sample.levels {
+		levels[level] = entries
+	}
+
+	if cp.sample.size > 0 && cp.sample.size < cp.size {
+		scale := float64(cp.size) / float64(cp.sample.size)
+		lines = int64(math.Round(float64(lines) * scale))
+		for level, entries := range levels {
+			levels[level] = int64(math.Round(float64(entries) * scale))
+		}
+	}
+	if cp.streamed {
+		lines = cp.lines
+	}
+
+	return lines, levels.Total(), levels
+}
+
+// entryDetector creates the detector telling a log file's entries apart, using the file's
+// best timestamp pattern as its bounds do
+func (bs *BundleScanner) entryDetector(filePath string) *parser.EntryDetector {
+	extractor := bs.timestampExtractor.ForFile(filePath)
+
+	var pattern *parser.TimestampPattern
+	if result, err := extractor.DetectBestPattern(filePath); err == nil {
+		pattern = result.BestPattern
+	}
+	return parser.NewEntryDetector(extractor, pattern)
+}
+
+// sampleContent counts lines and entries in windows spread evenly over a plain log file,
+// reading all of it only if it is no larger than the windows together
+func (bs *BundleScanner) sampleContent(ctx context.Context, filePath string, size int64, detector *parser.EntryDetector) (contentPass, error) {
+	file, err := bs.fs.Open(filePath)
+	if err != nil {
+		return contentPass{}, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	pass := contentPass{size: size, sample: windowCount{levels: make(models.LevelCounts)}}
+	windows, windowSize := int64(sampleWindows), int64(sampleWindowSize)
+	if size <= windows*windowSize {
+		windows, windowSize = 1, size
+	}
+
+	buffer := make([]byte, windowSize)
+	for i := int64(0); i < windows; i++ {
+		if err := ctx.Err(); err != nil {
+			return contentPass{}, err
+		}
+
+		// The first window starts the file and the last one ends it
+		offset := int64(0)
+		if windows > 1 {
+			offset = i * (size - windowSize) / (windows - 1)
+		}
+		n, err := file.ReadAt(buffer, offset)
+		if err != nil && err != io.EOF {
+			return contentPass{}, fmt.Errorf("failed to read file: %w", err)
+		}
+		bs.progress.bytesPeeked.Add(int64(n))
+
+		atEnd := offset+int64(n) >= size
+		pass.sample.add(countWindow(buffer[:n], detector, offset == 0, atEnd))
+	}
+
+	return pass, nil
+}
+
+// streamContent reads a compressed or UTF-16 log file, which can't seek, to the end once:
+// measuring its size and lines, counting entries in windows spread over it and keeping its
+// tail for the latest timestamp. Reading stops with ctx.Err() once ctx is cancelled.
+func (bs *BundleScanner) streamContent(ctx context.Context, filePath string, detector *parser.EntryDetector) (contentPass, error) {
+	reader, _, err := parser.OpenDecompressed(bs.fs, filePath)
+	if err != nil {
+		return contentPass{}, err
+	}
+	defer reader.Close()
+
+	content := &lineCounter{ctx: ctx, reader: &countingReader{reader: reader, counter: &bs.progress.bytesPeeked}}
+	var tail parser.TailWriter
+	sampler := newWindowSampler(detector)
+	if _, err := io.Copy(io.MultiWriter(&tail, sampler), content); err != nil {
+		return contentPass{}, err
+	}
+
+	return contentPass{
+		streamed: true,
+		size:     content.size,
+		lines:    content.lines(),
+		tail:     tail.Bytes(),
+		sample:   sampler.close(),
+	}, nil
+}
+
+// countWindow counts the complete lines of a window of content, and the entries starting in
+// it per level. A window that doesn't start the file is counted from its first entry, and
+// one that doesn't end it up to its last newline, so cut off lines aren't counted.
+func countWindow(window []byte, detector *parser.EntryDetector, atStart, atEnd bool) windowCount {
+	if !atEnd {
+		window = window[:bytes.LastIndexByte(window, '\n')+1]
+	}
+	if !atStart {
+		if first := bytes.IndexByte(window, '\n'); first >= 0 {
+			window = window[first+1:]
+		} else {
+			window = nil
+		}
+		for len(window) > 0 {
+			line, _, _ := bytes.Cut(window, []byte{'\n'})
+			if detector.IsEntryStart(bytes.TrimSuffix(line, []byte{'\r'})) {
+				break
+			}
+			window = window[min(len(line)+1, len(window)):]
+		}
+	}
+
+	count := windowCount{size: int64(len(window)), levels: make(models.LevelCounts)}
+	tracker := parser.NewEntryTracker(detector)
+	for len(window) > 0 {
+		line, rest, _ := bytes.Cut(window, []byte{'\n'})
+		window = rest
+
+		count.lines++
+		if start, level := tracker.Next(bytes.TrimSuffix(line, []byte{'\r'})); start {
+			count.levels[level]++
+		}
+	}
+	return count
+}
+
+// windowSampler counts windows spread evenly over a stream of unknown length. Windows start
+// at every multiple of stride; once more than sampleWindows are counted, every other one is
+// dropped and the stride doubles, so the windows kept stay evenly spread however long the
+// stream turns out to be.
+type windowSampler struct {
+	detector *parser.EntryDetector
+	stride   int64
+	offset   int64         // Stream offset of the next byte written
+	window   []byte        // The window being filled
+	windows  []windowCount // Counts of the windows at offsets 0, stride, 2*stride, ...
+}
+
+func newWindowSampler(detector *parser.EntryDetector) *windowSampler {
+	return &windowSampler{
+		detector: detector,
+		stride:   sampleWindowSize,
+		window:   make([]byte, 0, sampleWindowSize),
+	}
+}
+
+// Write feeds the next bytes of the stream to the sampler
+func (ws *windowSampler) Write(p []byte) (int, error) {
+	written := len(p)
+	for len(p) > 0 {
+		windowStart := ws.offset / ws.stride * ws.stride
+		into := ws.offset - windowStart
+
+		if into >= sampleWindowSize {
+			// Between windows; skip to the next one
+			skip := min(int64(len(p)), windowStart+ws.stride-ws.offset)
+			ws.offset += skip
+			p = p[skip:]
+			continue
+		}
+
+		n := min(int64(len(p)), sampleWindowSize-into)
+		ws.window = append(ws.window, p[:n]...)
+		ws.offset += n
+		p = p[n:]
+		if len(ws.window) == sampleWindowSize {
+			ws.finishWindow(windowStart == 0, false)
+		}
+	}
+	return written, nil
+}
+
+// finishWindow counts the filled window, thinning out the windows if there are too many
+func (ws *windowSampler) finishWindow(atStart, atEnd bool) {
+	ws.windows = append(ws.windows, countWindow(ws.window, ws.detector, atStart, atEnd))
+	ws.window = ws.window[:0]
+
+	if len(ws.windows) > sampleWindows {
+		kept := ws.windows[:0]
+		for i := 0; i < len(ws.windows); i += 2 {
+			kept = append(kept, ws.windows[i])
+		}
+		ws.windows = kept
+		ws.stride *= 2
+	}
+}
+
+// close counts the window the stream ended in and returns the counts of all windows kept
+func (ws *windowSampler) close() windowCount {
+	if len(ws.window) > 0 {
+		ws.finishWindow(ws.offset == int64(len(ws.window)), true)
+	}
+
+	total := windowCount{levels: make(models.LevelCounts)}
+	for _, window := range ws.windows {
+		total.add(window)
+	}
+	return total
+}
+
+// lineCounter counts the bytes and lines read through it, failing with ctx.Err() once ctx
+// is cancelled so that streaming a large file can be aborted
+type lineCounter struct {
+	ctx      context.Context
+	reader   io.Reader
+	size     int64
+	newlines int64
+	last     byte // Last byte read, to count an unterminated last line
+}
+
+func (lc *lineCounter) Read(p []byte) (int, error) {
+	if err := lc.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := lc.reader.Read(p)
+	if n > 0 {
+		lc.size += int64(n)
+		lc.newlines += int64(bytes.Count(p[:n], []byte{'\n'}))
+		lc.last = p[n-1]
+	}
+	return n, err
+}
+
+// lines returns the number of lines read, including an unterminated last line
+func (lc *lineCounter) lines() int64 {
+	if lc.size > 0 && lc.last != '\n' {
+		return lc.newlines + 1
+	}
+	return lc.newlines
+}
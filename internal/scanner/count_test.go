@@ -0,0 +1,194 @@
+package scanner
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cheerioskun/logninja/internal/models"
+	"github.com/cheerioskun/logninja/internal/parser"
+	"github.com/spf13/afero"
+)
+
+// countTestLog writes entries a second apart with levels cycling through INFO, WARN and
+// ERROR, every seventh followed by two continuation lines. It returns the content, its line
+// count and its entries per level.
+func countTestLog(entries int) ([]byte, int64, models.LevelCounts) {
+	start := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	levelNames := []string{"INFO", "WARN", "ERROR"}
+	levels := []models.LogLevel{models.LevelInfo, models.LevelWarn, models.LevelError}
+
+	var content bytes.Buffer
+	var lines int64
+	counts := make(models.LevelCounts)
+	for i := 0; i < entries; i++ {
+		timestamp := start.Add(time.Duration(i) * time.Second).Format("2006-01-02 15:04:05")
+		fmt.Fprintf(&content, "%s %s worker-%d handled request %d\n", timestamp, levelNames[i%3], i%5, i)
+		lines++
+		counts[levels[i%3]]++
+		if i%7 == 0 {
+			content.WriteString("java.lang.RuntimeException: retry failed\n\tat com.example.Worker.run(Worker.java:42)\n")
+			lines += 2
+		}
+	}
+	return content.Bytes(), lines, counts
+}
+
+// testDetector returns an entry detector for countTestLog content
+func testDetector() *parser.EntryDetector {
+	extractor := parser.NewTimestampExtractor(nil)
+	return parser.NewEntryDetector(extractor, extractor.PatternByName("DateTime_Dash"))
+}
+
+func TestCountWindow(t *testing.T) {
+	window := []byte("2024-01-02 10:00:00 INFO started\n" +
+		"\tat com.example.Worker.run(Worker.java:42)\n" +
+		"2024-01-02 10:00:01 ERROR failed\n" +
+		"2024-01-02 10:00:02 WARN cut off")
+
+	tests := []struct {
+		name          string
+		atStart       bool
+		atEnd         bool
+		wantLines     int64
+		wantLevels    models.LevelCounts
+		wantSizeBytes int
+	}{
+		{
+			name:          "whole file",
+			atStart:       true,
+			atEnd:         true,
+			wantLines:     4,
+			wantLevels:    models.LevelCounts{models.LevelInfo: 1, models.LevelError: 1, models.LevelWarn: 1},
+			wantSizeBytes: len(window),
+		},
+		{
+			name:          "start of the file",
+			atStart:       true,
+			wantLines:     3,
+			wantLevels:    models.LevelCounts{models.LevelInfo: 1, models.LevelError: 1},
+			wantSizeBytes: bytes.LastIndexByte(window, '\n') + 1,
+		},
+		{
+			// The first line may be cut off, and the continuation line after it belongs to an
+			// entry started before the window
+			name:          "middle of the file",
+			wantLines:     1,
+			wantLevels:    models.LevelCounts{models.LevelError: 1},
+			wantSizeBytes: len("2024-01-02 10:00:01 ERROR failed\n"),
+		},
+		{
+			name:          "end of the file",
+			atEnd:         true,
+			wantLines:     2,
+			wantLevels:    models.LevelCounts{models.LevelError: 1, models.LevelWarn: 1},
+			wantSizeBytes: len("2024-01-02 10:00:01 ERROR failed\n2024-01-02 10:00:02 WARN cut off"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count := countWindow(window, testDetector(), tt.atStart, tt.atEnd)
+			if count.lines != tt.wantLines || count.size != int64(tt.wantSizeBytes) || !reflect.DeepEqual(count.levels, tt.wantLevels) {
+				t.Errorf("countWindow() = %d lines of %d bytes with levels %v, want %d lines of %d bytes with levels %v",
+					count.lines, count.size, count.levels, tt.wantLines, tt.wantSizeBytes, tt.wantLevels)
+			}
+		})
+	}
+}
+
+func TestWindowSamplerSpreadsWindows(t *testing.T) {
+	content, lines, levels := countTestLog(200000) // Many times sampleWindows windows
+	sampler := newWindowSampler(testDetector())
+
+	// Feed the stream in uneven writes, as io.Copy would
+	for rest := content; len(rest) > 0; {
+		n := min(len(rest), 10007)
+		sampler.Write(rest[:n])
+		rest = rest[n:]
+	}
+
+	if len(sampler.windows) > sampleWindows {
+		t.Errorf("sampler kept %d windows, want at most %d", len(sampler.windows), sampleWindows)
+	}
+	if sampler.stride*int64(sampleWindows) < int64(len(content))/2 {
+		t.Errorf("windows %d bytes apart don't span the %d byte stream", sampler.stride, len(content))
+	}
+
+	pass := contentPass{streamed: true, size: int64(len(content)), lines: lines, sample: sampler.close()}
+	gotLines, gotEntries, gotLevels := pass.counts()
+	if gotLines != lines {
+		t.Errorf("counts() = %d lines, want the exact %d of streamed content", gotLines, lines)
+	}
+	assertEstimate(t, "entries", gotEntries, levels.Total())
+	for level, want := range levels {
+		assertEstimate(t, string(level)+" entries", gotLevels[level], want)
+	}
+}
+
+// assertEstimate fails the test if got is more than 2% away from want
+func assertEstimate(t *testing.T, what string, got, want int64) {
+	t.Helper()
+	if diff := got - want; diff < -want/50 || diff > want/50 {
+		t.Errorf("estimated %d %s, want %d within 2%%", got, what, want)
+	}
+}
+
+func TestScanCountsEntries(t *testing.T) {
+	small, smallLines, smallLevels := countTestLog(300)
+	large, largeLines, largeLevels := countTestLog(60000) // Larger than the sampled windows together
+
+	var gzipped bytes.Buffer
+	writer := gzip.NewWriter(&gzipped)
+	writer.Write(large)
+	writer.Close()
+
+	fs := afero.NewMemMapFs()
+	files := map[string][]byte{
+		"small.log":      small,
+		"large.log":      large,
+		"large.log.1.gz": gzipped.Bytes(),
+	}
+	for name, content := range files {
+		if err := afero.WriteFile(fs, filepath.Join("/bundle", name), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bundle, err := NewBundleScanner(fs).ScanBundle("/bundle")
+	if err != nil {
+		t.Fatalf("ScanBundle() error = %v", err)
+	}
+	scanned := make(map[string]models.FileInfo)
+	for _, file := range bundle.Files {
+		scanned[file.Path] = file
+	}
+
+	// Small files are counted in full
+	file := scanned["small.log"]
+	if file.EstimatedLines != smallLines || file.EntryCount != smallLevels.Total() || !reflect.DeepEqual(file.LevelCounts, smallLevels) {
+		t.Errorf("small.log counted %d lines, %d entries and levels %v, want %d, %d and %v",
+			file.EstimatedLines, file.EntryCount, file.LevelCounts, smallLines, smallLevels.Total(), smallLevels)
+	}
+
+	// Large files are sampled; streamed ones still get their exact line count and size
+	for _, name := range []string{"large.log", "large.log.1.gz"} {
+		file := scanned[name]
+		if name == "large.log.1.gz" {
+			if file.EstimatedLines != largeLines || file.UncompressedSize != int64(len(large)) {
+				t.Errorf("%s measured %d lines of %d bytes, want %d lines of %d bytes",
+					name, file.EstimatedLines, file.UncompressedSize, largeLines, len(large))
+			}
+		} else {
+			assertEstimate(t, name+" lines", file.EstimatedLines, largeLines)
+		}
+		assertEstimate(t, name+" entries", file.EntryCount, largeLevels.Total())
+		for level, want := range largeLevels {
+			assertEstimate(t, name+" "+string(level)+" entries", file.LevelCounts[level], want)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package scanner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/cheerioskun/logninja/internal/parser"
+)
+
+// DetectionThresholds decide whether a file is a log file from the PeakLines lines sampled
+// from its start
+type DetectionThresholds struct {
+	MinMatches    int     `mapstructure:"min_matches" json:"min_matches"`       // Sampled lines the best pattern must match
+	MinConfidence float64 `mapstructure:"min_confidence" json:"min_confidence"` // Share of sampled lines it must match, 0 to 1
+}
+
+// DefaultDetectionThresholds require timestamps on at least 2 of the sampled lines,
+// and on at least 30% of them
+func DefaultDetectionThresholds() DetectionThresholds {
+	return DetectionThresholds{
+		MinMatches:    2,
+		MinConfidence: 0.3,
+	}
+}
+
+// Detection thresholds are registered once at startup, like timestamp patterns
+var (
+	thresholdsMu sync.RWMutex
+	thresholds   = DefaultDetectionThresholds()
+)
+
+// SetDetectionThresholds registers the thresholds files are classified with. Out of range
+// values keep their default and are reported in the returned error.
+func SetDetectionThresholds(config DetectionThresholds) error {
+	var errs []error
+	defaults := DefaultDetectionThresholds()
+
+	if config.MinMatches < 1 || config.MinMatches > PeakLines {
+		errs = append(errs, fmt.Errorf("min_matches must be between 1 and %d, got %d", PeakLines, config.MinMatches))
+		config.MinMatches = defaults.MinMatches
+	}
+	if config.MinConfidence < 0 || config.MinConfidence > 1 {
+		errs = append(errs, fmt.Errorf("min_confidence must be between 0 and 1, got %g", config.MinConfidence))
+		config.MinConfidence = defaults.MinConfidence
+	}
+
+	thresholdsMu.Lock()
+	thresholds = config
+	thresholdsMu.Unlock()
+
+	return errors.Join(errs...)
+}
+
+// CurrentDetectionThresholds returns the registered detection thresholds
+func CurrentDetectionThresholds() DetectionThresholds {
+	thresholdsMu.RLock()
+	defer thresholdsMu.RUnlock()
+	return thresholds
+}
+
+// thresholdsFingerprint identifies the registered thresholds, so results cached under
+// different ones can be discarded. It is empty for the defaults.
+func thresholdsFingerprint() string {
+	current := CurrentDetectionThresholds()
+	if current == DefaultDetectionThresholds() {
+		return ""
+	}
+	return fmt.Sprintf("%d/%g", current.MinMatches, current.MinConfidence)
+}
+
+// Detection records how a file was classified: what its content was sniffed as, how every
+// timestamp pattern fared on its first lines, and which thresholds the best pattern fell short of
+type Detection struct {
+	Content    ContentSniff          // Binary content is not sampled
+	Sample     []string              // Lines sampled, only kept by ExplainFile
+	LineCount  int                   // Non-empty lines sampled
+	Scores     []parser.PatternScore // One per pattern, in priority order
+	Best       *parser.PatternScore  // Pattern matching the most lines, nil if none matched
+	Confidence float64               // Share of sampled lines the best pattern matched
+	Thresholds DetectionThresholds
+	Failures   []string // Why the file is not a log file; empty if it is
+}
+
+// IsLogFile returns true if the file met every threshold
+func (d *Detection) IsLogFile() bool {
+	return len(d.Failures) == 0
+}
+
+// ExplainFile classifies a file the way a scan does, keeping the sampled lines
+func (bs *BundleScanner) ExplainFile(filePath string) (*Detection, error) {
+	content, err := bs.SniffFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if content.Binary {
+		return &Detection{
+			Content:    content,
+			Thresholds: CurrentDetectionThresholds(),
+			Failures:   []string{"the content is binary, so it is not searched for timestamps"},
+		}, nil
+	}
+
+	file, _, err := parser.OpenDecompressed(bs.fs, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	var sample bytes.Buffer
+	detection, err := bs.detect(io.TeeReader(file, &sample), filePath)
+	if err != nil {
+		return nil, err
+	}
+	detection.Content = content
+	detection.Sample = parser.SampleLines(sample.Bytes(), detection.LineCount)
+	return detection, nil
+}
+
+// detect scores the timestamp patterns on the first lines of a stream and checks the best
+// one against the detection thresholds
+func (bs *BundleScanner) detect(reader io.Reader, filePath string) (*Detection, error) {
+	scores, lineCount, err := bs.timestampExtractor.ForFile(filePath).ScorePatterns(reader, PeakLines)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+
+	detection := &Detection{
+		LineCount:  lineCount,
+		Scores:     scores,
+		Best:       parser.BestScore(scores),
+		Thresholds: CurrentDetectionThresholds(),
+	}
+
+	switch {
+	case lineCount == 0:
+		detection.Failures = append(detection.Failures, "the file has no non-empty lines to sample")
+	case detection.Best == nil:
+		detection.Failures = append(detection.Failures,
+			fmt.Sprintf("no timestamp pattern matched any of the %d sampled lines", lineCount))
+	default:
+		detection.Confidence = float64(detection.Best.Matches) / float64(lineCount)
+		if detection.Best.Matches < detection.Thresholds.MinMatches {
+			detection.Failures = append(detection.Failures, fmt.Sprintf("%s matched %d/%d lines, below min_matches (%d)",
+				detection.Best.Pattern.Name, detection.Best.Matches, lineCount, detection.Thresholds.MinMatches))
+		}
+		if detection.Confidence < detection.Thresholds.MinConfidence {
+			detection.Failures = append(detection.Failures, fmt.Sprintf("confidence %.0f%% is below min_confidence (%.0f%%)",
+				detection.Confidence*100, detection.Thresholds.MinConfidence*100))
+		}
+	}
+
+	return detection, nil
+}
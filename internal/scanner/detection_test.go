@@ -0,0 +1,107 @@
+package scanner
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestSetDetectionThresholds(t *testing.T) {
+	t.Cleanup(func() { SetDetectionThresholds(DefaultDetectionThresholds()) })
+
+	tests := []struct {
+		name    string
+		config  DetectionThresholds
+		want    DetectionThresholds
+		wantErr bool
+	}{
+		{"defaults", DefaultDetectionThresholds(), DefaultDetectionThresholds(), false},
+		{"in range", DetectionThresholds{MinMatches: PeakLines, MinConfidence: 1}, DetectionThresholds{MinMatches: PeakLines, MinConfidence: 1}, false},
+		{"no matches", DetectionThresholds{MinMatches: 0, MinConfidence: 0.5}, DetectionThresholds{MinMatches: 2, MinConfidence: 0.5}, true},
+		{"more matches than sampled lines", DetectionThresholds{MinMatches: PeakLines + 1, MinConfidence: 0.5}, DetectionThresholds{MinMatches: 2, MinConfidence: 0.5}, true},
+		{"negative confidence", DetectionThresholds{MinMatches: 3, MinConfidence: -0.1}, DetectionThresholds{MinMatches: 3, MinConfidence: 0.3}, true},
+		{"confidence above one", DetectionThresholds{MinMatches: 3, MinConfidence: 30}, DetectionThresholds{MinMatches: 3, MinConfidence: 0.3}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SetDetectionThresholds(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetDetectionThresholds(%+v) error = %v, wantErr %v", tt.config, err, tt.wantErr)
+			}
+			if got := CurrentDetectionThresholds(); got != tt.want {
+				t.Errorf("CurrentDetectionThresholds() = %+v, want %+v", got, tt.want)
+			}
+			if fingerprint := thresholdsFingerprint(); (fingerprint == "") != (tt.want == DefaultDetectionThresholds()) {
+				t.Errorf("thresholdsFingerprint() = %q for %+v", fingerprint, tt.want)
+			}
+		})
+	}
+}
+
+// sampleFile returns PeakLines lines, the first stamped of them with a timestamp
+func sampleFile(stamped int) string {
+	var content strings.Builder
+	for i := 0; i < PeakLines; i++ {
+		if i < stamped {
+			fmt.Fprintf(&content, "2024-01-02 10:00:%02d INFO worker handled request %d\n", i, i)
+		} else {
+			fmt.Fprintf(&content, "plain line %d\n", i)
+		}
+	}
+	return content.String()
+}
+
+func TestDetectThresholds(t *testing.T) {
+	t.Cleanup(func() { SetDetectionThresholds(DefaultDetectionThresholds()) })
+
+	lenient := DetectionThresholds{MinMatches: 1, MinConfidence: 0.1}
+	tests := []struct {
+		name         string
+		content      string
+		thresholds   DetectionThresholds
+		wantLog      bool
+		wantFailures []string
+	}{
+		{"mostly timestamped", sampleFile(5), DefaultDetectionThresholds(), true, nil},
+		{"below min_confidence", sampleFile(2), DefaultDetectionThresholds(), false, []string{"min_confidence"}},
+		{"below both", sampleFile(1), DefaultDetectionThresholds(), false, []string{"min_matches", "min_confidence"}},
+		{"lenient thresholds", sampleFile(1), lenient, true, nil},
+		{"no timestamps", sampleFile(0), lenient, false, []string{"no timestamp pattern matched"}},
+		{"empty", "", lenient, false, []string{"no non-empty lines"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetDetectionThresholds(tt.thresholds); err != nil {
+				t.Fatalf("SetDetectionThresholds() error = %v", err)
+			}
+
+			fs := afero.NewMemMapFs()
+			if err := afero.WriteFile(fs, "/bundle/app.log", []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			detection, err := NewBundleScanner(fs).ExplainFile("/bundle/app.log")
+			if err != nil {
+				t.Fatalf("ExplainFile() error = %v", err)
+			}
+
+			if detection.IsLogFile() != tt.wantLog {
+				t.Errorf("IsLogFile() = %v, want %v (failures %q)", detection.IsLogFile(), tt.wantLog, detection.Failures)
+			}
+			if len(detection.Failures) != len(tt.wantFailures) {
+				t.Fatalf("failures = %q, want %d mentioning %q", detection.Failures, len(tt.wantFailures), tt.wantFailures)
+			}
+			for i, want := range tt.wantFailures {
+				if !strings.Contains(detection.Failures[i], want) {
+					t.Errorf("failure %d = %q, want it to mention %q", i, detection.Failures[i], want)
+				}
+			}
+			if len(detection.Sample) != detection.LineCount {
+				t.Errorf("kept %d sample lines of %d sampled", len(detection.Sample), detection.LineCount)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cheerioskun/logninja/internal/models"
+	"github.com/cheerioskun/logninja/internal/parser"
+	"github.com/spf13/afero"
+)
+
+// indexVersion is bumped whenever the cached classification logic changes,
+// invalidating every index written by older versions
+const indexVersion = 10
+
+// IndexEntry is the cached scan result for a single file.
+// An entry is reused only while the file's size and modification time are unchanged.
+type IndexEntry struct {
+	Size             int64              `json:"size"`
+	ModTime          time.Time          `json:"mod_time"`
+	IsLogFile        bool               `json:"is_log_file"`
+	Compression      string             `json:"compression,omitempty"`
+	Kind             models.FileKind    `json:"kind,omitempty"`
+	Encoding         string             `json:"encoding,omitempty"`
+	UncompressedSize int64              `json:"uncompressed_size,omitempty"`
+	TimestampPattern string             `json:"timestamp_pattern,omitempty"`
+	FormatConfidence float64            `json:"format_confidence,omitempty"`
+	TimeRange        *models.TimeRange  `json:"time_range,omitempty"`
+	EstimatedLines   int64              `json:"estimated_lines,omitempty"`
+	EntryCount       int64              `json:"entry_count,omitempty"`
+	LevelCounts      models.LevelCounts `json:"level_counts,omitempty"`
+}
+
+// matches reports whether the entry still describes a file with the given stat info
+func (e IndexEntry) matches(info os.FileInfo) bool {
+	return e.Size == info.Size() && e.ModTime.Equal(info.ModTime())
+}
+
+// ScanIndex holds the cached scan results of one bundle, keyed by path relative to the bundle root.
+// Patterns, Timezones and Thresholds record the custom timestamp patterns, timezone settings
+// and detection thresholds in use, since they change how files classify.
+type ScanIndex struct {
+	Version    int                   `json:"version"`
+	BundlePath string                `json:"bundle_path"`
+	Patterns   string                `json:"patterns,omitempty"`
+	Timezones  string                `json:"timezones,omitempty"`
+	Thresholds string                `json:"thresholds,omitempty"`
+	Entries    map[string]IndexEntry `json:"entries"`
+}
+
+// newScanIndex creates an empty index for a bundle
+func newScanIndex(bundlePath string) *ScanIndex {
+	return &ScanIndex{
+		Version:    indexVersion,
+		BundlePath: bundlePath,
+		Patterns:   parser.PatternsFingerprint(),
+		Timezones:  parser.TimezonesFingerprint(),
+		Thresholds: thresholdsFingerprint(),
+		Entries:    make(map[string]IndexEntry),
+	}
+}
+
+// IndexCache stores scan indexes on disk, one file per bundle
+type IndexCache struct {
+	fs  afero.Fs
+	dir string
+}
+
+// NewIndexCache creates an index cache storing its files in dir
+func NewIndexCache(fs afero.Fs, dir string) *IndexCache {
+	return &IndexCache{
+		fs:  fs,
+		dir: dir,
+	}
+}
+
+// DefaultIndexDir returns the per-user cache directory for scan indexes ($XDG_CACHE_HOME/logninja/index)
+func DefaultIndexDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user cache directory: %w", err)
+	}
+	return filepath.Join(cacheDir, "logninja", "index"), nil
+}
+
+// indexPath returns the index file for a bundle, named after a hash of its absolute path
+func (ic *IndexCache) indexPath(bundlePath string) string {
+	sum := sha256.Sum256([]byte(bundlePath))
+	return filepath.Join(ic.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Load reads the index for a bundle. A missing or outdated index yields an empty one.
+func (ic *IndexCache) Load(bundlePath string) (*ScanIndex, error) {
+	data, err := afero.ReadFile(ic.fs, ic.indexPath(bundlePath))
+	if os.IsNotExist(err) {
+		return newScanIndex(bundlePath), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scan index: %w", err)
+	}
+
+	var index ScanIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse scan index: %w", err)
+	}
+
+	if index.Version != indexVersion || index.BundlePath != bundlePath || index.Entries == nil ||
+		index.Patterns != parser.PatternsFingerprint() || index.Timezones != parser.TimezonesFingerprint() ||
+		index.Thresholds != thresholdsFingerprint() {
+		return newScanIndex(bundlePath), nil
+	}
+
+	return &index, nil
+}
+
+// Save writes the index for a bundle, replacing any previous one atomically
+func (ic *IndexCache) Save(index *ScanIndex) error {
+	if err := ic.fs.MkdirAll(ic.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create index directory: %w", err)
+	}
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to encode scan index: %w", err)
+	}
+
+	path := ic.indexPath(index.BundlePath)
+	tmpPath := path + ".tmp"
+	if err := afero.WriteFile(ic.fs, tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write scan index: %w", err)
+	}
+
+	if err := ic.fs.Rename(tmpPath, path); err != nil {
+		ic.fs.Remove(tmpPath)
+		return fmt.Errorf("failed to replace scan index: %w", err)
+	}
+
+	return nil
+}
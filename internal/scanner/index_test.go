@@ -0,0 +1,187 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cheerioskun/logninja/internal/models"
+	"github.com/spf13/afero"
+)
+
+func TestIndexCache(t *testing.T) {
+	modTime := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	saved := newScanIndex("/bundles/host1")
+	saved.Entries["var/log/app.log"] = IndexEntry{
+		Size:             4096,
+		ModTime:          modTime,
+		IsLogFile:        true,
+		TimestampPattern: "DateTime_Dash",
+		TimeRange:        &models.TimeRange{Start: modTime.Add(-time.Hour), End: modTime},
+		EstimatedLines:   80,
+	}
+
+	tests := []struct {
+		name        string
+		prepare     func(t *testing.T, cache *IndexCache)
+		bundlePath  string
+		wantEntries int
+		wantErr     bool
+	}{
+		{
+			name:        "missing index",
+			prepare:     func(t *testing.T, cache *IndexCache) {},
+			bundlePath:  "/bundles/host1",
+			wantEntries: 0,
+		},
+		{
+			name:        "saved index",
+			prepare:     saveIndex(saved),
+			bundlePath:  "/bundles/host1",
+			wantEntries: 1,
+		},
+		{
+			name:        "another bundle",
+			prepare:     saveIndex(saved),
+			bundlePath:  "/bundles/host2",
+			wantEntries: 0,
+		},
+		{
+			name: "outdated version",
+			prepare: func(t *testing.T, cache *IndexCache) {
+				outdated := *saved
+				outdated.Version = indexVersion - 1
+				saveIndex(&outdated)(t, cache)
+			},
+			bundlePath:  "/bundles/host1",
+			wantEntries: 0,
+		},
+		{
+			name: "corrupt index",
+			prepare: func(t *testing.T, cache *IndexCache) {
+				if err := afero.WriteFile(cache.fs, cache.indexPath("/bundles/host1"), []byte("{not json"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			},
+			bundlePath: "/bundles/host1",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			fs.MkdirAll("/cache", 0755)
+			cache := NewIndexCache(fs, "/cache")
+			tt.prepare(t, cache)
+
+			index, err := cache.Load(tt.bundlePath)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Load(%s) = %v, want error", tt.bundlePath, index)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load(%s) error = %v", tt.bundlePath, err)
+			}
+			if index.BundlePath != tt.bundlePath || len(index.Entries) != tt.wantEntries {
+				t.Fatalf("Load(%s) = index of %s with %d entries, want %d entries", tt.bundlePath, index.BundlePath, len(index.Entries), tt.wantEntries)
+			}
+
+			if tt.wantEntries > 0 {
+				got := index.Entries["var/log/app.log"]
+				want := saved.Entries["var/log/app.log"]
+				if got.Size != want.Size || !got.ModTime.Equal(want.ModTime) || got.IsLogFile != want.IsLogFile ||
+					got.TimestampPattern != want.TimestampPattern || !got.TimeRange.Start.Equal(want.TimeRange.Start) ||
+					!got.TimeRange.End.Equal(want.TimeRange.End) || got.EstimatedLines != want.EstimatedLines {
+					t.Errorf("Load() entry = %+v, want %+v", got, want)
+				}
+			}
+		})
+	}
+}
+
+// saveIndex returns a preparation step storing index in the cache
+func saveIndex(index *ScanIndex) func(t *testing.T, cache *IndexCache) {
+	return func(t *testing.T, cache *IndexCache) {
+		t.Helper()
+		if err := cache.Save(index); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+}
+
+func TestScanReusesIndex(t *testing.T) {
+	root := t.TempDir()
+	var content strings.Builder
+	for i := 0; i < 50; i++ {
+		fmt.Fprintf(&content, "2024-01-02 10:%02d:00 INFO worker handled request %d\n", i, i)
+	}
+	files := map[string]string{
+		"app.log":   content.String(),
+		"other.log": content.String(),
+		"notes.txt": "plain notes\nwithout any timestamps\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cache := NewIndexCache(afero.NewMemMapFs(), "/cache")
+	scan := func() map[string]bool {
+		t.Helper()
+		bundleScanner := NewBundleScanner(afero.NewOsFs())
+		bundleScanner.SetIndexCache(cache)
+		bundle, err := bundleScanner.ScanBundle(root)
+		if err != nil {
+			t.Fatalf("ScanBundle() error = %v", err)
+		}
+		logFiles := make(map[string]bool)
+		for _, file := range bundle.Files {
+			logFiles[file.Path] = file.IsLogFile
+		}
+		return logFiles
+	}
+
+	first := scan()
+	if !first["app.log"] || !first["other.log"] || first["notes.txt"] {
+		t.Fatalf("first scan classified files as %v", first)
+	}
+
+	index, err := cache.Load(root)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(index.Entries) != len(files) {
+		t.Fatalf("index holds %d entries, want %d", len(index.Entries), len(files))
+	}
+
+	// Tamper with the cached results: unchanged files must be taken from the index as is
+	for _, name := range []string{"app.log", "other.log"} {
+		entry := index.Entries[name]
+		entry.IsLogFile = false
+		index.Entries[name] = entry
+	}
+	if err := cache.Save(index); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// A changed modification time invalidates the entry of other.log
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(root, "other.log"), later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	second := scan()
+	if second["app.log"] {
+		t.Error("app.log was classified again rather than reused from the index")
+	}
+	if !second["other.log"] {
+		t.Error("other.log was reused from the index after it changed")
+	}
+}
@@ -0,0 +1,111 @@
+package scanner
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// ScanPhase identifies which stage of the bundle scan is running
+type ScanPhase int
+
+const (
+	PhaseWalking ScanPhase = iota
+	PhaseClassifying
+	PhaseBuilding
+	PhaseDone
+)
+
+// String returns a human-readable name for the scan phase
+func (p ScanPhase) String() string {
+	switch p {
+	case PhaseWalking:
+		return "Walking"
+	case PhaseClassifying:
+		return "Classifying"
+	case PhaseBuilding:
+		return "Building"
+	case PhaseDone:
+		return "Done"
+	default:
+		return "Unknown"
+	}
+}
+
+// ScanProgress is a snapshot of scan progress emitted while a bundle is scanned
+type ScanProgress struct {
+	Phase           ScanPhase
+	FilesWalked     int64 // Files discovered so far in Phase 1
+	FilesClassified int64 // Files whose content has been peeked in Phase 2
+	FilesCached     int64 // Classified files reused unchanged from the scan index
+	TotalFiles      int64 // Files to classify (known once walking finishes)
+	BytesPeeked     int64 // Bytes read while peeking at file content
+}
+
+// progressInterval throttles how often progress snapshots are emitted
+const progressInterval = 100 * time.Millisecond
+
+// progressTracker holds the live counters updated concurrently by scan workers
+type progressTracker struct {
+	phase           atomic.Int64
+	filesWalked     atomic.Int64
+	filesClassified atomic.Int64
+	filesCached     atomic.Int64
+	totalFiles      atomic.Int64
+	bytesPeeked     atomic.Int64
+}
+
+// snapshot captures the current counters
+func (pt *progressTracker) snapshot() ScanProgress {
+	return ScanProgress{
+		Phase:           ScanPhase(pt.phase.Load()),
+		FilesWalked:     pt.filesWalked.Load(),
+		FilesClassified: pt.filesClassified.Load(),
+		FilesCached:     pt.filesCached.Load(),
+		TotalFiles:      pt.totalFiles.Load(),
+		BytesPeeked:     pt.bytesPeeked.Load(),
+	}
+}
+
+// setPhase moves the tracker to a new scan phase
+func (pt *progressTracker) setPhase(phase ScanPhase) {
+	pt.phase.Store(int64(phase))
+}
+
+// report periodically sends snapshots to out until ctx is cancelled or done is closed,
+// then sends one final snapshot so consumers always see the finished state
+func (pt *progressTracker) report(ctx context.Context, out chan<- ScanProgress, done <-chan struct{}) {
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-done:
+			select {
+			case out <- pt.snapshot():
+			case <-ctx.Done():
+			}
+			return
+		case <-ticker.C:
+			select {
+			case out <- pt.snapshot():
+			default: // Consumer is busy; a fresher snapshot follows shortly
+			}
+		}
+	}
+}
+
+// countingReader counts the bytes read through it into a shared counter
+type countingReader struct {
+	reader  io.Reader
+	counter *atomic.Int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.reader.Read(p)
+	cr.counter.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,72 @@
+package scanner
+
+import (
+	"github.com/cheerioskun/logninja/internal/models"
+	"github.com/cheerioskun/logninja/internal/parser"
+)
+
+// sniffSize is how much of a file's (decompressed) content is sniffed; the same head the
+// parser detects encodings from when reading the file
+const sniffSize = parser.EncodingSniffSize
+
+// maxControlRatio is the share of control bytes above which text without NUL bytes is
+// still considered binary
+const maxControlRatio = 0.1
+
+// ContentSniff is what the first bytes of a file say about its content
+type ContentSniff struct {
+	Kind        models.FileKind          // Text, binary or compressed
+	Compression parser.CompressionFormat // Compression of the file on disk
+	Encoding    parser.TextEncoding      // Encoding of the (decompressed) text, from its byte order mark
+	Binary      bool                     // The (decompressed) content is binary, so it isn't searched for timestamps
+}
+
+// SniffFile classifies the content of a file from its first bytes. Compressed files are
+// sniffed decompressed, so a compressed core is told apart from a compressed log.
+func (bs *BundleScanner) SniffFile(filePath string) (ContentSniff, error) {
+	head, compression, err := parser.ReadHead(bs.fs, filePath, sniffSize)
+	if err != nil {
+		return ContentSniff{}, err
+	}
+	return sniffContent(head, compression), nil
+}
+
+// sniffContent classifies a file from the head of its decompressed content
+func sniffContent(head []byte, compression parser.CompressionFormat) ContentSniff {
+	sniff := ContentSniff{
+		Compression: compression,
+		Encoding:    parser.EncodingFromHeader(head),
+	}
+	sniff.Binary = !sniff.Encoding.IsUTF16() && isBinary(head)
+
+	switch {
+	case compression != parser.CompressionNone:
+		sniff.Kind = models.KindCompressed
+	case sniff.Binary:
+		sniff.Kind = models.KindBinary
+	default:
+		sniff.Kind = models.KindText
+	}
+	return sniff
+}
+
+// isBinary reports whether data looks like binary rather than text: it has a NUL byte, which
+// text other than UTF-16 never has, or too many control bytes. Bytes above 0x7f are not
+// counted, as they are common in UTF-8 and legacy 8-bit encodings alike.
+func isBinary(data []byte) bool {
+	if len(data) == 0 {
+		return false
+	}
+
+	control := 0
+	for _, b := range data {
+		switch {
+		case b == 0:
+			return true
+		case b == '\t', b == '\n', b == '\r', b == '\f', b == 0x1b: // ESC starts ANSI colour codes
+		case b < 0x20, b == 0x7f:
+			control++
+		}
+	}
+	return float64(control) > float64(len(data))*maxControlRatio
+}
@@ -0,0 +1,83 @@
+package scanner
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+
+	"github.com/cheerioskun/logninja/internal/models"
+	"github.com/cheerioskun/logninja/internal/parser"
+	"github.com/spf13/afero"
+)
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"empty", nil, false},
+		{"plain text", []byte("2024-01-02 10:00:00 INFO started\n"), false},
+		{"tabs and CRLF", []byte("key\tvalue\r\nkey\tvalue\r\n"), false},
+		{"ANSI colours", []byte("\x1b[31mERROR\x1b[0m failed\n"), false},
+		{"UTF-8", []byte("2024-01-02 10:00:00 INFO überprüft ✓\n"), false},
+		{"Latin-1", []byte("caf\xe9 cr\xe8me\n"), false},
+		{"NUL byte", []byte("text\x00more text"), true},
+		{"few control bytes", append([]byte(strings.Repeat("a", 95)), 1, 2, 3, 4, 5), false},
+		{"many control bytes", append([]byte(strings.Repeat("a", 80)), bytes.Repeat([]byte{1, 2, 3, 4}, 5)...), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isBinary(tt.data); got != tt.want {
+				t.Errorf("isBinary(%q) = %v, want %v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSniffFile(t *testing.T) {
+	gzipped := func(data []byte) []byte {
+		var out bytes.Buffer
+		writer := gzip.NewWriter(&out)
+		writer.Write(data)
+		writer.Close()
+		return out.Bytes()
+	}
+	text := []byte("2024-01-02 10:00:00 INFO started\n")
+	core := []byte("\x7fELF\x02\x01\x01\x00\x00\x00\x00\x00")
+
+	tests := []struct {
+		name        string
+		content     []byte
+		want        models.FileKind
+		compression parser.CompressionFormat
+		encoding    parser.TextEncoding
+		binary      bool
+	}{
+		{"text", text, models.KindText, parser.CompressionNone, parser.EncodingUnknown, false},
+		{"UTF-16 text", []byte("\xff\xfe2\x000\x002\x004\x00\n\x00"), models.KindText, parser.CompressionNone, parser.EncodingUTF16LE, false},
+		{"binary", core, models.KindBinary, parser.CompressionNone, parser.EncodingUnknown, true},
+		{"compressed text", gzipped(text), models.KindCompressed, parser.CompressionGzip, parser.EncodingUnknown, false},
+		{"compressed binary", gzipped(core), models.KindCompressed, parser.CompressionGzip, parser.EncodingUnknown, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			if err := afero.WriteFile(fs, "/bundle/file", tt.content, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			sniff, err := NewBundleScanner(fs).SniffFile("/bundle/file")
+			if err != nil {
+				t.Fatalf("SniffFile() error = %v", err)
+			}
+			want := ContentSniff{Kind: tt.want, Compression: tt.compression, Encoding: tt.encoding, Binary: tt.binary}
+			if sniff != want {
+				t.Errorf("SniffFile() = %+v, want %+v", sniff, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+// Package structured reads fields from structured log lines: JSON objects and logfmt
+// key=value pairs. Lines are scanned rather than decoded, since only a field or two is needed.
+package structured
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// Field returns the value of a key in a JSON or logfmt line, with string quotes removed.
+// Nested JSON keys are separated by dots.
+func Field(line []byte, key string) ([]byte, bool) {
+	var value []byte
+	var ok bool
+	if trimmed := bytes.TrimLeft(line, " \t"); len(trimmed) > 0 && trimmed[0] == '{' {
+		value, ok = JSONField(trimmed, key)
+	} else {
+		value, ok = LogfmtField(line, key)
+	}
+	if !ok {
+		return nil, false
+	}
+	return Unquote(value), true
+}
+
+// Unquote removes the quotes of a raw string value, resolving escapes where possible.
+// Other values are returned unchanged.
+func Unquote(value []byte) []byte {
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return value
+	}
+	if bytes.IndexByte(value, '\\') < 0 {
+		return value[1 : len(value)-1]
+	}
+	if unquoted, err := strconv.Unquote(string(value)); err == nil {
+		return []byte(unquoted)
+	}
+	return value[1 : len(value)-1]
+}
+
+// LogfmtField returns the raw value of a key in a logfmt line (key=value key2="quoted value").
+// Words without an "=" are skipped, so keys after a plain-text prefix are still found.
+func LogfmtField(line []byte, key string) ([]byte, bool) {
+	for i := 0; i < len(line); {
+		i = skipSpace(line, i)
+		start := i
+		for i < len(line) && line[i] != '=' && line[i] != ' ' && line[i] != '\t' && line[i] != '"' {
+			i++
+		}
+		name := line[start:i]
+
+		if i >= len(line) || line[i] != '=' {
+			// Not a key; skip the word, or the quoted text it runs into
+			if i < len(line) && line[i] == '"' {
+				if end := skipString(line, i); end > 0 {
+					i = end
+					continue
+				}
+			}
+			for i < len(line) && line[i] != ' ' && line[i] != '\t' {
+				i++
+			}
+			continue
+		}
+
+		i++ // Skip '='
+		valueStart := i
+		if i < len(line) && line[i] == '"' {
+			if end := skipString(line, i); end > 0 {
+				i = end
+			} else {
+				i = len(line)
+			}
+		} else {
+			for i < len(line) && line[i] != ' ' && line[i] != '\t' {
+				i++
+			}
+		}
+
+		if len(name) > 0 && string(name) == key {
+			return line[valueStart:i], true
+		}
+	}
+	return nil, false
+}
+
+// JSONField returns the raw value of a key in a JSON object line, following dots into nested
+// objects ("event.created") when no key has the full name. Only as much of the line is
+// parsed as is needed to skip over other values.
+func JSONField(line []byte, path string) ([]byte, bool) {
+	if value, ok := objectField(line, path); ok {
+		return value, true
+	}
+
+	head, rest, nested := strings.Cut(path, ".")
+	if !nested {
+		return nil, false
+	}
+	object, ok := objectField(line, head)
+	if !ok {
+		return nil, false
+	}
+	return JSONField(object, rest)
+}
+
+// objectField scans the top level of a JSON object for a key
+func objectField(data []byte, key string) ([]byte, bool) {
+	i := skipSpace(data, 0)
+	if i >= len(data) || data[i] != '{' {
+		return nil, false
+	}
+	i++
+
+	for {
+		i = skipSpace(data, i)
+		if i >= len(data) || data[i] != '"' {
+			return nil, false // End of object or malformed
+		}
+		keyEnd := skipString(data, i)
+		if keyEnd < 0 {
+			return nil, false
+		}
+		name := data[i+1 : keyEnd-1]
+
+		i = skipSpace(data, keyEnd)
+		if i >= len(data) || data[i] != ':' {
+			return nil, false
+		}
+		i = skipSpace(data, i+1)
+
+		valueEnd := skipValue(data, i)
+		if valueEnd <= i {
+			return nil, false
+		}
+		if string(name) == key {
+			return data[i:valueEnd], true
+		}
+
+		i = skipSpace(data, valueEnd)
+		if i >= len(data) || data[i] != ',' {
+			return nil, false
+		}
+		i++
+	}
+}
+
+// skipValue returns the index just past the JSON value starting at i, or -1 if it is malformed
+func skipValue(data []byte, i int) int {
+	if i >= len(data) {
+		return -1
+	}
+
+	switch data[i] {
+	case '"':
+		return skipString(data, i)
+
+	case '{', '[':
+		depth := 0
+		for i < len(data) {
+			switch data[i] {
+			case '"':
+				end := skipString(data, i)
+				if end < 0 {
+					return -1
+				}
+				i = end
+				continue
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return i + 1
+				}
+			}
+			i++
+		}
+		return -1
+
+	default: // Number, true, false or null
+		start := i
+		for i < len(data) && !isValueEnd(data[i]) {
+			i++
+		}
+		if i == start {
+			return -1
+		}
+		return i
+	}
+}
+
+// skipString returns the index just past the JSON string starting at i, or -1 if it is unterminated
+func skipString(data []byte, i int) int {
+	for j := i + 1; j < len(data); j++ {
+		switch data[j] {
+		case '\\':
+			j++
+		case '"':
+			return j + 1
+		}
+	}
+	return -1
+}
+
+func isValueEnd(c byte) bool {
+	return c == ',' || c == '}' || c == ']' || c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+func skipSpace(data []byte, i int) int {
+	for i < len(data) && (data[i] == ' ' || data[i] == '\t' || data[i] == '\r' || data[i] == '\n') {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,112 @@
+package structured
+
+import "testing"
+
+func TestJSONField(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{"string", `{"ts":"2024-01-02T10:00:00Z","msg":"ok"}`, "ts", `"2024-01-02T10:00:00Z"`, true},
+		{"number", `{"msg":"ok","ts":1704189600}`, "ts", `1704189600`, true},
+		{"after nested object", `{"ctx":{"ts":1},"ts":2}`, "ts", `2`, true},
+		{"after array", `{"tags":["a","ts"],"ts":3}`, "ts", `3`, true},
+		{"escaped quote in string", `{"msg":"say \"ts\":1","ts":4}`, "ts", `4`, true},
+		{"nested path", `{"event":{"created":"2024-01-02"}}`, "event.created", `"2024-01-02"`, true},
+		{"dotted key preferred", `{"event.created":1,"event":{"created":2}}`, "event.created", `1`, true},
+		{"object value", `{"ctx":{"a":1}}`, "ctx", `{"a":1}`, true},
+		{"spaces", `  { "ts" : 5 , "x" : 6 }`, "x", `6`, true},
+		{"only nested", `{"ctx":{"ts":1}}`, "ts", ``, false},
+		{"missing", `{"msg":"ok"}`, "ts", ``, false},
+		{"not JSON", `ts=1 msg=ok`, "ts", ``, false},
+		{"truncated", `{"msg":"unterminated`, "ts", ``, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := JSONField([]byte(tt.line), tt.path)
+			if ok != tt.wantOK || string(got) != tt.want {
+				t.Errorf("JSONField(%q, %q) = %q, %v, want %q, %v", tt.line, tt.path, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestLogfmtField(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		key    string
+		want   string
+		wantOK bool
+	}{
+		{"first", `ts=1704189600 level=info`, "ts", `1704189600`, true},
+		{"last", `level=info ts=1704189600`, "ts", `1704189600`, true},
+		{"quoted", `level=info msg="request handled" ts=1`, "msg", `"request handled"`, true},
+		{"key inside quoted value", `msg="ts=1 level=error" ts=2`, "ts", `2`, true},
+		{"after plain-text prefix", `Jan  2 10:00:00 app: level=warn msg=slow`, "level", `warn`, true},
+		{"after quoted text", `app "some words" level=warn`, "level", `warn`, true},
+		{"empty value", `level= msg=ok`, "level", ``, true},
+		{"prefix of a longer key", `tsx=1 level=info`, "ts", ``, false},
+		{"missing", `level=info msg=ok`, "ts", ``, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := LogfmtField([]byte(tt.line), tt.key)
+			if ok != tt.wantOK || string(got) != tt.want {
+				t.Errorf("LogfmtField(%q, %q) = %q, %v, want %q, %v", tt.line, tt.key, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestField(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		key    string
+		want   string
+		wantOK bool
+	}{
+		{"JSON string unquoted", `{"msg":"tab\there"}`, "msg", "tab\there", true},
+		{"JSON number", ` {"n":42}`, "n", "42", true},
+		{"logfmt quoted", `msg="a \"quoted\" word"`, "msg", `a "quoted" word`, true},
+		{"logfmt bare", `level=info`, "level", "info", true},
+		{"missing", `level=info`, "msg", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Field([]byte(tt.line), tt.key)
+			if ok != tt.wantOK || string(got) != tt.want {
+				t.Errorf("Field(%q, %q) = %q, %v, want %q, %v", tt.line, tt.key, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestUnquote(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{`"plain"`, "plain"},
+		{`"tab\there"`, "tab\there"},
+		{`"escaped \"quote\""`, `escaped "quote"`},
+		{`"bad \escape"`, `bad \escape`},
+		{`42`, "42"},
+		{`"`, `"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := Unquote([]byte(tt.value)); string(got) != tt.want {
+				t.Errorf("Unquote(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
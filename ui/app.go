@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -9,9 +10,14 @@ import (
 	"github.com/cheerioskun/logninja/internal/export"
 	"github.com/cheerioskun/logninja/internal/messages"
 	"github.com/cheerioskun/logninja/internal/models"
+	"github.com/cheerioskun/logninja/internal/parser"
+	"github.com/cheerioskun/logninja/internal/scanner"
+	"github.com/cheerioskun/logninja/ui/detail"
 	exportui "github.com/cheerioskun/logninja/ui/export"
 	"github.com/cheerioskun/logninja/ui/filelist"
+	"github.com/cheerioskun/logninja/ui/histogram"
 	"github.com/cheerioskun/logninja/ui/regex"
+	"github.com/cheerioskun/logninja/ui/timerange"
 	"github.com/spf13/afero"
 )
 
@@ -21,6 +27,7 @@ type FocusedPanel int
 const (
 	RegexPanel FocusedPanel = iota
 	FileListPanel
+	HistogramPanel
 	StatusPanel
 )
 
@@ -30,12 +37,16 @@ type AppModel struct {
 	workingSet *models.WorkingSet
 
 	// Services
-	exportService *export.Service
+	exportService   *export.Service
+	contentSearcher *parser.ContentSearcher
 
 	// UI Components
-	regexPanel    *regex.Model
-	fileListPanel *filelist.Model
-	exportModal   *exportui.Model
+	regexPanel     *regex.Model
+	fileListPanel  *filelist.Model
+	histogramPanel *histogram.Model
+	exportModal    *exportui.Model
+	timePicker     *timerange.Model
+	detailPopup    *detail.Model
 
 	// UI state
 	focused      FocusedPanel
@@ -44,6 +55,16 @@ type AppModel struct {
 	panels       []FocusedPanel
 	currentPanel int
 
+	// Export sizes inside the time window and after line filters, measured in the background
+	exportSize         exportSizeMsg
+	exportSizeReady    bool
+	estimateGeneration int
+	estimateCancel     context.CancelFunc
+
+	// File match searches by pattern: finished results and searches still running
+	fileMatches  map[string]map[string]int64
+	fileSearches map[string]context.CancelFunc
+
 	// Status
 	status   string
 	ready    bool
@@ -52,36 +73,55 @@ type AppModel struct {
 
 // NewAppModel creates a new application model
 func NewAppModel(workingSet *models.WorkingSet, fs afero.Fs) *AppModel {
-	// Create services
-	exportService := export.NewService(fs)
+	// Create services; bundle files are read through the bundle's own filesystem
+	// (which may be a read-only archive) and exported through fs
+	sourceFs := fs
+	if workingSet != nil && workingSet.Bundle != nil && workingSet.Bundle.GetFilesystem() != nil {
+		sourceFs = workingSet.Bundle.GetFilesystem()
+	}
+	exportService := export.NewServiceWithFilesystems(sourceFs, fs)
 
-	// Create the two working panels
+	// Create the working panels
 	regexPanel := regex.NewModel()
 	fileListPanel := filelist.NewModel()
+	histogramPanel := histogram.NewModel(sourceFs)
+	histogramPanel.SetWorkingSet(workingSet)
 	exportModal := exportui.NewModel(exportService)
 
 	if workingSet != nil && workingSet.Bundle != nil {
 		var filePaths []string
+		formats := make(map[string]string, len(workingSet.Bundle.Files))
+		kinds := make(map[string]string, len(workingSet.Bundle.Files))
 		for _, file := range workingSet.Bundle.Files {
 			filePaths = append(filePaths, file.Path)
+			formats[file.Path] = file.Format()
+			kinds[file.Path] = file.KindDescription()
 		}
+		regexPanel.SetFileFormats(formats)
+		regexPanel.SetFileKinds(kinds)
 		regexPanel.SetFiles(filePaths)
 	}
 
 	return &AppModel{
-		workingSet:    workingSet,
-		exportService: exportService,
-		regexPanel:    regexPanel,
-		fileListPanel: fileListPanel,
-		exportModal:   exportModal,
-		focused:       RegexPanel,
-		width:         80,
-		height:        24,
-		panels:        []FocusedPanel{RegexPanel, FileListPanel},
-		currentPanel:  0,
-		status:        "Ready",
-		ready:         true,
-		quitting:      false,
+		workingSet:      workingSet,
+		exportService:   exportService,
+		contentSearcher: parser.NewContentSearcher(sourceFs),
+		regexPanel:      regexPanel,
+		fileListPanel:   fileListPanel,
+		histogramPanel:  histogramPanel,
+		exportModal:     exportModal,
+		timePicker:      timerange.NewModel(),
+		detailPopup:     detail.NewModel(),
+		focused:         RegexPanel,
+		width:           80,
+		height:          24,
+		panels:          []FocusedPanel{RegexPanel, FileListPanel, HistogramPanel},
+		currentPanel:    0,
+		status:          "Ready",
+		ready:           true,
+		quitting:        false,
+		fileMatches:     make(map[string]map[string]int64),
+		fileSearches:    make(map[string]context.CancelFunc),
 	}
 }
 
@@ -103,15 +143,54 @@ func (m *AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 		m.exportModal.SetSize(msg.Width, msg.Height)
+		m.timePicker.SetSize(msg.Width, msg.Height)
+		m.detailPopup.SetSize(msg.Width, msg.Height)
 		return m, nil
 
 	case messages.RegexFiltersChangedMsg:
 		// Handle ordered regex filter changes
 		return m, m.handleRegexFiltersChange(msg)
 
+	case messages.TimeFilterChangedMsg:
+		// Handle time window changes
+		return m, m.handleTimeFilterChange(msg)
+
 	case messages.WorkingSetUpdatedMsg:
-		// Handle working set updates
+		// Handle working set updates; the histogram regenerates in the background
 		m.status = fmt.Sprintf("Working set updated: %d files selected", msg.SelectedCount)
+		var cmd tea.Cmd
+		m.histogramPanel, cmd = m.histogramPanel.Update(histogram.WorkingSetUpdatedMsg{WorkingSet: m.workingSet})
+		return m, tea.Batch(cmd, m.estimateExportSize())
+
+	case fileSearchDoneMsg:
+		return m, m.handleFileSearchDone(msg)
+
+	case exportSizeMsg:
+		if msg.generation != m.estimateGeneration {
+			return m, nil // Superseded by a newer estimate
+		}
+		m.estimateCancel = nil
+		if msg.err != nil {
+			m.status = fmt.Sprintf("Export size estimate failed: %v", msg.err)
+			return m, nil
+		}
+		m.exportSize = msg
+		m.exportSizeReady = true
+		return m, nil
+
+	case histogram.HistogramDataMsg, histogram.HistogramErrorMsg:
+		// Forward background histogram results to the histogram panel
+		var cmd tea.Cmd
+		m.histogramPanel, cmd = m.histogramPanel.Update(msg)
+		return m, cmd
+
+	case filelist.ExplainFileMsg:
+		m.status = fmt.Sprintf("Explaining %s...", msg.File.Path)
+		return m, m.explainFile(msg.File)
+
+	case explainDoneMsg:
+		m.detailPopup.Show(msg.file, msg.detection, msg.err)
+		m.status = fmt.Sprintf("Showing details of %s", msg.file.Path)
 		return m, nil
 
 	case filelist.FileListDataMsg:
@@ -120,6 +199,17 @@ func (m *AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.fileListPanel, cmd = m.fileListPanel.Update(msg)
 		return m, cmd
 
+	case timerange.TimePickerClosedMsg:
+		switch {
+		case !msg.Applied:
+			m.status = "Time filter unchanged"
+		case msg.TimeRange == nil:
+			m.status = "Time filter cleared"
+		default:
+			m.status = "Time filter applied"
+		}
+		return m, nil
+
 	case exportui.ExportModalConfirmedMsg:
 		m.status = "Export started..."
 		return m, nil
@@ -142,6 +232,18 @@ func (m *AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
+		// The time picker and detail popup capture all keys while open
+		if m.timePicker.IsVisible() {
+			var cmd tea.Cmd
+			m.timePicker, cmd = m.timePicker.Update(msg)
+			return m, cmd
+		}
+		if m.detailPopup.IsVisible() {
+			var cmd tea.Cmd
+			m.detailPopup, cmd = m.detailPopup.Update(msg)
+			return m, cmd
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "q":
 			m.quitting = true
@@ -157,11 +259,23 @@ func (m *AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case "?":
 			// Show help (placeholder)
-			m.status = "Help: Tab/Shift+Tab to navigate, Shift+E to export, q to quit"
+			m.status = "Help: Tab/Shift+Tab to navigate, Shift+T for time range, Shift+E to export, q to quit"
 			return m, nil
 
+		case "T":
+			// Show time range picker (Shift+T), unless T is being typed into an input
+			if m.workingSet != nil && !m.exportModal.IsVisible() && !m.regexPanel.IsEditing() {
+				m.status = "Time range picker opened"
+				return m, m.timePicker.Show(m.workingSet)
+			}
+			cmds = append(cmds, m.forwardKeyToPanel(msg))
+
 		case "E":
-			// Show export modal (Shift+E)
+			// Show export modal (Shift+E), unless E is being typed into an input
+			if m.regexPanel.IsEditing() {
+				cmds = append(cmds, m.forwardKeyToPanel(msg))
+				break
+			}
 			if m.workingSet != nil {
 				cmd := m.exportModal.Show(m.workingSet)
 				m.status = "Export modal opened"
@@ -171,22 +285,7 @@ func (m *AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 
 		default:
-			// Forward message to focused panel and collect commands only if modal is not visible
-			if !m.exportModal.IsVisible() {
-				if m.focused == RegexPanel {
-					var cmd tea.Cmd
-					m.regexPanel, cmd = m.regexPanel.Update(msg)
-					if cmd != nil {
-						cmds = append(cmds, cmd)
-					}
-				} else if m.focused == FileListPanel {
-					var cmd tea.Cmd
-					m.fileListPanel, cmd = m.fileListPanel.Update(msg)
-					if cmd != nil {
-						cmds = append(cmds, cmd)
-					}
-				}
-			}
+			cmds = append(cmds, m.forwardKeyToPanel(msg))
 		}
 	}
 
@@ -219,6 +318,16 @@ func (m *AppModel) View() string {
 	// Create layout
 	content := m.renderLayout()
 
+	// Render time picker overlay if visible
+	if m.timePicker.IsVisible() {
+		return m.timePicker.View()
+	}
+
+	// Render file detail overlay if visible
+	if m.detailPopup.IsVisible() {
+		return m.detailPopup.View()
+	}
+
 	// Render export modal overlay if visible
 	if m.exportModal.IsVisible() {
 		modalView := m.exportModal.View()
@@ -238,21 +347,23 @@ func (m *AppModel) renderLayout() string {
 	statusHeight := 3
 	contentHeight := m.height - headerHeight - statusHeight
 
-	// Split content area into two equal panels side by side
-	panelWidth := m.width / 2
+	// Split content area into three panels side by side
+	panelWidth := m.width / 3
+	histogramWidth := m.width - 2*panelWidth
 
 	// Create header
 	header := m.renderHeader()
 
-	// Create the two working panels
+	// Create the working panels
 	regexPanel := m.renderRegexPanel(panelWidth, contentHeight)
 	fileListPanel := m.renderFileListPanel(panelWidth, contentHeight)
+	histogramPanel := m.renderHistogramPanel(histogramWidth, contentHeight)
 
 	// Create status
 	status := m.renderStatusPanel(m.width, statusHeight)
 
 	// Combine panels side by side
-	content := lipgloss.JoinHorizontal(lipgloss.Top, regexPanel, fileListPanel)
+	content := lipgloss.JoinHorizontal(lipgloss.Top, regexPanel, fileListPanel, histogramPanel)
 
 	// Combine all
 	return lipgloss.JoinVertical(lipgloss.Left, header, content, status)
@@ -276,11 +387,29 @@ func (m *AppModel) renderHeader() string {
 
 	help := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("240")).
-		Render("Tab: Navigate | ?: Help | E: Export | q: Quit")
+		Render("Tab: Navigate | ?: Help | T: Time Range | E: Export | q: Quit")
 
 	return lipgloss.JoinVertical(lipgloss.Left, title, path, help)
 }
 
+// forwardKeyToPanel forwards a key to the focused panel, unless the export modal is visible
+func (m *AppModel) forwardKeyToPanel(msg tea.KeyMsg) tea.Cmd {
+	if m.exportModal.IsVisible() {
+		return nil
+	}
+
+	var cmd tea.Cmd
+	switch m.focused {
+	case RegexPanel:
+		m.regexPanel, cmd = m.regexPanel.Update(msg)
+	case FileListPanel:
+		m.fileListPanel, cmd = m.fileListPanel.Update(msg)
+	case HistogramPanel:
+		m.histogramPanel, cmd = m.histogramPanel.Update(msg)
+	}
+	return cmd
+}
+
 // renderRegexPanel renders the unified regex patterns panel
 func (m *AppModel) renderRegexPanel(width, height int) string {
 	style := m.getPanelStyle(RegexPanel, width, height)
@@ -317,6 +446,24 @@ func (m *AppModel) renderFileListPanel(width, height int) string {
 	return style.Render(content)
 }
 
+// renderHistogramPanel renders the volume histogram panel
+func (m *AppModel) renderHistogramPanel(width, height int) string {
+	style := m.getPanelStyle(HistogramPanel, width, height)
+
+	// Set component size and focus state
+	m.histogramPanel.SetSize(width-4, height-4) // Account for border and padding
+	if m.focused == HistogramPanel {
+		m.histogramPanel.Focus()
+	} else {
+		m.histogramPanel.Blur()
+	}
+
+	// Get the component's view
+	content := m.histogramPanel.View()
+
+	return style.Render(content)
+}
+
 // renderStatusPanel renders the status panel
 func (m *AppModel) renderStatusPanel(width, height int) string {
 	style := lipgloss.NewStyle().
@@ -338,8 +485,22 @@ func (m *AppModel) renderStatusPanel(width, height int) string {
 		statusParts = append(statusParts,
 			fmt.Sprintf("Files: %d/%d selected", selectedCount, totalCount),
 			fmt.Sprintf("Size: %s", formatBytes(m.workingSet.GetSelectedTotalSize())),
-			fmt.Sprintf("Status: %s", m.status),
 		)
+		if m.workingSet.HasTimeFilter() {
+			statusParts = append(statusParts,
+				m.windowSizeStatus(),
+				fmt.Sprintf("Time: %s → %s",
+					m.workingSet.TimeFilter.Start.Format("01-02 15:04:05"),
+					m.workingSet.TimeFilter.End.Format("01-02 15:04:05")))
+		}
+		if m.workingSet.HasLineFilters() {
+			if m.exportSizeReady {
+				statusParts = append(statusParts, fmt.Sprintf("After line filters: ≈%s", formatBytes(m.exportSize.filtered)))
+			} else {
+				statusParts = append(statusParts, "After line filters: estimating...")
+			}
+		}
+		statusParts = append(statusParts, fmt.Sprintf("Status: %s", m.status))
 	}
 
 	return style.Render(strings.Join(statusParts, " | "))
@@ -379,11 +540,101 @@ func (m *AppModel) handleRegexFiltersChange(msg messages.RegexFiltersChangedMsg)
 
 	// Update the working set with the new ordered filter list
 	m.workingSet.SetRegexFilters(msg.Filters)
+	searchCmd := m.syncFileSearches()
 
 	// Apply filtering and broadcast the update
-	m.applyOrderedRegexFiltering()
+	m.applyFilters()
 
 	// Return command to broadcast working set update to other components
+	return tea.Batch(m.broadcastWorkingSetUpdate(), searchCmd)
+}
+
+// fileSearchDoneMsg carries the results of a file match search
+type fileSearchDoneMsg struct {
+	pattern string
+	matches map[string]int64
+	err     error
+}
+
+// syncFileSearches attaches known search results to the file match filters, starts searching
+// for new patterns and cancels searches whose filter was removed
+func (m *AppModel) syncFileSearches() tea.Cmd {
+	wanted := make(map[string]bool)
+	var cmds []tea.Cmd
+
+	for _, filter := range m.workingSet.RegexFilters {
+		if !filter.IsFileMatchFilter() || !filter.Valid || filter.Compiled == nil {
+			continue
+		}
+		wanted[filter.Pattern] = true
+
+		if matches, ok := m.fileMatches[filter.Pattern]; ok {
+			m.workingSet.SetFileMatches(filter.Pattern, matches)
+			continue
+		}
+		if _, running := m.fileSearches[filter.Pattern]; running {
+			continue
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		m.fileSearches[filter.Pattern] = cancel
+		m.status = fmt.Sprintf("Searching file contents for %q...", filter.Pattern)
+
+		pattern, compiled, bundle := filter.Pattern, filter.Compiled, m.workingSet.Bundle
+		cmds = append(cmds, func() tea.Msg {
+			matches, err := m.contentSearcher.SearchBundle(ctx, bundle, compiled)
+			if ctx.Err() != nil {
+				return nil // Cancelled; the filter was removed
+			}
+			return fileSearchDoneMsg{pattern: pattern, matches: matches, err: err}
+		})
+	}
+
+	for pattern, cancel := range m.fileSearches {
+		if !wanted[pattern] {
+			cancel()
+			delete(m.fileSearches, pattern)
+		}
+	}
+
+	return tea.Batch(cmds...)
+}
+
+// handleFileSearchDone records a finished file match search and reselects files with it
+func (m *AppModel) handleFileSearchDone(msg fileSearchDoneMsg) tea.Cmd {
+	if _, running := m.fileSearches[msg.pattern]; !running {
+		return nil // Filter removed while the search finished
+	}
+	delete(m.fileSearches, msg.pattern)
+
+	if msg.err != nil {
+		m.status = fmt.Sprintf("Content search for %q failed: %v", msg.pattern, msg.err)
+		return nil
+	}
+
+	m.fileMatches[msg.pattern] = msg.matches
+	m.regexPanel.SetFileMatchCount(msg.pattern, len(msg.matches))
+	m.workingSet.SetFileMatches(msg.pattern, msg.matches)
+	m.applyFilters()
+	m.status = fmt.Sprintf("Found %q in %d files", msg.pattern, len(msg.matches))
+
+	return m.broadcastWorkingSetUpdate()
+}
+
+// handleTimeFilterChange sets or clears the time window and re-applies all filter stages
+func (m *AppModel) handleTimeFilterChange(msg messages.TimeFilterChangedMsg) tea.Cmd {
+	if m.workingSet == nil {
+		return nil
+	}
+
+	if msg.TimeRange == nil {
+		m.workingSet.ClearTimeFilter()
+	} else {
+		m.workingSet.SetTimeFilter(msg.TimeRange)
+	}
+
+	m.applyFilters()
+
 	return m.broadcastWorkingSetUpdate()
 }
 
@@ -401,6 +652,7 @@ func (m *AppModel) broadcastWorkingSetUpdate() tea.Cmd {
 
 	// Get all files sorted by size for the file list component
 	allFiles := m.workingSet.GetSelectedFilesBySize(0)
+	matchCounts := m.workingSet.FileMatchCounts()
 
 	// Return batched commands for different components
 	return tea.Batch(
@@ -414,14 +666,84 @@ func (m *AppModel) broadcastWorkingSetUpdate() tea.Cmd {
 		},
 		func() tea.Msg {
 			return filelist.FileListDataMsg{
-				Files:      allFiles,
-				TotalSize:  totalSize,
-				TotalFiles: selectedCount,
+				Files:       allFiles,
+				TotalSize:   totalSize,
+				TotalFiles:  selectedCount,
+				MatchCounts: matchCounts,
 			}
 		},
 	)
 }
 
+// explainDoneMsg carries the classification details of a file, read in the background
+type explainDoneMsg struct {
+	file      models.FileInfo
+	detection *scanner.Detection
+	err       error
+}
+
+// explainFile classifies a file again in the background, keeping the details for the detail popup
+func (m *AppModel) explainFile(file models.FileInfo) tea.Cmd {
+	if m.workingSet == nil || m.workingSet.Bundle == nil {
+		return nil
+	}
+
+	bundle := m.workingSet.Bundle
+	return func() tea.Msg {
+		detection, err := scanner.NewBundleScanner(bundle.GetFilesystem()).ExplainFile(bundle.GetAbsolutePath(file.Path))
+		return explainDoneMsg{file: file, detection: detection, err: err}
+	}
+}
+
+// windowSizeStatus describes the bytes inside the time window. Until the window has been
+// located in each file it is interpolated from the files' time ranges, marked as approximate.
+func (m *AppModel) windowSizeStatus() string {
+	if m.exportSizeReady {
+		return fmt.Sprintf("In window: %s", formatBytes(m.exportSize.window))
+	}
+	return fmt.Sprintf("In window: ≈%s", formatBytes(m.workingSet.GetSelectedWindowSize()))
+}
+
+// exportSizeMsg carries the background measurement of the export size
+type exportSizeMsg struct {
+	generation int
+	window     int64 // Bytes inside the time window, as the export trims them
+	filtered   int64 // Estimated bytes left after line filters
+	err        error
+}
+
+// estimateExportSize starts measuring, on a snapshot of the working set, how many bytes an
+// export writes: the time window is located the way the export trims it, and the share kept
+// by the line filters is estimated. Any measurement still running is cancelled.
+func (m *AppModel) estimateExportSize() tea.Cmd {
+	if m.estimateCancel != nil {
+		m.estimateCancel()
+		m.estimateCancel = nil
+	}
+	m.estimateGeneration++
+	m.exportSizeReady = false
+
+	if m.workingSet == nil || (!m.workingSet.HasTimeFilter() && !m.workingSet.HasLineFilters()) {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.estimateCancel = cancel
+	generation := m.estimateGeneration
+	snapshot := m.workingSet.Snapshot()
+
+	return func() tea.Msg {
+		summary, err := m.exportService.GetExportSummaryContext(ctx, snapshot, "")
+		if ctx.Err() != nil {
+			return nil // Cancelled; a newer estimate is on its way
+		}
+		if err != nil {
+			return exportSizeMsg{generation: generation, err: err}
+		}
+		return exportSizeMsg{generation: generation, window: summary.TotalSize, filtered: summary.FilteredSize}
+	}
+}
+
 // getFilteredFileNames returns the names of files that pass the current filters
 func (m *AppModel) getFilteredFileNames() []string {
 	if m.workingSet == nil || m.workingSet.Bundle == nil {
@@ -437,8 +759,32 @@ func (m *AppModel) getFilteredFileNames() []string {
 	return filteredFiles
 }
 
+// applyFilters runs every selection stage in order: regex filters first, then the time window
+func (m *AppModel) applyFilters() {
+	m.applyOrderedRegexFiltering()
+	m.applyTimeFiltering()
+}
+
+// applyTimeFiltering deselects files whose time range does not overlap the time filter.
+// Files without a known time range are left as the regex stage selected them.
+func (m *AppModel) applyTimeFiltering() {
+	if m.workingSet == nil || m.workingSet.Bundle == nil || !m.workingSet.HasTimeFilter() {
+		return
+	}
+
+	for i := range m.workingSet.Bundle.Files {
+		file := &m.workingSet.Bundle.Files[i]
+		if m.workingSet.IsFileSelected(file.Path) && !m.workingSet.PassesTimeFilter(file) {
+			m.workingSet.SetFileSelection(file.Path, false)
+		}
+	}
+}
+
 // applyOrderedRegexFiltering applies regex filters in order (take/exclude)
 // Files are selected IF AND ONLY IF the last regex that matched them was an include regex.
+// Path filters match file paths, file match filters the searched contents, format filters
+// the detected formats and kind filters the sniffed kinds; content filters don't select files,
+// they are applied to lines on export.
 func (m *AppModel) applyOrderedRegexFiltering() {
 	if m.workingSet == nil || m.workingSet.Bundle == nil {
 		return
@@ -454,13 +800,15 @@ func (m *AppModel) applyOrderedRegexFiltering() {
 
 	// Apply each regex filter in order, tracking the last match for each file
 	for _, filter := range m.workingSet.RegexFilters {
-		if !filter.Valid || filter.Compiled == nil {
+		if !filter.Valid || filter.Compiled == nil || filter.IsContentFilter() {
 			continue
 		}
 
-		for _, file := range m.workingSet.Bundle.Files {
-			// Check if the pattern matches this file path
-			if filter.Compiled.MatchString(file.Path) {
+		for i := range m.workingSet.Bundle.Files {
+			file := &m.workingSet.Bundle.Files[i]
+
+			// Check if the pattern matches this file
+			if filter.MatchesFile(file) {
 				// Track this as the last matching regex for this file
 				filterCopy := filter // Make a copy to store in the map
 				lastMatchingRegex[file.Path] = &filterCopy
@@ -0,0 +1,224 @@
+package detail
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/cheerioskun/logninja/internal/models"
+	"github.com/cheerioskun/logninja/internal/scanner"
+)
+
+// Styling
+var (
+	modalStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("62")).
+			Padding(1, 2).
+			Background(lipgloss.Color("235")).
+			Foreground(lipgloss.Color("255"))
+
+	titleStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("39"))
+
+	sectionStyle = lipgloss.NewStyle().
+			Bold(true).
+			Margin(1, 0, 0, 0)
+
+	sampleStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("245"))
+
+	chosenStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("46"))
+
+	failureStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("196"))
+
+	helpStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("241")).
+			Italic(true).
+			Margin(1, 0, 0, 0)
+)
+
+// modalWidth is the width of the popup, borders included
+const modalWidth = 96
+
+// Model is a popup showing how a file was classified: its sampled lines, how each timestamp
+// pattern fared on them, and which detection threshold failed
+type Model struct {
+	visible bool
+	width   int
+	height  int
+
+	file      models.FileInfo
+	detection *scanner.Detection
+	err       error
+}
+
+// NewModel creates a hidden detail popup
+func NewModel() *Model {
+	return &Model{}
+}
+
+// Show displays the classification details of a file; err is shown instead if it could not be read
+func (m *Model) Show(file models.FileInfo, detection *scanner.Detection, err error) {
+	m.visible = true
+	m.file = file
+	m.detection = detection
+	m.err = err
+}
+
+// Hide hides the popup
+func (m *Model) Hide() {
+	m.visible = false
+	m.detection = nil
+	m.err = nil
+}
+
+// IsVisible returns true if the popup is visible
+func (m *Model) IsVisible() bool {
+	return m.visible
+}
+
+// SetSize sets the available screen size
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// Update closes the popup on Esc, Enter, i or q; it captures every other key while open
+func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.visible {
+		switch keyMsg.String() {
+		case "esc", "enter", "i", "q":
+			m.Hide()
+		}
+	}
+	return m, nil
+}
+
+// View renders the popup
+func (m *Model) View() string {
+	if !m.visible {
+		return ""
+	}
+
+	innerWidth := modalWidth - 6 // Border and padding
+	var parts []string
+	parts = append(parts, titleStyle.Render(truncate("File Detail: "+m.file.Path, innerWidth)))
+	parts = append(parts, m.renderFileInfo())
+
+	if m.err != nil {
+		parts = append(parts, failureStyle.Render(m.err.Error()))
+	} else if m.detection != nil && m.detection.Content.Binary {
+		parts = append(parts, "\n"+failureStyle.Render("Result: not a log file"))
+		for _, failure := range m.detection.Failures {
+			parts = append(parts, failureStyle.Render(truncate("  - "+failure, innerWidth)))
+		}
+	} else if m.detection != nil {
+		parts = append(parts, m.renderDetection(innerWidth)...)
+	}
+
+	parts = append(parts, helpStyle.Render("Esc: Close"))
+
+	content := modalStyle.Width(modalWidth).Render(strings.Join(parts, "\n"))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
+}
+
+// renderFileInfo summarises what the scan recorded for the file
+func (m *Model) renderFileInfo() string {
+	size := formatBytes(m.file.Size)
+	if m.file.IsCompressed() {
+		size += fmt.Sprintf(" (%s, %s uncompressed)", m.file.Compression, formatBytes(m.file.UncompressedSize))
+	}
+
+	kind := string(m.file.Kind)
+	if m.file.Encoding != "" {
+		kind += ", " + m.file.Encoding
+	}
+
+	lines := []string{"Size: " + size, "Kind: " + kind}
+	if m.file.IsLogFile {
+		lines = append(lines, fmt.Sprintf("Format: %s (%.0f%% confidence), %d lines, %d entries",
+			m.file.Format(), m.file.FormatConfidence*100, m.file.EstimatedLines, m.file.EntryCount))
+	} else {
+		lines = append(lines, "Format: "+models.UndetectedFormat)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderDetection shows the sample, the pattern scores and the verdict
+func (m *Model) renderDetection(innerWidth int) []string {
+	detection := m.detection
+	var parts []string
+
+	parts = append(parts, sectionStyle.Render(fmt.Sprintf("Sampled %d lines", detection.LineCount)))
+	var sample []string
+	for i, line := range detection.Sample {
+		sample = append(sample, truncate(fmt.Sprintf("%3d │ %s", i+1, line), innerWidth))
+	}
+	if len(sample) > 0 {
+		parts = append(parts, sampleStyle.Render(strings.Join(sample, "\n")))
+	}
+
+	var scores []string
+	unmatched := 0
+	for _, score := range detection.Scores {
+		if score.Matches == 0 {
+			unmatched++
+			continue
+		}
+		line := fmt.Sprintf("  %-32s %5s matched %5d parsed", score.Pattern.Name,
+			fmt.Sprintf("%d/%d", score.Matches, detection.LineCount), score.Parsed)
+		if detection.Best != nil && score.Pattern == detection.Best.Pattern {
+			line = chosenStyle.Render("*" + line[1:])
+		}
+		scores = append(scores, line)
+	}
+	if unmatched > 0 {
+		scores = append(scores, fmt.Sprintf("  (%d other patterns matched nothing)", unmatched))
+	}
+	parts = append(parts, sectionStyle.Render("Patterns"), strings.Join(scores, "\n"))
+
+	verdict := []string{fmt.Sprintf("Thresholds: min_matches %d, min_confidence %.0f%%",
+		detection.Thresholds.MinMatches, detection.Thresholds.MinConfidence*100)}
+	if detection.Best != nil {
+		verdict = append([]string{fmt.Sprintf("Chosen pattern: %s (%.0f%% confidence)",
+			detection.Best.Pattern.Name, detection.Confidence*100)}, verdict...)
+	}
+	if detection.IsLogFile() {
+		verdict = append(verdict, chosenStyle.Render("Result: log file"))
+	} else {
+		verdict = append(verdict, failureStyle.Render("Result: not a log file"))
+		for _, failure := range detection.Failures {
+			verdict = append(verdict, failureStyle.Render(truncate("  - "+failure, innerWidth)))
+		}
+	}
+	parts = append(parts, "\n"+strings.Join(verdict, "\n"))
+
+	return parts
+}
+
+// truncate shortens text to at most width runes
+func truncate(text string, width int) string {
+	runes := []rune(text)
+	if len(runes) <= width {
+		return text
+	}
+	return string(runes[:width-3]) + "..."
+}
+
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
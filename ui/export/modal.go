@@ -1,6 +1,7 @@
 package export
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -79,6 +80,10 @@ type Model struct {
 	exportSummary  *export.ExportSummary
 	errorMessage   string
 	successMessage string
+
+	// Background calculation of the export summary
+	summaryGeneration int
+	summaryCancel     context.CancelFunc
 }
 
 // ExportModalConfirmedMsg is sent when user confirms export
@@ -127,12 +132,13 @@ func (m *Model) Show(ws *models.WorkingSet) tea.Cmd {
 	m.textInput.SetValue(defaultPath)
 	m.textInput.Focus()
 
-	// Calculate export summary
+	// Calculate export summary in the background
 	return m.updateSummary()
 }
 
 // Hide hides the modal
 func (m *Model) Hide() {
+	m.cancelSummary()
 	m.visible = false
 	m.textInput.Blur()
 	m.state = StateInput
@@ -169,11 +175,9 @@ func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
 				m.Hide()
 				return m, func() tea.Msg { return ExportModalCancelledMsg{} }
 			default:
+				// The summary doesn't depend on the destination, so typing doesn't recalculate it
 				m.textInput, cmd = m.textInput.Update(msg)
 				cmds = append(cmds, cmd)
-
-				// Update summary when path changes
-				cmds = append(cmds, m.updateSummary())
 			}
 		case StateExporting:
 			// Don't handle input while exporting
@@ -195,6 +199,18 @@ func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
 			}
 		}
 
+	case summaryMsg:
+		if msg.generation != m.summaryGeneration {
+			return m, nil // Superseded, or the modal was closed
+		}
+		m.summaryCancel = nil
+		if msg.err != nil {
+			m.errorMessage = fmt.Sprintf("Failed to calculate export summary: %v", msg.err)
+			return m, nil
+		}
+		m.exportSummary = msg.summary
+		return m, nil
+
 	case exportSyncMsg:
 		// Perform the export synchronously and update state directly
 		if msg.modal.workingSet == nil {
@@ -301,7 +317,17 @@ func (m *Model) renderInputState() string {
 	if m.exportSummary != nil {
 		preview := fmt.Sprintf("Files to export: %d\nTotal size: %s",
 			m.exportSummary.FileCount, formatBytes(m.exportSummary.TotalSize))
+		if m.exportSummary.TimeRange != nil {
+			preview += fmt.Sprintf("\nTime window: %s\nTrimmed to window: %d files",
+				m.exportSummary.TimeRange.String(), m.exportSummary.TrimmedFileCount)
+		}
+		if m.exportSummary.LineFilterCount > 0 {
+			preview += fmt.Sprintf("\nLine filters: %d (≈%s kept)",
+				m.exportSummary.LineFilterCount, formatBytes(m.exportSummary.FilteredSize))
+		}
 		parts = append(parts, previewStyle.Render(preview))
+	} else if m.summaryCancel != nil {
+		parts = append(parts, previewStyle.Render("Calculating export summary..."))
 	}
 
 	// Input
@@ -360,6 +386,13 @@ func (m *Model) confirmExport() (*Model, tea.Cmd) {
 		m.errorMessage = err.Error()
 		return m, nil
 	}
+	if m.exportSummary == nil && m.summaryCancel != nil {
+		m.errorMessage = "Still calculating the export summary, try again in a moment"
+		return m, nil
+	}
+	if m.exportSummary != nil {
+		m.exportSummary.DestinationPath = destPath
+	}
 
 	// Clear error and start export
 	m.errorMessage = ""
@@ -369,22 +402,45 @@ func (m *Model) confirmExport() (*Model, tea.Cmd) {
 	return m, m.performExportSync(destPath)
 }
 
-// updateSummary updates the export summary
+// summaryMsg carries an export summary calculated in the background
+type summaryMsg struct {
+	generation int
+	summary    *export.ExportSummary
+	err        error
+}
+
+// updateSummary starts calculating the export summary on a snapshot of the working set,
+// off the UI goroutine since it locates time windows and samples line filters. Any
+// calculation still running is cancelled.
 func (m *Model) updateSummary() tea.Cmd {
+	m.cancelSummary()
+	m.exportSummary = nil
 	if m.workingSet == nil {
 		return nil
 	}
 
-	// Update summary synchronously instead of via command
+	ctx, cancel := context.WithCancel(context.Background())
+	m.summaryCancel = cancel
+	generation := m.summaryGeneration
+	snapshot := m.workingSet.Snapshot()
 	destPath := strings.TrimSpace(m.textInput.Value())
-	if destPath != "" {
-		summary, err := m.exportService.GetExportSummary(m.workingSet, destPath)
-		if err == nil {
-			m.exportSummary = summary
+
+	return func() tea.Msg {
+		summary, err := m.exportService.GetExportSummaryContext(ctx, snapshot, destPath)
+		if ctx.Err() != nil {
+			return nil // Cancelled; a newer summary is on its way or the modal was closed
 		}
+		return summaryMsg{generation: generation, summary: summary, err: err}
 	}
+}
 
-	return nil
+// cancelSummary stops a running summary calculation and discards its result
+func (m *Model) cancelSummary() {
+	if m.summaryCancel != nil {
+		m.summaryCancel()
+		m.summaryCancel = nil
+	}
+	m.summaryGeneration++
 }
 
 // performExportSync performs the export operation and updates modal state directly
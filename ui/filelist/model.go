@@ -15,22 +15,25 @@ import (
 // Model represents the file list component sorted by size
 type Model struct {
 	// Data
-	files      []models.FileInfo
-	totalSize  int64
-	totalFiles int
+	files       []models.FileInfo
+	totalSize   int64
+	totalFiles  int
+	matchCounts map[string]int64 // Matching lines per file, nil without file match filters
 
 	// UI state
+	cursor   int // Index of the highlighted file
 	focused  bool
 	width    int
 	height   int
 	viewport viewport.Model
 
 	// Styles
-	titleStyle lipgloss.Style
-	fileStyle  lipgloss.Style
-	sizeStyle  lipgloss.Style
-	emptyStyle lipgloss.Style
-	helpStyle  lipgloss.Style
+	titleStyle  lipgloss.Style
+	fileStyle   lipgloss.Style
+	cursorStyle lipgloss.Style
+	sizeStyle   lipgloss.Style
+	emptyStyle  lipgloss.Style
+	helpStyle   lipgloss.Style
 }
 
 // NewModel creates a new file list model
@@ -55,6 +58,10 @@ func NewModel() *Model {
 		fileStyle: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("255")),
 
+		cursorStyle: lipgloss.NewStyle().
+			Background(lipgloss.Color("205")).
+			Foreground(lipgloss.Color("0")),
+
 		sizeStyle: lipgloss.NewStyle().
 			Foreground(lipgloss.Color("240")).
 			Align(lipgloss.Right),
@@ -85,6 +92,8 @@ func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
 		m.files = msg.Files
 		m.totalSize = msg.TotalSize
 		m.totalFiles = msg.TotalFiles
+		m.matchCounts = msg.MatchCounts
+		m.moveCursor(0) // Keep the cursor on the list as it shrinks
 
 		// Update viewport content
 		m.updateViewportContent()
@@ -94,18 +103,25 @@ func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
 		if m.focused {
 			switch msg.String() {
 			case "j", "down":
-				m.viewport.LineDown(1)
+				m.moveCursor(1)
 			case "k", "up":
-				m.viewport.LineUp(1)
+				m.moveCursor(-1)
 			case "pgdown", " ":
-				m.viewport.ViewDown()
+				m.moveCursor(m.viewport.Height)
 			case "pgup":
-				m.viewport.ViewUp()
+				m.moveCursor(-m.viewport.Height)
 			case "home", "g":
-				m.viewport.GotoTop()
+				m.moveCursor(-len(m.files))
 			case "end", "G":
-				m.viewport.GotoBottom()
+				m.moveCursor(len(m.files))
+			case "enter", "i":
+				if m.cursor < len(m.files) {
+					file := m.files[m.cursor]
+					return m, func() tea.Msg { return ExplainFileMsg{File: file} }
+				}
 			}
+			m.updateViewportContent()
+			return m, nil
 		}
 	}
 
@@ -142,6 +158,7 @@ func (m *Model) View() string {
 			"↑/↓/j/k: Navigate",
 			"PgUp/PgDn: Page",
 			"Home/End: First/Last",
+			"i/Enter: Explain",
 		}
 		help = m.helpStyle.Render(strings.Join(helpItems, " • "))
 	}
@@ -153,6 +170,23 @@ func (m *Model) View() string {
 	return lipgloss.JoinVertical(lipgloss.Left, header, content, summary)
 }
 
+// moveCursor moves the highlighted file by delta, scrolling the viewport to keep it in view
+func (m *Model) moveCursor(delta int) {
+	m.cursor += delta
+	if m.cursor >= len(m.files) {
+		m.cursor = len(m.files) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+
+	if m.cursor < m.viewport.YOffset {
+		m.viewport.SetYOffset(m.cursor)
+	} else if m.viewport.Height > 0 && m.cursor >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(m.cursor - m.viewport.Height + 1)
+	}
+}
+
 // updateViewportContent updates the viewport with the current file list content
 func (m *Model) updateViewportContent() {
 	if len(m.files) == 0 {
@@ -171,7 +205,10 @@ func (m *Model) renderFileListContent() string {
 	for i, file := range m.files {
 		// Format filename (truncate if too long to fit in viewport)
 		filename := filepath.Base(file.Path)
-		maxFilenameWidth := m.width - 30 // Leave space for size and percentage
+		maxFilenameWidth := m.width - 32 // Leave space for kind, size and percentage
+		if m.matchCounts != nil {
+			maxFilenameWidth -= 9 // And the match count
+		}
 		if maxFilenameWidth < 10 {
 			maxFilenameWidth = 10
 		}
@@ -186,13 +223,20 @@ func (m *Model) renderFileListContent() string {
 		percentage := float64(file.Size) / float64(m.totalSize) * 100
 
 		// Format line without progress bars, just percentage
-		line := fmt.Sprintf("%d. %-*s %8s %5.1f%%",
+		line := fmt.Sprintf("%d. %-*s %-8s %8s %5.1f%%",
 			i+1,
 			maxFilenameWidth,
 			filename,
+			file.KindLabel(),
 			sizeStr,
 			percentage,
 		)
+		if m.matchCounts != nil {
+			line += fmt.Sprintf(" %8s", fmt.Sprintf("(%d)", m.matchCounts[file.Path]))
+		}
+		if m.focused && i == m.cursor {
+			line = m.cursorStyle.Render(line)
+		}
 
 		lines = append(lines, line)
 	}
@@ -206,10 +250,10 @@ func (m *Model) renderSummary() string {
 		return ""
 	}
 
-	// Add scroll position indicator
+	// Add cursor position indicator
 	scrollInfo := ""
 	if len(m.files) > 0 && m.viewport.Height > 0 {
-		scrollInfo = fmt.Sprintf(" • %d/%d", m.viewport.YOffset+1, len(m.files))
+		scrollInfo = fmt.Sprintf(" • %d/%d", m.cursor+1, len(m.files))
 	}
 
 	summary := fmt.Sprintf("Total: %s • %d files%s",
@@ -217,6 +261,13 @@ func (m *Model) renderSummary() string {
 		m.totalFiles,
 		scrollInfo,
 	)
+	if m.matchCounts != nil {
+		var matches int64
+		for _, file := range m.files {
+			matches += m.matchCounts[file.Path]
+		}
+		summary += fmt.Sprintf(" • (%d matching lines)", matches)
+	}
 
 	return m.sizeStyle.Render(summary)
 }
@@ -224,11 +275,19 @@ func (m *Model) renderSummary() string {
 // Component interface methods
 
 func (m *Model) Focus() {
-	m.focused = true
+	m.setFocused(true)
 }
 
 func (m *Model) Blur() {
-	m.focused = false
+	m.setFocused(false)
+}
+
+// setFocused changes the focus state, redrawing the list since the cursor only shows when focused
+func (m *Model) setFocused(focused bool) {
+	if m.focused != focused {
+		m.focused = focused
+		m.updateViewportContent()
+	}
 }
 
 func (m *Model) IsFocused() bool {
@@ -255,11 +314,17 @@ func (m *Model) SetSize(width, height int) {
 	}
 }
 
+// ExplainFileMsg asks for the details of how a file was classified
+type ExplainFileMsg struct {
+	File models.FileInfo
+}
+
 // FileListDataMsg is a custom message containing file data for this component
 type FileListDataMsg struct {
-	Files      []models.FileInfo
-	TotalSize  int64
-	TotalFiles int
+	Files       []models.FileInfo
+	TotalSize   int64
+	TotalFiles  int
+	MatchCounts map[string]int64 // Matching lines per file of the file match filters, nil if none
 }
 
 // formatBytes formats byte counts in human-readable format
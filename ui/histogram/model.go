@@ -1,18 +1,19 @@
 package histogram
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/cheerioskun/logninja/internal/messages"
 	"github.com/cheerioskun/logninja/internal/models"
 	"github.com/cheerioskun/logninja/internal/parser"
 	"github.com/spf13/afero"
 )
 
-var (
-	Disabled = true
-)
+// sourceComponent identifies this panel in TimeFilterChangedMsg
+const sourceComponent = "histogram"
 
 // Model represents the histogram panel state
 type Model struct {
@@ -23,6 +24,10 @@ type Model struct {
 	loading    bool
 	lastUpdate time.Time
 
+	// Background generation; only results of the latest generation are shown
+	generation int
+	cancel     context.CancelFunc
+
 	// Volume analyzer
 	volumeAnalyzer *parser.VolumeAnalyzer
 
@@ -31,8 +36,14 @@ type Model struct {
 	width   int
 	height  int
 
+	// Brush selection: cursor is the highlighted bin, anchor the bin where the
+	// pending selection started (-1 when not selecting)
+	cursor int
+	anchor int
+
 	// Display options
 	showFiles   bool // Show file count per bin
+	showEntries bool // Plot entry counts instead of bytes
 	maxBarWidth int  // Maximum width for histogram bars
 
 	// Status
@@ -52,16 +63,13 @@ func NewModel(fs afero.Fs) *Model {
 		height:         20,
 		showFiles:      false,
 		maxBarWidth:    30,
+		anchor:         -1,
 		status:         "Ready",
 	}
 }
 
 // Update handles messages for the histogram panel
 func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
-	if Disabled {
-		return m, nil
-	}
-
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if !m.focused {
@@ -76,6 +84,10 @@ func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
 			// Toggle file count display
 			m.showFiles = !m.showFiles
 			return m, nil
+		case "l":
+			// Toggle between entry and byte volume
+			m.showEntries = !m.showEntries
+			return m, nil
 		case "+", "=":
 			// Increase bin count
 			if m.binCount < 50 {
@@ -88,11 +100,53 @@ func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
 				m.binCount -= 5
 				return m, m.generateHistogram()
 			}
+		case "up", "k":
+			m.moveCursor(-1)
+		case "down", "j":
+			m.moveCursor(1)
+		case "home", "g":
+			m.moveCursor(-len(m.volumeData))
+		case "end", "G":
+			m.moveCursor(len(m.volumeData))
+		case " ":
+			// First press marks the start bin, second press applies the window
+			if len(m.volumeData) == 0 {
+				return m, nil
+			}
+			if m.anchor < 0 {
+				m.anchor = m.cursor
+				return m, nil
+			}
+			return m, m.applySelection()
+		case "enter":
+			// Apply the pending selection, or just the bin under the cursor
+			if len(m.volumeData) == 0 {
+				return m, nil
+			}
+			if m.anchor < 0 {
+				m.anchor = m.cursor
+			}
+			return m, m.applySelection()
+		case "esc":
+			// Abandon the pending selection
+			m.anchor = -1
+		case "c":
+			// Clear the time filter
+			m.anchor = -1
+			if m.workingSet != nil && m.workingSet.HasTimeFilter() {
+				return m, emitTimeFilter(nil)
+			}
 		}
 
 	case HistogramDataMsg:
-		// Received new histogram data
+		if msg.Generation != m.generation {
+			return m, nil // Superseded by a newer generation
+		}
+
+		// Received new histogram data; bins may have moved, so drop any pending selection
 		m.volumeData = msg.VolumeData
+		m.anchor = -1
+		m.moveCursor(0)
 		m.loading = false
 		m.lastUpdate = time.Now()
 		m.status = fmt.Sprintf("Updated at %s (%d bins)",
@@ -101,19 +155,16 @@ func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
 		return m, nil
 
 	case HistogramErrorMsg:
+		if msg.Generation != m.generation {
+			return m, nil // Superseded by a newer generation
+		}
+
 		// Error generating histogram
 		m.loading = false
 		m.error = msg.Error
 		m.status = "Error generating histogram"
 		return m, nil
 
-	case HistogramLoadingMsg:
-		// Started generating histogram
-		m.loading = true
-		m.status = "Generating histogram..."
-		m.error = ""
-		return m, nil
-
 	case WorkingSetUpdatedMsg:
 		// Working set changed, update our reference
 		m.workingSet = msg.WorkingSet
@@ -125,10 +176,6 @@ func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
 
 // View renders the histogram panel
 func (m *Model) View() string {
-	if Disabled {
-		return ""
-	}
-
 	if m.loading {
 		return m.renderLoading()
 	}
@@ -178,39 +225,112 @@ func (m *Model) GetVolumeData() []models.VolumePoint {
 	return m.volumeData
 }
 
-// generateHistogram creates a command to generate histogram data
+// moveCursor moves the bin cursor by delta, clamped to the available bins
+func (m *Model) moveCursor(delta int) {
+	m.cursor += delta
+	if m.cursor >= len(m.volumeData) {
+		m.cursor = len(m.volumeData) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// selectionBounds returns the first and last bin of the pending selection, or -1, -1 if none
+func (m *Model) selectionBounds() (int, int) {
+	if m.anchor < 0 || len(m.volumeData) == 0 {
+		return -1, -1
+	}
+	if m.anchor < m.cursor {
+		return m.anchor, m.cursor
+	}
+	return m.cursor, m.anchor
+}
+
+// applySelection turns the pending selection into the working set's time filter
+func (m *Model) applySelection() tea.Cmd {
+	first, last := m.selectionBounds()
+	m.anchor = -1
+	if first < 0 {
+		return nil
+	}
+
+	timeRange, err := models.NewTimeRange(m.volumeData[first].BinStart, m.volumeData[last].BinEnd)
+	if err != nil {
+		m.error = err.Error()
+		return nil
+	}
+	return emitTimeFilter(timeRange)
+}
+
+// emitTimeFilter creates a command announcing a new time filter
+func emitTimeFilter(timeRange *models.TimeRange) tea.Cmd {
+	return func() tea.Msg {
+		return messages.TimeFilterChangedMsg{
+			TimeRange:       timeRange,
+			SourceComponent: sourceComponent,
+		}
+	}
+}
+
+// generateHistogram starts generating histogram data in the background.
+// Any generation still running is cancelled, and it works on a snapshot of the
+// working set so the UI can keep changing the selection meanwhile.
 func (m *Model) generateHistogram() tea.Cmd {
 	if m.workingSet == nil {
 		return nil
 	}
 
-	return tea.Batch(
-		func() tea.Msg { return HistogramLoadingMsg{} },
-		func() tea.Msg {
-			volumeData, err := m.volumeAnalyzer.GenerateHistogram(m.workingSet, m.binCount)
-			if err != nil {
-				return HistogramErrorMsg{Error: err.Error()}
-			}
-			return HistogramDataMsg{VolumeData: volumeData}
-		},
-	)
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+	m.generation++
+	generation := m.generation
+
+	if m.workingSet.GetSelectedFileCount() == 0 {
+		m.loading = false
+		m.volumeData = make([]models.VolumePoint, 0)
+		m.error = ""
+		m.status = "No files selected"
+		return nil
+	}
+
+	m.loading = true
+	m.status = "Generating histogram..."
+	m.error = ""
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	snapshot := m.workingSet.Snapshot()
+	binCount := m.binCount
+
+	return func() tea.Msg {
+		volumeData, err := m.volumeAnalyzer.GenerateHistogramContext(ctx, snapshot, binCount)
+		if ctx.Err() != nil {
+			return nil // Cancelled; a newer generation is on its way
+		}
+		if err != nil {
+			return HistogramErrorMsg{Generation: generation, Error: err.Error()}
+		}
+		return HistogramDataMsg{Generation: generation, VolumeData: volumeData}
+	}
 }
 
 // Messages for histogram component
 
 // HistogramDataMsg contains new histogram data
 type HistogramDataMsg struct {
+	Generation int
 	VolumeData []models.VolumePoint
 }
 
 // HistogramErrorMsg contains histogram generation error
 type HistogramErrorMsg struct {
-	Error string
+	Generation int
+	Error      string
 }
 
-// HistogramLoadingMsg indicates histogram generation started
-type HistogramLoadingMsg struct{}
-
 // WorkingSetUpdatedMsg indicates the working set changed
 type WorkingSetUpdatedMsg struct {
 	WorkingSet *models.WorkingSet
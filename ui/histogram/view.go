@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/cheerioskun/logninja/internal/models"
 )
 
 // Styles for histogram rendering
@@ -20,6 +21,18 @@ var (
 	emptyBarStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("240"))
 
+	// Gutter marking bins inside the active time filter
+	windowStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214"))
+
+	// Bars inside the selection being brushed
+	brushBarStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("212"))
+
+	cursorStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("205"))
+
 	labelStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("250"))
 
@@ -31,21 +44,50 @@ var (
 
 	statusStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("111"))
+
+	// Bar segment colours per log level
+	levelStyles = map[models.LogLevel]lipgloss.Style{
+		models.LevelFatal:   lipgloss.NewStyle().Foreground(lipgloss.Color("201")),
+		models.LevelError:   lipgloss.NewStyle().Foreground(lipgloss.Color("196")),
+		models.LevelWarn:    lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+		models.LevelInfo:    barStyle,
+		models.LevelDebug:   lipgloss.NewStyle().Foreground(lipgloss.Color("39")),
+		models.LevelTrace:   lipgloss.NewStyle().Foreground(lipgloss.Color("141")),
+		models.LevelUnknown: lipgloss.NewStyle().Foreground(lipgloss.Color("250")),
+	}
+
+	// Single-letter level names for the legend
+	levelLetters = map[models.LogLevel]string{
+		models.LevelFatal:   "F",
+		models.LevelError:   "E",
+		models.LevelWarn:    "W",
+		models.LevelInfo:    "I",
+		models.LevelDebug:   "D",
+		models.LevelTrace:   "T",
+		models.LevelUnknown: "?",
+	}
 )
 
 // renderHistogram renders the main histogram view
 func (m *Model) renderHistogram() string {
 	var parts []string
 
-	// Title - always showing bytes now
-	title := titleStyle.Render("Log Volume Histogram (Bytes)")
+	// Title names the plotted unit and the zone of the time axis
+	unit := "Bytes"
+	if m.showEntries {
+		unit = "Entries"
+	}
+	title := titleStyle.Render(fmt.Sprintf("Log Volume Histogram (%s, %s)", unit, models.DisplayLocation()))
 	parts = append(parts, title)
 
 	// Histogram bars
 	histogram := m.renderHistogramBars()
 	parts = append(parts, histogram)
 
-	// Status and help
+	// Level legend, status and help
+	if legend := m.renderLegend(); legend != "" {
+		parts = append(parts, legend)
+	}
 	status := m.renderStatus()
 	help := m.renderHelp()
 	parts = append(parts, status, help)
@@ -62,7 +104,7 @@ func (m *Model) renderHistogramBars() string {
 	// Calculate maximum value for scaling
 	var maxValue int64
 	for _, point := range m.volumeData {
-		value := point.Size // Always use bytes now
+		value := m.pointValue(point)
 		if value > maxValue {
 			maxValue = value
 		}
@@ -73,28 +115,53 @@ func (m *Model) renderHistogramBars() string {
 	}
 
 	var lines []string
-	availableHeight := m.height - 6 // Reserve space for title, status, help
-	barsToShow := len(m.volumeData)
+	availableHeight := m.height - 8 // Reserve space for title, status, legend, help
+	if availableHeight < 1 {
+		availableHeight = 1
+	}
 
-	// Limit number of bars if too many for height
+	// Scroll so the cursor stays visible when there are more bins than rows
+	offset := 0
+	barsToShow := len(m.volumeData)
 	if barsToShow > availableHeight {
 		barsToShow = availableHeight
+		if m.cursor >= barsToShow {
+			offset = m.cursor - barsToShow + 1
+		}
 	}
 
 	// Calculate time range for labels
 	timeRange := m.getTimeRange()
+	brushFirst, brushLast := m.selectionBounds()
 
-	for i := 0; i < barsToShow; i++ {
+	for i := offset; i < offset+barsToShow; i++ {
 		point := m.volumeData[i]
 
-		// Get value to display (always bytes now)
-		value := point.Size
+		// Get value to display in the current unit
+		value := m.pointValue(point)
 
 		// Calculate bar length
 		barLength := int((float64(value) / float64(maxValue)) * float64(m.maxBarWidth))
 
-		// Create bar
-		bar := m.createBar(barLength, value > 0)
+		// Create bar: solid while being brushed, dimmed outside the time filter,
+		// and otherwise stacked by log level
+		var bar string
+		switch {
+		case i >= brushFirst && i <= brushLast:
+			bar = m.createBar(barLength, value > 0, brushBarStyle)
+		case m.hasTimeFilter() && !m.inTimeFilter(point):
+			bar = m.createBar(barLength, value > 0, emptyBarStyle)
+		default:
+			bar = m.createStackedBar(barLength, point.Levels)
+		}
+
+		// Mark the cursor row while the panel is focused, and bins in the time filter
+		marker := " "
+		if m.focused && i == m.cursor {
+			marker = cursorStyle.Render("▶")
+		} else if m.inTimeFilter(point) {
+			marker = windowStyle.Render("┃")
+		}
 
 		// Create time label
 		timeLabel := m.formatTimeLabel(point.BinStart, timeRange)
@@ -103,15 +170,29 @@ func (m *Model) renderHistogramBars() string {
 		valueLabel := m.formatValueLabel(value, point.FileCount)
 
 		// Combine into line
-		line := fmt.Sprintf("%s %s %s", timeLabel, bar, valueLabel)
+		line := fmt.Sprintf("%s%s %s %s", marker, timeLabel, bar, valueLabel)
 		lines = append(lines, line)
 	}
 
 	return strings.Join(lines, "\n")
 }
 
+// hasTimeFilter reports whether the working set has an active time filter
+func (m *Model) hasTimeFilter() bool {
+	return m.workingSet != nil && m.workingSet.HasTimeFilter()
+}
+
+// inTimeFilter reports whether a bin lies within the working set's time filter
+func (m *Model) inTimeFilter(point models.VolumePoint) bool {
+	if !m.hasTimeFilter() {
+		return false
+	}
+	filter := m.workingSet.TimeFilter
+	return !point.BinStart.Before(filter.Start) && !point.BinEnd.After(filter.End)
+}
+
 // createBar creates a single histogram bar
-func (m *Model) createBar(length int, hasData bool) string {
+func (m *Model) createBar(length int, hasData bool, style lipgloss.Style) string {
 	if length <= 0 {
 		return emptyBarStyle.Render("▏")
 	}
@@ -120,11 +201,84 @@ func (m *Model) createBar(length int, hasData bool) string {
 	bar := strings.Repeat("█", length)
 
 	if hasData {
-		return barStyle.Render(bar)
+		return style.Render(bar)
 	}
 	return emptyBarStyle.Render(bar)
 }
 
+// createStackedBar creates a bar split into coloured segments proportional to each log
+// level's share of the bin's entries. Fatal and error entries always get at least one cell
+// so rare failures stay visible.
+func (m *Model) createStackedBar(length int, levels models.LevelCounts) string {
+	total := levels.Total()
+	if total == 0 || length <= 0 {
+		return m.createBar(length, length > 0, barStyle)
+	}
+
+	// Largest remainder apportionment of cells to levels
+	cells := make(map[models.LogLevel]int, len(models.LogLevels))
+	remainders := make(map[models.LogLevel]float64, len(models.LogLevels))
+	assigned := 0
+	for _, level := range models.LogLevels {
+		exact := float64(levels[level]) / float64(total) * float64(length)
+		cells[level] = int(exact)
+		remainders[level] = exact - float64(cells[level])
+		assigned += cells[level]
+	}
+	for ; assigned < length; assigned++ {
+		best := models.LevelUnknown
+		bestRemainder := -1.0
+		for _, level := range models.LogLevels {
+			if remainders[level] > bestRemainder {
+				best, bestRemainder = level, remainders[level]
+			}
+		}
+		cells[best]++
+		remainders[best] = -1
+	}
+
+	for _, level := range []models.LogLevel{models.LevelFatal, models.LevelError} {
+		if levels[level] == 0 || cells[level] > 0 {
+			continue
+		}
+		largest := level
+		for _, other := range models.LogLevels {
+			if cells[other] > cells[largest] {
+				largest = other
+			}
+		}
+		if cells[largest] > 1 {
+			cells[largest]--
+			cells[level]++
+		}
+	}
+
+	var bar strings.Builder
+	for _, level := range models.LogLevels {
+		if cells[level] > 0 {
+			bar.WriteString(levelStyles[level].Render(strings.Repeat("█", cells[level])))
+		}
+	}
+	return bar.String()
+}
+
+// renderLegend lists the entries per log level across all bins, in their bar colours
+func (m *Model) renderLegend() string {
+	totals := make(models.LevelCounts)
+	for _, point := range m.volumeData {
+		totals.Add(point.Levels)
+	}
+
+	var parts []string
+	for _, level := range models.LogLevels {
+		if count := totals[level]; count > 0 {
+			parts = append(parts, levelStyles[level].Render("■")+
+				labelStyle.Render(fmt.Sprintf(" %s %s", levelLetters[level], formatNumber(count))))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
 // formatTimeLabel creates a time label for the histogram
 func (m *Model) formatTimeLabel(t time.Time, timeRange time.Duration) string {
 	var format string
@@ -144,10 +298,25 @@ func (m *Model) formatTimeLabel(t time.Time, timeRange time.Duration) string {
 	return labelStyle.Render(fmt.Sprintf("%-5s", label))
 }
 
+// pointValue returns the plotted value of a bin: its entry count or its byte size
+func (m *Model) pointValue(point models.VolumePoint) int64 {
+	if m.showEntries {
+		return point.Count
+	}
+	return point.Size
+}
+
+// formatValue formats a value in the current unit
+func (m *Model) formatValue(value int64) string {
+	if m.showEntries {
+		return formatNumber(value)
+	}
+	return formatBytes(value)
+}
+
 // formatValueLabel creates a value label for the histogram
 func (m *Model) formatValueLabel(value int64, fileCount int) string {
-	// Always format as bytes now
-	valueStr := formatBytes(value)
+	valueStr := m.formatValue(value)
 
 	label := valueStr
 	if m.showFiles && fileCount > 0 {
@@ -183,7 +352,7 @@ func (m *Model) renderStatus() string {
 		nonEmptyBins := 0
 
 		for _, point := range m.volumeData {
-			value := point.Size // Always use bytes now
+			value := m.pointValue(point)
 
 			total += value
 			if value > peakValue {
@@ -194,9 +363,8 @@ func (m *Model) renderStatus() string {
 			}
 		}
 
-		// Always format as bytes now
-		totalStr := formatBytes(total)
-		peakStr := formatBytes(peakValue)
+		totalStr := m.formatValue(total)
+		peakStr := m.formatValue(peakValue)
 
 		summary := fmt.Sprintf("Total: %s | Peak: %s | Active bins: %d/%d",
 			totalStr, peakStr, nonEmptyBins, len(m.volumeData))
@@ -213,6 +381,12 @@ func (m *Model) renderHelp() string {
 		return ""
 	}
 
+	// While brushing, the help is replaced by the pending window
+	if first, last := m.selectionBounds(); first >= 0 {
+		return helpStyle.Render(fmt.Sprintf("Selecting %s → %s\nspace/enter:apply | esc:cancel",
+			m.volumeData[first].BinStart.Format("15:04:05"), m.volumeData[last].BinEnd.Format("15:04:05")))
+	}
+
 	var helpParts []string
 	helpParts = append(helpParts, "r:refresh")
 	if m.showFiles {
@@ -220,10 +394,21 @@ func (m *Model) renderHelp() string {
 	} else {
 		helpParts = append(helpParts, "f:show files")
 	}
+	if m.showEntries {
+		helpParts = append(helpParts, "l:bytes")
+	} else {
+		helpParts = append(helpParts, "l:entries")
+	}
 	helpParts = append(helpParts, "+/-:bins")
-
 	help := strings.Join(helpParts, " | ")
-	return helpStyle.Render(help)
+
+	// Second line covers brushing a time window
+	brush := "↑↓:move | space:mark | enter:bin"
+	if m.workingSet != nil && m.workingSet.HasTimeFilter() {
+		brush += " | c:clear"
+	}
+
+	return helpStyle.Render(help + "\n" + brush)
 }
 
 // renderLoading renders the loading state
@@ -251,7 +436,7 @@ func (m *Model) renderError() string {
 func (m *Model) renderEmpty() string {
 	title := titleStyle.Render("Volume Histogram")
 	empty := "No volume data available"
-	help := helpStyle.Render("Select files and press 'r' to generate histogram")
+	help := helpStyle.Render("Select log files to generate the histogram")
 
 	return fmt.Sprintf("%s\n\n%s\n%s", title, empty, help)
 }
@@ -0,0 +1,185 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/cheerioskun/logninja/internal/models"
+	"github.com/cheerioskun/logninja/internal/scanner"
+	"github.com/spf13/afero"
+)
+
+// ScanFunc runs a bundle scan, sending progress snapshots on the given channel
+type ScanFunc func(ctx context.Context, progress chan<- scanner.ScanProgress) (*models.Bundle, error)
+
+// LoaderModel renders a loading screen while the bundle is scanned and hands over
+// to AppModel once the scan completes. Ctrl+C aborts the scan.
+type LoaderModel struct {
+	// Scan
+	bundlePath string
+	scan       ScanFunc
+	outputFs   afero.Fs
+	ctx        context.Context
+	cancel     context.CancelFunc
+	progressCh chan scanner.ScanProgress
+
+	// State
+	progress  scanner.ScanProgress
+	err       error
+	cancelled bool
+	width     int
+	height    int
+}
+
+// scanProgressMsg carries a progress snapshot from the running scan
+type scanProgressMsg scanner.ScanProgress
+
+// scanCompletedMsg is sent when the scan finishes, successfully or not
+type scanCompletedMsg struct {
+	bundle *models.Bundle
+	err    error
+}
+
+// NewLoaderModel creates a loader that runs scan and opens the main UI on the result.
+// Exported files are written through outputFs.
+func NewLoaderModel(bundlePath string, scan ScanFunc, outputFs afero.Fs) *LoaderModel {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &LoaderModel{
+		bundlePath: bundlePath,
+		scan:       scan,
+		outputFs:   outputFs,
+		ctx:        ctx,
+		cancel:     cancel,
+		progressCh: make(chan scanner.ScanProgress),
+		width:      80,
+		height:     24,
+	}
+}
+
+// Init implements tea.Model
+func (m *LoaderModel) Init() tea.Cmd {
+	return tea.Batch(m.runScan(), m.waitForProgress())
+}
+
+// Update implements tea.Model
+func (m *LoaderModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q":
+			m.cancel()
+			m.cancelled = true
+			return m, tea.Quit
+		}
+		return m, nil
+
+	case scanProgressMsg:
+		m.progress = scanner.ScanProgress(msg)
+		return m, m.waitForProgress()
+
+	case scanCompletedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, tea.Quit
+		}
+
+		// Hand over to the main application, replaying the known window size
+		app := NewAppModel(models.NewWorkingSet(msg.bundle), m.outputFs)
+		size := tea.WindowSizeMsg{Width: m.width, Height: m.height}
+		return app, tea.Batch(func() tea.Msg { return size }, app.Init())
+	}
+
+	return m, nil
+}
+
+// View implements tea.Model
+func (m *LoaderModel) View() string {
+	if m.cancelled {
+		return "Scan cancelled\n"
+	}
+
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("205")).
+		Render("LogNinja - Scanning Bundle")
+
+	path := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Render(fmt.Sprintf("Bundle: %s", m.bundlePath))
+
+	help := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Italic(true).
+		Render("Ctrl+C: Abort")
+
+	lines := []string{
+		fmt.Sprintf("Phase:       %s", m.progress.Phase),
+		fmt.Sprintf("Walked:      %d files", m.progress.FilesWalked),
+		fmt.Sprintf("Classified:  %d/%d files", m.progress.FilesClassified, m.progress.TotalFiles),
+		fmt.Sprintf("From cache:  %d files", m.progress.FilesCached),
+		fmt.Sprintf("Peeked:      %s", formatBytes(m.progress.BytesPeeked)),
+	}
+	if m.progress.TotalFiles > 0 {
+		lines = append(lines, renderProgressBar(m.progress.FilesClassified, m.progress.TotalFiles, 40))
+	}
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		Padding(1, 2).
+		Render(lipgloss.JoinVertical(lipgloss.Left, title, path, "", strings.Join(lines, "\n"), "", help))
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// Err returns the scan error, if the scan failed
+func (m *LoaderModel) Err() error {
+	return m.err
+}
+
+// Cancelled returns true if the user aborted the scan
+func (m *LoaderModel) Cancelled() bool {
+	return m.cancelled
+}
+
+// runScan starts the scan; the progress channel is closed once it returns
+func (m *LoaderModel) runScan() tea.Cmd {
+	return func() tea.Msg {
+		bundle, err := m.scan(m.ctx, m.progressCh)
+		close(m.progressCh)
+		return scanCompletedMsg{bundle: bundle, err: err}
+	}
+}
+
+// waitForProgress blocks until the next progress snapshot arrives
+func (m *LoaderModel) waitForProgress() tea.Cmd {
+	return func() tea.Msg {
+		progress, ok := <-m.progressCh
+		if !ok {
+			return nil
+		}
+		return scanProgressMsg(progress)
+	}
+}
+
+// renderProgressBar draws a simple fixed-width progress bar
+func renderProgressBar(done, total int64, width int) string {
+	filled := int(float64(done) / float64(total) * float64(width))
+	if filled > width {
+		filled = width
+	}
+
+	bar := lipgloss.NewStyle().Foreground(lipgloss.Color("86")).Render(strings.Repeat("█", filled)) +
+		lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render(strings.Repeat("░", width-filled))
+
+	return fmt.Sprintf("%s %3.0f%%", bar, float64(done)/float64(total)*100)
+}
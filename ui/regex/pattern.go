@@ -1,6 +1,12 @@
 package regex
 
-import "regexp"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/cheerioskun/logninja/internal/models"
+)
 
 // PatternType represents whether this is for include or exclude patterns
 type PatternType int
@@ -24,10 +30,58 @@ func (pt PatternType) String() string {
 
 // Pattern represents a regex pattern with metadata
 type Pattern struct {
-	Text       string         // The regex pattern text
-	Type       PatternType    // Whether this is an include or exclude pattern
-	Compiled   *regexp.Regexp // Compiled regex (nil if invalid)
-	Valid      bool           // Whether the pattern is valid
-	MatchCount int            // Number of files matching this pattern
-	Error      string         // Error message if invalid
+	Text       string              // The regex pattern text
+	Type       PatternType         // Whether this is an include or exclude pattern
+	Target     models.FilterTarget // Whether the pattern matches file paths or log lines
+	Field      bool                // Whether the line filter matches one field; Text is then "key=regex"
+	Key        string              // For field filters, the JSON or logfmt key (empty if invalid)
+	Compiled   *regexp.Regexp      // Compiled regex (nil if invalid)
+	Valid      bool                // Whether the pattern is valid
+	MatchCount int                 // Number of files matching this pattern (all but line filters)
+	Error      string              // Error message if invalid
+}
+
+// IsLineFilter returns true if the pattern filters log lines instead of selecting files
+func (p *Pattern) IsLineFilter() bool {
+	return p.Target == models.FilterTargetContent
+}
+
+// Expr returns the regex part of the pattern text
+func (p *Pattern) Expr() string {
+	if p.Key == "" {
+		return p.Text
+	}
+	return strings.TrimPrefix(p.Text, p.Key+"=")
+}
+
+// IsFileMatch returns true if the pattern selects files by their contents
+func (p *Pattern) IsFileMatch() bool {
+	return p.Target == models.FilterTargetFileMatch
+}
+
+// IsFormat returns true if the pattern selects files by their detected log format
+func (p *Pattern) IsFormat() bool {
+	return p.Target == models.FilterTargetFormat
+}
+
+// IsKind returns true if the pattern selects files by their sniffed content kind
+func (p *Pattern) IsKind() bool {
+	return p.Target == models.FilterTargetKind
+}
+
+// IsPathPattern returns true if the pattern selects files by their paths
+func (p *Pattern) IsPathPattern() bool {
+	return !p.IsLineFilter() && !p.IsFileMatch() && !p.IsFormat() && !p.IsKind()
+}
+
+// fieldFilterPattern splits the text of a field filter into its key and regex
+var fieldFilterPattern = regexp.MustCompile(`^([A-Za-z_@][\w.@-]*)=(.*)$`)
+
+// splitFieldFilter parses "key=regex" ("component=sched.*", "http.status=5\d\d")
+func splitFieldFilter(text string) (key, expr string, err error) {
+	matches := fieldFilterPattern.FindStringSubmatch(text)
+	if matches == nil {
+		return "", "", fmt.Errorf("expected key=regex, e.g. component=scheduler")
+	}
+	return matches[1], matches[2], nil
 }
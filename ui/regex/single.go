@@ -51,11 +51,13 @@ type Model struct {
 	patterns []Pattern // Ordered list of patterns (includes and excludes mixed)
 
 	// UI State
-	cursor         int
-	editMode       bool
-	editInput      textinput.Model
-	editIndex      int         // Index of pattern being edited (-1 for new pattern)
-	newPatternType PatternType // Type for the next pattern to be added
+	cursor           int
+	editMode         bool
+	editInput        textinput.Model
+	editIndex        int                 // Index of pattern being edited (-1 for new pattern)
+	newPatternType   PatternType         // Type for the next pattern to be added
+	newPatternTarget models.FilterTarget // Target for the next pattern to be added
+	newPatternField  bool                // Whether the next line filter targets a field
 
 	// Component state
 	focused bool
@@ -64,6 +66,15 @@ type Model struct {
 
 	// Files for pattern matching
 	allFiles []string
+
+	// Files matching each file match pattern, by pattern text, once searched
+	fileMatchCounts map[string]int
+
+	// Detected log format of each file, for format patterns
+	fileFormats map[string]string
+
+	// Sniffed kind description of each file, for kind patterns
+	fileKinds map[string]string
 }
 
 // NewModel creates a new unified regex model
@@ -73,16 +84,20 @@ func NewModel() *Model {
 	input.CharLimit = 256
 
 	return &Model{
-		patterns:       make([]Pattern, 0),
-		cursor:         0,
-		editMode:       false,
-		editInput:      input,
-		editIndex:      -1,
-		newPatternType: IncludeType, // Default to include
-		focused:        false,
-		width:          40,
-		height:         20,
-		allFiles:       make([]string, 0),
+		patterns:         make([]Pattern, 0),
+		cursor:           0,
+		editMode:         false,
+		editInput:        input,
+		editIndex:        -1,
+		newPatternType:   IncludeType, // Default to include
+		newPatternTarget: models.FilterTargetPath,
+		focused:          false,
+		width:            40,
+		height:           20,
+		allFiles:         make([]string, 0),
+		fileMatchCounts:  make(map[string]int),
+		fileFormats:      make(map[string]string),
+		fileKinds:        make(map[string]string),
 	}
 }
 
@@ -119,12 +134,40 @@ func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
 			m.moveCursorDown()
 		case "a":
 			// Add include pattern
-			m.newPatternType = IncludeType
-			m.startAddPattern()
+			m.startAddPattern(IncludeType, models.FilterTargetPath)
 		case "A":
 			// Add exclude pattern (Shift+A)
-			m.newPatternType = ExcludeType
-			m.startAddPattern()
+			m.startAddPattern(ExcludeType, models.FilterTargetPath)
+		case "l":
+			// Add line filter keeping matching lines
+			m.startAddPattern(IncludeType, models.FilterTargetContent)
+		case "L":
+			// Add line filter dropping matching lines
+			m.startAddPattern(ExcludeType, models.FilterTargetContent)
+		case "f":
+			// Add line filter keeping entries whose field matches
+			m.startAddFieldPattern(IncludeType)
+		case "F":
+			// Add line filter dropping entries whose field matches
+			m.startAddFieldPattern(ExcludeType)
+		case "c":
+			// Add file match pattern selecting files that contain a match
+			m.startAddPattern(IncludeType, models.FilterTargetFileMatch)
+		case "C":
+			// Add file match pattern excluding files that contain a match
+			m.startAddPattern(ExcludeType, models.FilterTargetFileMatch)
+		case "o":
+			// Add format pattern selecting files of a detected format
+			m.startAddPattern(IncludeType, models.FilterTargetFormat)
+		case "O":
+			// Add format pattern excluding files of a detected format
+			m.startAddPattern(ExcludeType, models.FilterTargetFormat)
+		case "y":
+			// Add kind pattern selecting files of a sniffed kind
+			m.startAddPattern(IncludeType, models.FilterTargetKind)
+		case "Y":
+			// Add kind pattern excluding files of a sniffed kind
+			m.startAddPattern(ExcludeType, models.FilterTargetKind)
 		case "e":
 			if m.hasPatternAtCursor() {
 				m.startEditPattern()
@@ -137,8 +180,7 @@ func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
 				m.startEditPattern()
 			} else {
 				// Default to include pattern on enter
-				m.newPatternType = IncludeType
-				m.startAddPattern()
+				m.startAddPattern(IncludeType, models.FilterTargetPath)
 			}
 		case "t":
 			m.testPatterns()
@@ -173,6 +215,10 @@ func (m *Model) IsFocused() bool {
 	return m.focused
 }
 
+func (m *Model) IsEditing() bool {
+	return m.editMode
+}
+
 func (m *Model) SetSize(width, height int) {
 	m.width = width
 	m.height = height
@@ -185,15 +231,35 @@ func (m *Model) SetFiles(files []string) {
 	m.testPatterns() // Retest patterns with new file list
 }
 
+// SetFileFormats records the detected log format of each file, by path
+func (m *Model) SetFileFormats(formats map[string]string) {
+	m.fileFormats = formats
+	m.testPatterns()
+}
+
+// SetFileKinds records the sniffed kind description of each file, by path
+func (m *Model) SetFileKinds(kinds map[string]string) {
+	m.fileKinds = kinds
+	m.testPatterns()
+}
+
+// SetFileMatchCount records how many files a content search found for a file match pattern
+func (m *Model) SetFileMatchCount(text string, files int) {
+	m.fileMatchCounts[text] = files
+	m.testPatterns()
+}
+
 // GetRegexFilters returns all patterns as ordered RegexFilter list
 func (m *Model) GetRegexFilters() []models.RegexFilter {
 	filters := make([]models.RegexFilter, 0, len(m.patterns))
 	for _, p := range m.patterns {
 		if p.Valid {
-			compiled, _ := regexp.Compile(p.Text) // Already validated
+			compiled, _ := regexp.Compile(p.Expr()) // Already validated
 			filter := models.RegexFilter{
-				Pattern:  p.Text,
+				Pattern:  p.Expr(),
 				Take:     p.Type == IncludeType,
+				Target:   p.Target,
+				Key:      p.Key,
 				Compiled: compiled,
 				Valid:    true,
 				Error:    "",
@@ -208,7 +274,7 @@ func (m *Model) GetRegexFilters() []models.RegexFilter {
 func (m *Model) GetIncludePatterns() []string {
 	patterns := make([]string, 0)
 	for _, p := range m.patterns {
-		if p.Valid && p.Type == IncludeType {
+		if p.Valid && p.Type == IncludeType && p.IsPathPattern() {
 			patterns = append(patterns, p.Text)
 		}
 	}
@@ -218,7 +284,7 @@ func (m *Model) GetIncludePatterns() []string {
 func (m *Model) GetExcludePatterns() []string {
 	patterns := make([]string, 0)
 	for _, p := range m.patterns {
-		if p.Valid && p.Type == ExcludeType {
+		if p.Valid && p.Type == ExcludeType && p.IsPathPattern() {
 			patterns = append(patterns, p.Text)
 		}
 	}
@@ -246,15 +312,21 @@ func (m *Model) renderNormalMode() string {
 	// Help
 	help := ""
 	if m.focused {
+		// Lower case keys add take patterns, upper case ones exclude
 		helpItems := []string{
-			"↑/↓: Navigate",
-			"a: Add Include",
-			"A: Add Exclude",
-			"e/Enter: Edit",
-			"d: Delete",
-			"t: Test",
+			"↑↓ move",
+			"a/A path",
+			"l/L line",
+			"f/F field",
+			"c/C contains",
+			"o/O format",
+			"y/Y kind",
+			"e edit",
+			"d delete",
+			"t test",
+			"Shift: exclude",
 		}
-		help = helpStyle.Render(strings.Join(helpItems, " • "))
+		help = helpStyle.Width(m.width).Render(strings.Join(helpItems, " • "))
 	}
 
 	// Calculate available space for content
@@ -269,6 +341,7 @@ func (m *Model) renderNormalMode() string {
 	// Content constrained to available height
 	content := m.renderPatterns()
 	constrainedContent := lipgloss.NewStyle().
+		Width(m.width).
 		Height(contentHeight).
 		Render(content)
 
@@ -280,6 +353,37 @@ func (m *Model) renderEditMode() string {
 	if m.editIndex == -1 {
 		title = "Add Pattern"
 	}
+	switch m.newPatternTarget {
+	case models.FilterTargetContent:
+		switch {
+		case m.newPatternField && m.newPatternType == IncludeType:
+			title += " (keep where field matches)"
+		case m.newPatternField:
+			title += " (drop where field matches)"
+		case m.newPatternType == IncludeType:
+			title += " (keep matching lines)"
+		default:
+			title += " (drop matching lines)"
+		}
+	case models.FilterTargetFileMatch:
+		if m.newPatternType == IncludeType {
+			title += " (files containing)"
+		} else {
+			title += " (files not containing)"
+		}
+	case models.FilterTargetFormat:
+		if m.newPatternType == IncludeType {
+			title += " (files of format)"
+		} else {
+			title += " (files not of format)"
+		}
+	case models.FilterTargetKind:
+		if m.newPatternType == IncludeType {
+			title += " (files of kind)"
+		} else {
+			title += " (files not of kind)"
+		}
+	}
 
 	header := headerStyle.
 		Foreground(primaryColor).
@@ -296,7 +400,7 @@ func (m *Model) renderPatterns() string {
 	if len(m.patterns) == 0 {
 		emptyMsg := "No patterns"
 		if m.focused {
-			emptyMsg += " (press 'a' for include, 'A' for exclude)"
+			emptyMsg += ": add with a path, l line, f field, c contains, o format, y kind (Shift to exclude)"
 		}
 		return lipgloss.NewStyle().
 			Foreground(secondaryColor).
@@ -368,12 +472,29 @@ func (m *Model) renderPattern(pattern Pattern, index int, isSelected bool) strin
 		patternText = patternText[:17] + "..."
 	}
 
-	// Match count
+	// Match count; line filters apply on export, so they have no file count
 	matchInfo := ""
-	if pattern.Valid {
-		matchInfo = fmt.Sprintf(" (%d)", pattern.MatchCount)
-	} else {
+	if !pattern.Valid {
 		matchInfo = " (error)"
+	} else if pattern.IsLineFilter() && pattern.Field {
+		matchInfo = " (field)"
+	} else if pattern.IsLineFilter() {
+		matchInfo = " (lines)"
+	} else if _, searched := m.fileMatchCounts[pattern.Text]; pattern.IsFileMatch() && !searched {
+		matchInfo = " (searching)"
+	} else {
+		matchInfo = fmt.Sprintf(" (%d)", pattern.MatchCount)
+	}
+
+	// Line filters, file match, format and kind patterns are marked so they aren't mistaken for path patterns
+	if pattern.IsLineFilter() {
+		typeIcon += "≡"
+	} else if pattern.IsFileMatch() {
+		typeIcon += "∋"
+	} else if pattern.IsFormat() {
+		typeIcon += "◆"
+	} else if pattern.IsKind() {
+		typeIcon += "▣"
 	}
 
 	content := fmt.Sprintf("%s %s%s", typeIcon, patternText, matchInfo)
@@ -417,19 +538,47 @@ func (m *Model) moveCursorDown() {
 	}
 }
 
-func (m *Model) startAddPattern() {
+func (m *Model) startAddPattern(patternType PatternType, target models.FilterTarget) {
+	m.newPatternType = patternType
+	m.newPatternTarget = target
+	m.setFieldMode(false)
 	m.editMode = true
 	m.editIndex = -1
 	m.editInput.SetValue("")
 	m.editInput.Focus()
 }
 
+// startAddFieldPattern adds a line filter matched against one field of JSON or logfmt lines
+func (m *Model) startAddFieldPattern(patternType PatternType) {
+	m.startAddPattern(patternType, models.FilterTargetContent)
+	m.setFieldMode(true)
+}
+
+// setFieldMode switches the input between plain regexes and "key=regex" field filters,
+// and sets the placeholder for the pattern being added
+func (m *Model) setFieldMode(field bool) {
+	m.newPatternField = field
+	switch {
+	case field:
+		m.editInput.Placeholder = "Enter key=regex (e.g. component=scheduler)..."
+	case m.newPatternTarget == models.FilterTargetFormat:
+		m.editInput.Placeholder = "Enter format regex (e.g. ^ISO8601|^JSON_)..."
+	case m.newPatternTarget == models.FilterTargetKind:
+		m.editInput.Placeholder = "Enter kind regex (e.g. binary|utf-16)..."
+	default:
+		m.editInput.Placeholder = "Enter regex pattern..."
+	}
+}
+
 func (m *Model) startEditPattern() {
 	if !m.hasPatternAtCursor() {
 		return
 	}
 
 	pattern := m.patterns[m.cursor]
+	m.newPatternType = pattern.Type
+	m.newPatternTarget = pattern.Target
+	m.setFieldMode(pattern.Field)
 	m.editMode = true
 	m.editIndex = m.cursor
 	m.editInput.SetValue(pattern.Text)
@@ -490,11 +639,23 @@ func (m *Model) deletePattern() tea.Cmd {
 }
 
 func (m *Model) compilePattern(text string) Pattern {
-	compiled, err := regexp.Compile(text)
+	key, expr := "", text
+	var err error
+	if m.newPatternField {
+		key, expr, err = splitFieldFilter(text)
+	}
+
+	var compiled *regexp.Regexp
+	if err == nil {
+		compiled, err = regexp.Compile(expr)
+	}
 	if err != nil {
 		return Pattern{
 			Text:       text,
 			Type:       m.newPatternType,
+			Target:     m.newPatternTarget,
+			Field:      m.newPatternField,
+			Key:        key,
 			Compiled:   nil,
 			Valid:      false,
 			MatchCount: 0,
@@ -505,6 +666,9 @@ func (m *Model) compilePattern(text string) Pattern {
 	return Pattern{
 		Text:       text,
 		Type:       m.newPatternType,
+		Target:     m.newPatternTarget,
+		Field:      m.newPatternField,
+		Key:        key,
 		Compiled:   compiled,
 		Valid:      true,
 		MatchCount: 0,
@@ -519,13 +683,22 @@ func (m *Model) testPatterns() {
 }
 
 func (m *Model) countMatches(pattern *Pattern) int {
-	if !pattern.Valid || pattern.Compiled == nil {
+	if !pattern.Valid || pattern.Compiled == nil || pattern.IsLineFilter() {
 		return 0
 	}
+	if pattern.IsFileMatch() {
+		return m.fileMatchCounts[pattern.Text]
+	}
 
 	count := 0
 	for _, filename := range m.allFiles {
-		if pattern.Compiled.MatchString(filename) {
+		subject := filename
+		if pattern.IsFormat() {
+			subject = m.fileFormats[filename]
+		} else if pattern.IsKind() {
+			subject = m.fileKinds[filename]
+		}
+		if pattern.Compiled.MatchString(subject) {
 			count++
 		}
 	}
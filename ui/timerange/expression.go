@@ -0,0 +1,221 @@
+package timerange
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cheerioskun/logninja/internal/models"
+)
+
+// absoluteLayouts are the accepted formats for a full date and time, tried in order
+var absoluteLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// clockLayouts are the accepted formats for a time of day without a date
+var clockLayouts = []string{
+	"15:04:05",
+	"15:04",
+}
+
+// rangeSeparators split an absolute "START <sep> END" expression
+var rangeSeparators = []string{" to ", "..", " - "}
+
+// dayDuration matches day components in durations like "1d" or "1.5d12h"
+var dayDuration = regexp.MustCompile(`(\d+(?:\.\d+)?)d`)
+
+// ParseExpression turns a time window expression into a time range.
+// Relative expressions are anchored to the bundle's time range rather than the wall clock,
+// since bundles are usually inspected long after they were collected; bounds may be nil, in
+// which case the current time is used. An empty expression (or "all") returns nil, meaning
+// no time filter.
+//
+// Supported forms:
+//
+//	last 2h                     final 2 hours of the bundle
+//	first 30m                   first 30 minutes of the bundle
+//	around 14:05 ±10m           10 minutes either side of a point ("+-" also accepted)
+//	since 13:00 / until 13:00   from a point to the end, or from the start to a point
+//	2024-01-02 10:00 to 11:30   absolute range ("..", " - " also accepted)
+func ParseExpression(input string, bounds *models.TimeRange) (*models.TimeRange, error) {
+	expression := strings.ToLower(strings.TrimSpace(input))
+	expression = strings.ReplaceAll(expression, "±", "+-")
+
+	// Times are typed in the display zone, whatever zone the bundle's timestamps carry
+	reference := models.InDisplayZone(time.Now())
+	if bounds != nil {
+		reference = models.InDisplayZone(bounds.End)
+	}
+
+	switch {
+	case expression == "" || expression == "all":
+		return nil, nil
+
+	case strings.HasPrefix(expression, "last "):
+		duration, err := parseDuration(strings.TrimPrefix(expression, "last "))
+		if err != nil {
+			return nil, err
+		}
+		return models.NewTimeRange(reference.Add(-duration), reference)
+
+	case strings.HasPrefix(expression, "first "):
+		if bounds == nil {
+			return nil, fmt.Errorf("bundle has no known start time")
+		}
+		duration, err := parseDuration(strings.TrimPrefix(expression, "first "))
+		if err != nil {
+			return nil, err
+		}
+		return models.NewTimeRange(bounds.Start, bounds.Start.Add(duration))
+
+	case strings.HasPrefix(expression, "around "):
+		return parseAround(strings.TrimPrefix(expression, "around "), reference)
+
+	case strings.HasPrefix(expression, "since "):
+		start, err := parsePointBefore(strings.TrimPrefix(expression, "since "), reference)
+		if err != nil {
+			return nil, err
+		}
+		return models.NewTimeRange(start, reference)
+
+	case strings.HasPrefix(expression, "until "):
+		if bounds == nil {
+			return nil, fmt.Errorf("bundle has no known start time")
+		}
+		end, err := parsePointBefore(strings.TrimPrefix(expression, "until "), reference)
+		if err != nil {
+			return nil, err
+		}
+		return models.NewTimeRange(bounds.Start, end)
+	}
+
+	for _, separator := range rangeSeparators {
+		if startText, endText, found := strings.Cut(expression, separator); found {
+			return parseAbsoluteRange(startText, endText, reference)
+		}
+	}
+
+	return nil, fmt.Errorf("unrecognised time expression %q", input)
+}
+
+// parseAround handles "POINT +-DURATION"
+func parseAround(expression string, reference time.Time) (*models.TimeRange, error) {
+	pointText, durationText, found := strings.Cut(expression, "+-")
+	if !found {
+		return nil, fmt.Errorf("expected \"around TIME ±DURATION\"")
+	}
+
+	point, err := parsePointBefore(pointText, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := parseDuration(durationText)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.NewTimeRange(point.Add(-duration), point.Add(duration))
+}
+
+// parseAbsoluteRange handles "START to END"; a time-of-day END is taken on START's date
+func parseAbsoluteRange(startText, endText string, reference time.Time) (*models.TimeRange, error) {
+	start, err := parsePointBefore(startText, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := parseAbsolute(endText, models.DisplayLocation())
+	if err != nil {
+		clock, clockErr := parseClock(endText)
+		if clockErr != nil {
+			return nil, err
+		}
+		end = onDate(start, clock)
+		if end.Before(start) {
+			end = end.AddDate(0, 0, 1) // Window crosses midnight
+		}
+	}
+
+	return models.NewTimeRange(start, end)
+}
+
+// parsePointBefore parses an absolute timestamp in the display zone, or a time of day resolved
+// to its latest occurrence at or before reference
+func parsePointBefore(text string, reference time.Time) (time.Time, error) {
+	if point, err := parseAbsolute(text, models.DisplayLocation()); err == nil {
+		return point, nil
+	}
+
+	clock, err := parseClock(text)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q", strings.TrimSpace(text))
+	}
+
+	point := onDate(reference, clock)
+	if point.After(reference) {
+		point = point.AddDate(0, 0, -1)
+	}
+	return point, nil
+}
+
+// parseAbsolute parses a full date (and optional time) in the given location
+func parseAbsolute(text string, location *time.Location) (time.Time, error) {
+	text = strings.ToUpper(strings.TrimSpace(text)) // RFC3339 expects upper-case T and Z
+	for _, layout := range absoluteLayouts {
+		if parsed, err := time.ParseInLocation(layout, text, location); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", text)
+}
+
+// parseClock parses a time of day, returned as an offset from midnight
+func parseClock(text string) (time.Duration, error) {
+	text = strings.TrimSpace(text)
+	for _, layout := range clockLayouts {
+		if parsed, err := time.Parse(layout, text); err == nil {
+			return time.Duration(parsed.Hour())*time.Hour +
+				time.Duration(parsed.Minute())*time.Minute +
+				time.Duration(parsed.Second())*time.Second, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid time of day %q", text)
+}
+
+// onDate places a time of day on the calendar date of day
+func onDate(day time.Time, clock time.Duration) time.Time {
+	year, month, date := day.Date()
+	return time.Date(year, month, date, 0, 0, 0, 0, day.Location()).Add(clock)
+}
+
+// parseDuration extends time.ParseDuration with a "d" (24h) unit
+func parseDuration(text string) (time.Duration, error) {
+	text = strings.ReplaceAll(strings.TrimSpace(text), " ", "")
+
+	var conversionErr error
+	converted := dayDuration.ReplaceAllStringFunc(text, func(days string) string {
+		value, err := strconv.ParseFloat(strings.TrimSuffix(days, "d"), 64)
+		if err != nil {
+			conversionErr = err
+		}
+		return strconv.FormatFloat(value*24, 'f', -1, 64) + "h"
+	})
+	if conversionErr != nil {
+		return 0, fmt.Errorf("invalid duration %q", text)
+	}
+
+	duration, err := time.ParseDuration(converted)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", text)
+	}
+	return duration, nil
+}
@@ -0,0 +1,107 @@
+package timerange
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cheerioskun/logninja/internal/models"
+)
+
+func utc(day, hour, minute int) time.Time {
+	return time.Date(2024, 1, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestParseExpression(t *testing.T) {
+	bounds := &models.TimeRange{Start: utc(1, 22, 0), End: utc(2, 14, 30)}
+
+	tests := []struct {
+		name       string
+		input      string
+		bounds     *models.TimeRange
+		start, end time.Time
+		wantNil    bool
+		wantErr    bool
+	}{
+		{name: "empty", input: "  ", bounds: bounds, wantNil: true},
+		{name: "all", input: "ALL", bounds: bounds, wantNil: true},
+		{name: "last", input: "last 2h", bounds: bounds, start: utc(2, 12, 30), end: utc(2, 14, 30)},
+		{name: "last days", input: "last 1d", bounds: bounds, start: utc(1, 14, 30), end: utc(2, 14, 30)},
+		{name: "last fractional days", input: "last 0.5d 1h", bounds: bounds, start: utc(2, 1, 30), end: utc(2, 14, 30)},
+		{name: "first", input: "first 30m", bounds: bounds, start: utc(1, 22, 0), end: utc(1, 22, 30)},
+		{name: "around", input: "around 14:05 ±10m", bounds: bounds, start: utc(2, 13, 55), end: utc(2, 14, 15)},
+		{name: "around with +-", input: "around 14:05 +- 10m", bounds: bounds, start: utc(2, 13, 55), end: utc(2, 14, 15)},
+		{name: "around a later time of day is the day before", input: "around 20:00 ±1h", bounds: bounds, start: utc(1, 19, 0), end: utc(1, 21, 0)},
+		{name: "since", input: "since 13:00", bounds: bounds, start: utc(2, 13, 0), end: utc(2, 14, 30)},
+		{name: "since the day before", input: "since 23:00", bounds: bounds, start: utc(1, 23, 0), end: utc(2, 14, 30)},
+		{name: "until", input: "until 01:00", bounds: bounds, start: utc(1, 22, 0), end: utc(2, 1, 0)},
+		{name: "absolute range", input: "2024-01-02 10:00 to 2024-01-02 11:30", bounds: bounds, start: utc(2, 10, 0), end: utc(2, 11, 30)},
+		{name: "absolute range with time of day end", input: "2024-01-02 10:00 .. 11:30", bounds: bounds, start: utc(2, 10, 0), end: utc(2, 11, 30)},
+		{name: "absolute range with dash", input: "2024-01-02T10:00:00Z - 2024-01-02T10:30:00Z", bounds: bounds, start: utc(2, 10, 0), end: utc(2, 10, 30)},
+		{name: "range crossing midnight", input: "2024-01-01 23:30 to 00:30", bounds: bounds, start: utc(1, 23, 30), end: utc(2, 0, 30)},
+		{name: "time of day range", input: "23:00 to 01:00", bounds: bounds, start: utc(1, 23, 0), end: utc(2, 1, 0)},
+		{name: "first without bounds", input: "first 30m", wantErr: true},
+		{name: "until without bounds", input: "until 10:00", wantErr: true},
+		{name: "invalid duration", input: "last soon", bounds: bounds, wantErr: true},
+		{name: "negative duration", input: "last -2h", bounds: bounds, wantErr: true},
+		{name: "around without duration", input: "around 14:05", bounds: bounds, wantErr: true},
+		{name: "invalid time", input: "since teatime", bounds: bounds, wantErr: true},
+		{name: "end before start", input: "2024-01-02 10:00 to 2024-01-01 10:00", bounds: bounds, wantErr: true},
+		{name: "unrecognised", input: "yesterday", bounds: bounds, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseExpression(tt.input, tt.bounds)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseExpression(%q) = %v, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseExpression(%q) error = %v", tt.input, err)
+			}
+			if tt.wantNil {
+				if got != nil {
+					t.Errorf("ParseExpression(%q) = %v, want nil", tt.input, got)
+				}
+				return
+			}
+			if got == nil || !got.Start.Equal(tt.start) || !got.End.Equal(tt.end) {
+				t.Errorf("ParseExpression(%q) = %v, want %v to %v", tt.input, got, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestParseExpressionInDisplayZone(t *testing.T) {
+	models.SetDisplayLocation(time.FixedZone("+02:00", 2*3600))
+	t.Cleanup(func() { models.SetDisplayLocation(nil) })
+
+	// 14:30 UTC is 16:30 in the display zone
+	bounds := &models.TimeRange{Start: utc(1, 22, 0), End: utc(2, 14, 30)}
+
+	tests := []struct {
+		name       string
+		input      string
+		start, end time.Time
+	}{
+		{"since", "since 16:00", utc(2, 14, 0), utc(2, 14, 30)},
+		{"since before local midnight", "since 01:00", utc(1, 23, 0), utc(2, 14, 30)},
+		{"around", "around 16:00 ±5m", utc(2, 13, 55), utc(2, 14, 5)},
+		{"absolute range", "2024-01-02 10:00 to 11:00", utc(2, 8, 0), utc(2, 9, 0)},
+		{"explicit offset wins", "2024-01-02T10:00:00Z to 2024-01-02T11:00:00Z", utc(2, 10, 0), utc(2, 11, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseExpression(tt.input, bounds)
+			if err != nil {
+				t.Fatalf("ParseExpression(%q) error = %v", tt.input, err)
+			}
+			if !got.Start.Equal(tt.start) || !got.End.Equal(tt.end) {
+				t.Errorf("ParseExpression(%q) = %v, want %v to %v", tt.input, got, tt.start, tt.end)
+			}
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package timerange
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/cheerioskun/logninja/internal/messages"
+	"github.com/cheerioskun/logninja/internal/models"
+)
+
+// Styling
+var (
+	modalStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("62")).
+			Padding(1, 2).
+			Background(lipgloss.Color("235")).
+			Foreground(lipgloss.Color("255"))
+
+	titleStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("39")).
+			Align(lipgloss.Center)
+
+	inputStyle = lipgloss.NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			BorderForeground(lipgloss.Color("62")).
+			Padding(0, 1).
+			Margin(1, 0)
+
+	previewStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("245"))
+
+	windowStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("46"))
+
+	helpStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("241")).
+			Italic(true).
+			Margin(1, 0, 0, 0)
+
+	errorStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("196")).
+			Bold(true)
+)
+
+// sourceComponent identifies this picker in TimeFilterChangedMsg
+const sourceComponent = "timerange"
+
+// Model is a modal for choosing the time window filter.
+// Valid expressions are applied live as they are typed; Esc restores the previous filter.
+type Model struct {
+	// UI components
+	input textinput.Model
+
+	// State
+	visible bool
+	width   int
+	height  int
+
+	// Data
+	workingSet   *models.WorkingSet
+	original     *models.TimeRange // Filter active when the picker opened
+	applied      *models.TimeRange // Filter most recently sent while typing
+	errorMessage string
+}
+
+// TimePickerClosedMsg is sent when the picker is closed
+type TimePickerClosedMsg struct {
+	Applied   bool              // false if the picker was cancelled
+	TimeRange *models.TimeRange // Resulting filter, nil if none
+}
+
+// NewModel creates a new time range picker
+func NewModel() *Model {
+	ti := textinput.New()
+	ti.Placeholder = "e.g. last 2h, around 14:05 ±10m"
+	ti.CharLimit = 128
+	ti.Width = 50
+
+	return &Model{
+		input:   ti,
+		visible: false,
+	}
+}
+
+// Show displays the picker for the given working set, prefilled with its current filter
+func (m *Model) Show(ws *models.WorkingSet) tea.Cmd {
+	m.visible = true
+	m.workingSet = ws
+	m.original = nil
+	if ws.HasTimeFilter() {
+		m.original = ws.TimeFilter
+	}
+	m.applied = m.original
+	m.errorMessage = ""
+
+	if m.original != nil {
+		m.input.SetValue(formatAbsolute(m.original))
+	} else {
+		m.input.SetValue("")
+	}
+	m.input.CursorEnd()
+
+	return m.input.Focus()
+}
+
+// Hide hides the picker
+func (m *Model) Hide() {
+	m.visible = false
+	m.input.Blur()
+}
+
+// IsVisible returns true if the picker is visible
+func (m *Model) IsVisible() bool {
+	return m.visible
+}
+
+// SetSize sets the available screen size
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// Update handles messages for the picker
+func (m *Model) Update(msg tea.Msg) (*Model, tea.Cmd) {
+	if !m.visible {
+		return m, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+
+	switch keyMsg.String() {
+	case "enter":
+		if m.errorMessage != "" {
+			return m, nil
+		}
+		m.Hide()
+		applied := m.applied
+		return m, func() tea.Msg { return TimePickerClosedMsg{Applied: true, TimeRange: applied} }
+
+	case "esc":
+		m.Hide()
+		original := m.original
+		return m, tea.Batch(
+			emitTimeFilter(original),
+			func() tea.Msg { return TimePickerClosedMsg{Applied: false, TimeRange: original} },
+		)
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(keyMsg)
+	return m, tea.Batch(cmd, m.reparse())
+}
+
+// reparse validates the current input and applies it live if it changed the window
+func (m *Model) reparse() tea.Cmd {
+	var bounds *models.TimeRange
+	if m.workingSet != nil && m.workingSet.Bundle != nil {
+		bounds = m.workingSet.Bundle.TimeRange
+	}
+
+	timeRange, err := ParseExpression(m.input.Value(), bounds)
+	if err != nil {
+		m.errorMessage = err.Error()
+		return nil
+	}
+	m.errorMessage = ""
+
+	if sameRange(timeRange, m.applied) {
+		return nil
+	}
+	m.applied = timeRange
+	return emitTimeFilter(timeRange)
+}
+
+// View renders the picker
+func (m *Model) View() string {
+	if !m.visible {
+		return ""
+	}
+
+	var parts []string
+	// Times are shown, and typed, in the display zone
+	parts = append(parts, titleStyle.Render(fmt.Sprintf("Time Range Filter (%s)", models.DisplayLocation())))
+
+	bundleRange := "(no timestamps found)"
+	if m.workingSet != nil && m.workingSet.Bundle != nil && m.workingSet.Bundle.TimeRange != nil {
+		bundleRange = describeRange(m.workingSet.Bundle.TimeRange)
+	}
+	parts = append(parts, previewStyle.Render("Bundle: "+bundleRange))
+
+	parts = append(parts, inputStyle.Render(m.input.View()))
+
+	if m.errorMessage != "" {
+		parts = append(parts, errorStyle.Render(m.errorMessage))
+	} else if m.applied != nil {
+		parts = append(parts, windowStyle.Render("Window: "+describeRange(m.applied)))
+	} else {
+		parts = append(parts, windowStyle.Render("Window: entire bundle"))
+	}
+
+	if m.workingSet != nil {
+		parts = append(parts, previewStyle.Render(fmt.Sprintf("Selected: %d files, %s (≈%s in window)",
+			m.workingSet.GetSelectedFileCount(), formatBytes(m.workingSet.GetSelectedTotalSize()),
+			formatBytes(m.workingSet.GetSelectedWindowSize()))))
+	}
+
+	parts = append(parts, helpStyle.Render(
+		"last 2h • first 30m • since 13:00 • until 13:00\n"+
+			"around 14:05 ±10m • 2024-01-02 10:00 to 11:30\n"+
+			"Empty: entire bundle • Enter: Apply • Esc: Cancel"))
+
+	content := modalStyle.Width(64).Render(strings.Join(parts, "\n"))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, content)
+}
+
+// emitTimeFilter creates a command announcing a new time filter
+func emitTimeFilter(timeRange *models.TimeRange) tea.Cmd {
+	return func() tea.Msg {
+		return messages.TimeFilterChangedMsg{
+			TimeRange:       timeRange,
+			SourceComponent: sourceComponent,
+		}
+	}
+}
+
+// sameRange compares two possibly nil time ranges
+func sameRange(a, b *models.TimeRange) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Start.Equal(b.Start) && a.End.Equal(b.End)
+}
+
+// describeRange renders a range with its duration
+func describeRange(tr *models.TimeRange) string {
+	return fmt.Sprintf("%s → %s (%s)",
+		tr.Start.Format("2006-01-02 15:04:05"), tr.End.Format("2006-01-02 15:04:05"), tr.Duration())
+}
+
+// formatAbsolute renders a range as an expression ParseExpression accepts
+func formatAbsolute(tr *models.TimeRange) string {
+	return fmt.Sprintf("%s to %s", tr.Start.Format("2006-01-02 15:04:05"), tr.End.Format("2006-01-02 15:04:05"))
+}
+
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}